@@ -0,0 +1,102 @@
+// Package alerting delivers detected anomalies to external alert sinks
+// alerting/alerting.go
+package alerting
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// Clock abstracts time so that pacing can be driven deterministically
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock is a Clock backed by the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// RealClock returns a Clock backed by the system time
+func RealClock() Clock {
+	return realClock{}
+}
+
+// Pacer spaces alert sends by a fixed interval plus seeded random jitter,
+// spreading bursts over a window instead of firing them all at once
+type Pacer struct {
+	interval time.Duration
+	jitter   time.Duration
+	rng      *rand.Rand
+	clock    Clock
+	next     time.Time
+}
+
+// NewPacer creates a pacer; the same seed always yields the same jitter sequence
+func NewPacer(interval, jitter time.Duration, seed int64, clock Clock) *Pacer {
+	if clock == nil {
+		clock = RealClock()
+	}
+	return &Pacer{
+		interval: interval,
+		jitter:   jitter,
+		rng:      rand.New(rand.NewSource(seed)),
+		clock:    clock,
+	}
+}
+
+// Wait blocks until the next send slot is reached. A slot missed while the caller was busy is
+// not made up later, so sends after a pause stay spaced instead of bursting to catch up.
+func (p *Pacer) Wait() {
+	now := p.clock.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	if delay := p.next.Sub(now); delay > 0 {
+		p.clock.Sleep(delay)
+	}
+
+	// Schedule the following slot
+	gap := p.interval
+	if p.jitter > 0 {
+		gap += time.Duration(p.rng.Int63n(int64(p.jitter) + 1))
+	}
+	p.next = p.next.Add(gap)
+}
+
+// Sink receives anomalies for delivery to an external system
+type Sink interface {
+	Send(anomaly models.Anomaly) error
+	Flush() error
+}
+
+// Publish sends every anomaly to the sink and flushes it, pacing sends when a pacer is supplied.
+// Delivery continues past individual send failures; the first failure is returned.
+func Publish(sink Sink, anomalies []models.Anomaly, pacer *Pacer) error {
+	var firstErr error
+	failed := 0
+	for _, anomaly := range anomalies {
+		if pacer != nil {
+			pacer.Wait()
+		}
+		if err := sink.Send(anomaly); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if err := sink.Flush(); err != nil && firstErr == nil {
+		return fmt.Errorf("error flushing alert sink: %w", err)
+	}
+	if firstErr != nil {
+		return fmt.Errorf("failed to send %d of %d alerts: %w", failed, len(anomalies), firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// alerting/alerting_test.go
+package alerting
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock advances only when slept on or stepped by the test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestPacerSpacing(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		work     []time.Duration // Time the caller spends before each Wait
+		wantGaps []time.Duration // Time between consecutive sends
+	}{
+		{
+			name:     "back to back",
+			work:     []time.Duration{0, 0, 0, 0},
+			wantGaps: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "slow caller is not held up",
+			work:     []time.Duration{0, 3 * time.Second, 0, 0},
+			wantGaps: []time.Duration{3 * time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "no burst after a pause",
+			work:     []time.Duration{0, 0, 10 * time.Second, 0, 0},
+			wantGaps: []time.Duration{time.Second, 10 * time.Second, time.Second, time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: start}
+			pacer := NewPacer(time.Second, 0, 1, clock)
+
+			var sends []time.Time
+			for _, work := range tt.work {
+				clock.Sleep(work)
+				pacer.Wait()
+				sends = append(sends, clock.Now())
+			}
+			for i, want := range tt.wantGaps {
+				if gap := sends[i+1].Sub(sends[i]); gap != want {
+					t.Errorf("gap %d = %s, want %s", i, gap, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPacerJitterIsSeeded(t *testing.T) {
+	schedule := func(seed int64) []time.Time {
+		clock := &fakeClock{now: time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)}
+		pacer := NewPacer(time.Second, 500*time.Millisecond, seed, clock)
+		var sends []time.Time
+		for i := 0; i < 20; i++ {
+			pacer.Wait()
+			sends = append(sends, clock.Now())
+		}
+		return sends
+	}
+
+	first, second := schedule(42), schedule(42)
+	for i := range first {
+		if !first[i].Equal(second[i]) {
+			t.Fatalf("send %d at %s and %s with the same seed", i, first[i], second[i])
+		}
+		if i > 0 {
+			if gap := first[i].Sub(first[i-1]); gap < time.Second || gap > 1500*time.Millisecond {
+				t.Errorf("gap %d = %s, want between 1s and 1.5s", i, gap)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// alerting/kafka.go
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// KafkaProducer is the subset of a Kafka client used to publish alerts, so any client
+// library (or a test double) can be plugged in
+type KafkaProducer interface {
+	Produce(topic string, key []byte, value []byte) error
+	Flush() error
+	Close() error
+}
+
+// KafkaSink publishes each anomaly as a JSON message to a Kafka topic, keyed by account ID
+// so that alerts for one account stay ordered within a partition
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaSink creates a sink publishing to the given topic
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{
+		producer: producer,
+		topic:    topic,
+	}
+}
+
+// Send publishes a single anomaly
+func (s *KafkaSink) Send(anomaly models.Anomaly) error {
+	payload, err := json.Marshal(anomaly)
+	if err != nil {
+		return fmt.Errorf("error encoding anomaly %s: %w", anomaly.TransactionID, err)
+	}
+	if err := s.producer.Produce(s.topic, []byte(anomaly.AccountID), payload); err != nil {
+		return fmt.Errorf("error publishing anomaly %s to %s: %w", anomaly.TransactionID, s.topic, err)
+	}
+	return nil
+}
+
+// Flush blocks until all published messages are delivered
+func (s *KafkaSink) Flush() error {
+	return s.producer.Flush()
+}
+
+// Close releases the producer once the batch's alerts are flushed
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
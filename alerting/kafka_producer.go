@@ -0,0 +1,95 @@
+// alerting/kafka_producer.go
+package alerting
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultKafkaTimeout bounds a Flush, including retries and waiting for acknowledgement
+const DefaultKafkaTimeout = 10 * time.Second
+
+// KafkaConfig holds the connection settings of a WriterProducer
+type KafkaConfig struct {
+	Brokers []string      // Bootstrap broker host:port addresses
+	Timeout time.Duration // Bound on each Flush
+	TLS     *tls.Config   // Nil connects in plaintext
+}
+
+// messageWriter is the part of kafka.Writer a WriterProducer uses
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// WriterProducer is a KafkaProducer backed by a segmentio/kafka-go Writer. Produce buffers
+// messages in memory; Flush hands them to the writer, which splits them into batches within the
+// broker's size limits, follows partition leader changes, retries, and waits for all in-sync
+// replicas to acknowledge. Messages are partitioned by key the way the Java client does.
+type WriterProducer struct {
+	writer  messageWriter
+	timeout time.Duration
+	pending []kafka.Message
+}
+
+// NewWriterProducer creates a producer for the configured cluster
+func NewWriterProducer(config KafkaConfig) *WriterProducer {
+	return &WriterProducer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(config.Brokers...),
+			Balancer:     kafka.Murmur2Balancer{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+			Transport:    &kafka.Transport{TLS: config.TLS},
+		},
+		timeout: config.Timeout,
+	}
+}
+
+// Produce buffers a message until the next Flush
+func (p *WriterProducer) Produce(topic string, key []byte, value []byte) error {
+	p.pending = append(p.pending, kafka.Message{Topic: topic, Key: key, Value: value})
+	return nil
+}
+
+// Flush sends every buffered message, returning once the brokers have acknowledged them all.
+// Messages stay buffered if sending them fails.
+func (p *WriterProducer) Flush() error {
+	if len(p.pending) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	err := p.writer.WriteMessages(ctx, p.pending...)
+	if err == nil {
+		p.pending = nil
+		return nil
+	}
+
+	// Keep only the messages that were not delivered, so a retried Flush doesn't duplicate the rest
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) && len(writeErrors) == len(p.pending) {
+		var undelivered []kafka.Message
+		for i, messageErr := range writeErrors {
+			if messageErr != nil {
+				undelivered = append(undelivered, p.pending[i])
+			}
+		}
+		p.pending = undelivered
+	}
+	return fmt.Errorf("error writing %d messages to kafka: %w", len(p.pending), err)
+}
+
+// Close closes the writer's broker connections
+func (p *WriterProducer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,228 @@
+// alerting/kafka_test.go
+package alerting
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// mockProducer records what a KafkaSink publishes
+type mockProducer struct {
+	topics    []string
+	keys      []string
+	values    [][]byte
+	flushes   int
+	closed    bool
+	produceOK bool
+}
+
+func (p *mockProducer) Produce(topic string, key []byte, value []byte) error {
+	if !p.produceOK {
+		return errors.New("broker unavailable")
+	}
+	p.topics = append(p.topics, topic)
+	p.keys = append(p.keys, string(key))
+	p.values = append(p.values, value)
+	return nil
+}
+
+func (p *mockProducer) Flush() error {
+	p.flushes++
+	return nil
+}
+
+func (p *mockProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestKafkaSinkPublish(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	anomalies := []models.Anomaly{
+		{ID: "AN1", AccountID: "ACC1", TransactionID: "TX1", Timestamp: timestamp, Type: "large_debit", Severity: "high"},
+		{ID: "AN2", AccountID: "ACC2", TransactionID: "TX2", Timestamp: timestamp, Type: "overdraft", Severity: "medium"},
+		{ID: "AN3", AccountID: "ACC1", TransactionID: "TX3", Timestamp: timestamp, Type: "structuring", Severity: "high"},
+	}
+
+	tests := []struct {
+		name      string
+		produceOK bool
+		wantKeys  []string
+		wantErr   bool
+	}{
+		{name: "delivered", produceOK: true, wantKeys: []string{"ACC1", "ACC2", "ACC1"}},
+		{name: "producer failing", produceOK: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &mockProducer{produceOK: tt.produceOK}
+			sink := NewKafkaSink(producer, "siem-alerts")
+
+			err := Publish(sink, anomalies, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() error = %v, want error %v", err, tt.wantErr)
+			}
+			if producer.flushes != 1 {
+				t.Errorf("flushed %d times, want once", producer.flushes)
+			}
+			if !reflect.DeepEqual(producer.keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", producer.keys, tt.wantKeys)
+			}
+			for i, value := range producer.values {
+				if producer.topics[i] != "siem-alerts" {
+					t.Errorf("message %d went to topic %s", i, producer.topics[i])
+				}
+				var decoded models.Anomaly
+				if err := json.Unmarshal(value, &decoded); err != nil {
+					t.Fatalf("message %d is not an anomaly: %v", i, err)
+				}
+				if !reflect.DeepEqual(decoded, anomalies[i]) {
+					t.Errorf("message %d = %+v, want %+v", i, decoded, anomalies[i])
+				}
+			}
+
+			if err := sink.Close(); err != nil || !producer.closed {
+				t.Errorf("Close() = %v, producer closed %v", err, producer.closed)
+			}
+		})
+	}
+}
+
+// fakeWriter records the messages a WriterProducer writes, failing the ones selected
+type fakeWriter struct {
+	written [][]kafka.Message
+	fail    func(i int) bool
+	closed  bool
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	w.written = append(w.written, append([]kafka.Message(nil), messages...))
+	if w.fail == nil {
+		return nil
+	}
+	errs := make(kafka.WriteErrors, len(messages))
+	failed := false
+	for i := range messages {
+		if w.fail(i) {
+			errs[i] = kafka.LeaderNotAvailable
+			failed = true
+		}
+	}
+	if failed {
+		return errs
+	}
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestWriterProducer(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        func(i int) bool
+		wantErr     bool
+		wantPending []string // Keys still buffered after the flush
+	}{
+		{name: "delivered"},
+		{name: "all rejected", fail: func(int) bool { return true }, wantErr: true, wantPending: []string{"ACC0", "ACC1", "ACC2", "ACC0"}},
+		{name: "partly delivered", fail: func(i int) bool { return i%2 == 1 }, wantErr: true, wantPending: []string{"ACC1", "ACC0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{fail: tt.fail}
+			producer := &WriterProducer{writer: writer, timeout: time.Second}
+
+			var wantKeys []string
+			for i := 0; i < 4; i++ {
+				key := "ACC" + strconv.Itoa(i%3)
+				wantKeys = append(wantKeys, key)
+				if err := producer.Produce("alerts", []byte(key), []byte(`{"id":"AN`+strconv.Itoa(i)+`"}`)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(writer.written) != 0 {
+				t.Fatal("Produce wrote before Flush")
+			}
+
+			err := producer.Flush()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Flush() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(writer.written) != 1 {
+				t.Fatalf("Flush wrote %d times, want once", len(writer.written))
+			}
+			var gotKeys []string
+			for i, message := range writer.written[0] {
+				gotKeys = append(gotKeys, string(message.Key))
+				if message.Topic != "alerts" || string(message.Value) != `{"id":"AN`+strconv.Itoa(i)+`"}` {
+					t.Errorf("message %d = %s %s, want alerts {\"id\":\"AN%d\"}", i, message.Topic, message.Value, i)
+				}
+			}
+			if !reflect.DeepEqual(gotKeys, wantKeys) {
+				t.Errorf("keys = %v, want %v", gotKeys, wantKeys)
+			}
+
+			var pending []string
+			for _, message := range producer.pending {
+				pending = append(pending, string(message.Key))
+			}
+			if !reflect.DeepEqual(pending, tt.wantPending) {
+				t.Errorf("still buffered %v, want %v", pending, tt.wantPending)
+			}
+
+			// A flush with nothing buffered doesn't touch the cluster
+			producer.pending = nil
+			if err := producer.Flush(); err != nil || len(writer.written) != 1 {
+				t.Errorf("empty Flush() = %v after %d writes", err, len(writer.written))
+			}
+			if err := producer.Close(); err != nil || !writer.closed {
+				t.Errorf("Close() = %v, writer closed %v", err, writer.closed)
+			}
+		})
+	}
+}
+
+func TestNewWriterProducer(t *testing.T) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	tests := []struct {
+		name   string
+		config KafkaConfig
+	}{
+		{name: "plaintext", config: KafkaConfig{Brokers: []string{"b1:9092"}, Timeout: time.Second}},
+		{name: "tls", config: KafkaConfig{Brokers: []string{"b1:9093", "b2:9093"}, Timeout: time.Second, TLS: tlsConfig}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, ok := NewWriterProducer(tt.config).writer.(*kafka.Writer)
+			if !ok {
+				t.Fatal("producer is not backed by a kafka.Writer")
+			}
+			if writer.Addr.String() != strings.Join(tt.config.Brokers, ",") {
+				t.Errorf("brokers = %s, want %v", writer.Addr, tt.config.Brokers)
+			}
+			if _, ok := writer.Balancer.(kafka.Murmur2Balancer); !ok {
+				t.Errorf("balancer = %T, want the Java-compatible murmur2 balancer", writer.Balancer)
+			}
+			if writer.RequiredAcks != kafka.RequireAll {
+				t.Errorf("required acks = %v, want all", writer.RequiredAcks)
+			}
+			if transport, ok := writer.Transport.(*kafka.Transport); !ok || transport.TLS != tt.config.TLS {
+				t.Errorf("transport = %+v, want TLS config %v", writer.Transport, tt.config.TLS)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package calendar determines bank business days
+// calendar/business_calendar.go
+package calendar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// BusinessCalendar knows which days are weekends or holidays
+type BusinessCalendar struct {
+	Holidays map[string]bool       // Holiday dates in YYYY-MM-DD format
+	Weekend  map[time.Weekday]bool // Non-business days of the week
+}
+
+// DefaultCalendar returns a calendar with a Saturday/Sunday weekend and no holidays
+func DefaultCalendar() BusinessCalendar {
+	return BusinessCalendar{
+		Holidays: make(map[string]bool),
+		Weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+	}
+}
+
+// IsBusinessDay reports whether the date is neither a weekend day nor a holiday
+func (c BusinessCalendar) IsBusinessDay(date time.Time) bool {
+	return !c.Weekend[date.Weekday()] && !c.Holidays[date.Format("2006-01-02")]
+}
+
+// PreviousBusinessDay returns the last business day strictly before the date
+func (c BusinessCalendar) PreviousBusinessDay(date time.Time) time.Time {
+	day := date.AddDate(0, 0, -1)
+	// A full year without a business day means the calendar is misconfigured
+	for i := 0; i < 366 && !c.IsBusinessDay(day); i++ {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// NextBusinessDay returns the first business day on or after the date, used as the
+// value date for transactions booked on a non-business day
+func (c BusinessCalendar) NextBusinessDay(date time.Time) time.Time {
+	day := date
+	for i := 0; i < 366 && !c.IsBusinessDay(day); i++ {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// LoadHolidays loads holiday dates from a CSV file whose first column is a YYYY-MM-DD date
+func LoadHolidays(filePath string) (map[string]bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening holidays file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	holidays := make(map[string]bool)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date at line %d: %w", i+1, err)
+		}
+		holidays[date.Format("2006-01-02")] = true
+	}
+
+	return holidays, nil
+}
+
+// ParseWeekend parses a comma-separated list of weekday names (e.g. "Sat,Sun")
+func ParseWeekend(list string) (map[time.Weekday]bool, error) {
+	weekend := make(map[time.Weekday]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			full := strings.ToLower(day.String())
+			if name == full || name == full[:3] {
+				weekend[day] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+	}
+	return weekend, nil
+}
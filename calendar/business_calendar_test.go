@@ -0,0 +1,51 @@
+// calendar/business_calendar_test.go
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreviousBusinessDay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.csv")
+	if err := os.WriteFile(path, []byte("date,name\n2025-04-18,Good Friday\n2025-04-21,Easter Monday\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	holidays, err := LoadHolidays(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time { return time.Date(2025, 4, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		weekend string
+		today   time.Time
+		want    time.Time
+	}{
+		{name: "ordinary weekday", weekend: "Sat,Sun", today: day(17), want: day(16)},
+		{name: "prior day a holiday", weekend: "Sat,Sun", today: day(22), want: day(17)},
+		{name: "after a weekend", weekend: "Sat,Sun", today: day(28), want: day(25)},
+		{name: "Friday/Saturday weekend", weekend: "friday,saturday", today: day(27), want: day(24)},
+		{name: "no weekend", weekend: "", today: day(20), want: day(19)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weekend, err := ParseWeekend(tt.weekend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			calendar := BusinessCalendar{Holidays: holidays, Weekend: weekend}
+			if got := calendar.PreviousBusinessDay(tt.today); !got.Equal(tt.want) {
+				t.Errorf("PreviousBusinessDay(%s) = %s, want %s",
+					tt.today.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+
+	if _, err := ParseWeekend("Sat,Funday"); err == nil {
+		t.Error("ParseWeekend accepted an unknown weekday")
+	}
+}
@@ -0,0 +1,67 @@
+// detector/anomaly_cap.go
+package detector
+
+import (
+	"fmt"
+	"sort"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// severityRanks orders severities so the most serious anomalies are kept first
+var severityRanks = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+// severityRank returns a severity's rank, ranking unknown and empty severities after low
+func severityRank(severity string) int {
+	if rank, ok := severityRanks[severity]; ok {
+		return rank
+	}
+	return len(severityRanks)
+}
+
+// CapAnomaliesPerAccount keeps at most maxPerAccount anomalies for each account, preferring
+// higher severities and then detection order. Each capped account gets a trailing
+// "anomalies_suppressed" entry recording how many were dropped. A cap of 0 disables the limit.
+func CapAnomaliesPerAccount(anomalies []models.Anomaly, maxPerAccount int) []models.Anomaly {
+	if maxPerAccount <= 0 {
+		return anomalies
+	}
+
+	// Group anomalies by account, remembering the order accounts were first seen
+	byAccount := make(map[string][]models.Anomaly)
+	var order []string
+	for _, anomaly := range anomalies {
+		if _, seen := byAccount[anomaly.AccountID]; !seen {
+			order = append(order, anomaly.AccountID)
+		}
+		byAccount[anomaly.AccountID] = append(byAccount[anomaly.AccountID], anomaly)
+	}
+
+	capped := make([]models.Anomaly, 0, len(anomalies))
+	var trailers []models.Anomaly
+	for _, accountID := range order {
+		accountAnomalies := byAccount[accountID]
+		if len(accountAnomalies) <= maxPerAccount {
+			capped = append(capped, accountAnomalies...)
+			continue
+		}
+
+		sort.SliceStable(accountAnomalies, func(i, j int) bool {
+			return severityRank(accountAnomalies[i].Severity) < severityRank(accountAnomalies[j].Severity)
+		})
+		capped = append(capped, accountAnomalies[:maxPerAccount]...)
+
+		suppressed := len(accountAnomalies) - maxPerAccount
+		trailer := models.Anomaly{
+			AccountID:   accountID,
+			Timestamp:   accountAnomalies[maxPerAccount-1].Timestamp,
+			Type:        "anomalies_suppressed",
+			Description: fmt.Sprintf("%d additional anomalies suppressed by the per-account cap of %d", suppressed, maxPerAccount),
+			Severity:    "low",
+		}
+		trailer.ID = AnomalyID(trailer, float64(suppressed))
+		trailers = append(trailers, trailer)
+	}
+
+	return append(capped, trailers...)
+}
@@ -0,0 +1,54 @@
+// detector/anomaly_cap_test.go
+package detector
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestCapAnomaliesPerAccount(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	anomaly := func(id, severity string) models.Anomaly {
+		return models.Anomaly{ID: id, AccountID: "ACC1", Timestamp: timestamp, Type: "large_debit", Severity: severity}
+	}
+
+	tests := []struct {
+		name          string
+		severities    []string
+		maxPerAccount int
+		wantKept      []string
+	}{
+		{name: "uncapped", severities: []string{"low", "", "high"}, maxPerAccount: 0, wantKept: []string{"AN1", "AN2", "AN3"}},
+		{name: "under the cap", severities: []string{"low", "high"}, maxPerAccount: 2, wantKept: []string{"AN1", "AN2"}},
+		{name: "by severity", severities: []string{"low", "medium", "high"}, maxPerAccount: 2, wantKept: []string{"AN3", "AN2"}},
+		{name: "ties keep detection order", severities: []string{"high", "low", "high"}, maxPerAccount: 2, wantKept: []string{"AN1", "AN3"}},
+		{name: "empty severity ranks last", severities: []string{"", "low", "medium"}, maxPerAccount: 2, wantKept: []string{"AN3", "AN2"}},
+		{name: "unknown severity ranks last", severities: []string{"critical", "", "low"}, maxPerAccount: 1, wantKept: []string{"AN3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var anomalies []models.Anomaly
+			for i, severity := range tt.severities {
+				anomalies = append(anomalies, anomaly("AN"+strconv.Itoa(i+1), severity))
+			}
+
+			capped := CapAnomaliesPerAccount(anomalies, tt.maxPerAccount)
+			var kept []string
+			for _, anomaly := range capped {
+				if anomaly.Type != "anomalies_suppressed" {
+					kept = append(kept, anomaly.ID)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept %v, want %v", kept, tt.wantKept)
+			}
+			if suppressed := len(capped) - len(kept); suppressed != min(1, len(tt.severities)-len(tt.wantKept)) {
+				t.Errorf("got %d suppression trailers for %d dropped anomalies", suppressed, len(tt.severities)-len(tt.wantKept))
+			}
+		})
+	}
+}
@@ -3,28 +3,163 @@ package detector
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"DailyTransactionBatchProcessing/models"
 )
 
 // Constants for anomaly detection
 const (
-	LargeTransactionThreshold     = 10000.0 // Transactions above this amount are considered large
-	RapidWithdrawalThreshold      = 3       // Number of withdrawals in short period considered suspicious
-	RapidWithdrawalTimeWindowMins = 60      // Time window in minutes for rapid withdrawal detection
-	OverdraftLimit                = -1000.0 // Maximum allowed overdraft
-	MaxDailyWithdrawalLimit       = 5000.0  // Maximum daily withdrawal limit
+	RapidWithdrawalThreshold      = 3  // Number of withdrawals in short period considered suspicious
+	RapidWithdrawalTimeWindowMins = 60 // Time window in minutes for rapid withdrawal detection
 )
 
+// Config holds the thresholds used for anomaly detection
+type Config struct {
+	// The limits processing enforced, so anomalies are measured against the same policy
+	models.Limits
+
+	LargeCreditThreshold          float64 `json:"large_credit_threshold"` // Credits at or above this are flagged as large_credit
+	LargeDebitThreshold           float64 `json:"large_debit_threshold"`  // Debits at or above this are flagged as large_debit
+	RapidWithdrawalThreshold      int     `json:"rapid_withdrawal_threshold"`
+	RapidWithdrawalTimeWindowMins float64 `json:"rapid_withdrawal_time_window_mins"`
+	NearLimitMargin               float64 `json:"near_limit_margin"`          // Distance above the overdraft limit flagged as near; 0 disables
+	CarryForwardTolerance         float64 `json:"carry_forward_tolerance"`    // Allowed gap between opening and prior closing balances
+	BalanceDrainFraction          float64 `json:"balance_drain_fraction"`     // Share of the opening balance transferred out in a day that is flagged; 0 disables
+	CoolingOffPeriodMins          float64 `json:"cooling_off_period_mins"`    // Minimum gap between large transactions; 0 disables
+	NetPositionLongLimit          float64 `json:"net_position_long_limit"`    // Maximum net inflow per day; 0 disables
+	NetPositionShortLimit         float64 `json:"net_position_short_limit"`   // Maximum net outflow per day; 0 disables
+	FalsePositiveWindowDays       int     `json:"false_positive_window_days"` // Days a confirmed false positive suppresses matching alerts
+	MaxAnomaliesPerAccount        int     `json:"max_anomalies_per_account"`  // Anomalies emitted per account; 0 means no cap
+	RepeatOverdraftThreshold      int     `json:"repeat_overdraft_threshold"` // Overdraft count above which overdraft severity escalates; 0 disables
+	VerboseOverdrafts             bool    `json:"verbose_overdrafts"`         // Report every transaction leaving an account overdrawn instead of one anomaly per account
+
+	// Sequential ID bursts: fires when an account has at least SequentialIDMinRun transactions
+	// whose numeric IDs step by no more than SequentialIDMaxGap
+	SequentialIDMinRun int   `json:"sequential_id_min_run"` // 0 disables
+	SequentialIDMaxGap int64 `json:"sequential_id_max_gap"`
+
+	// Pass-through: a credit followed within the window by an outflow within tolerance of its amount
+	PassThroughWindowMins float64 `json:"pass_through_window_mins"` // 0 disables
+	PassThroughTolerance  float64 `json:"pass_through_tolerance"`   // Fraction of the credit amount
+
+	// Structuring: fires when an account has at least StructuringMinCount debits in a day within
+	// StructuringBand below LargeTransactionThreshold
+	StructuringBand     float64 `json:"structuring_band"` // 0 disables
+	StructuringMinCount int     `json:"structuring_min_count"`
+
+	// Synchronized timestamps: fires when more than this many distinct accounts share one exact timestamp
+	SynchronizedTimestampMaxAccounts int `json:"synchronized_timestamp_max_accounts"` // 0 disables
+
+	// System-wide rejection spike: fires when the batch rejection rate exceeds baseline * factor
+	RejectionRateBaseline         float64 `json:"rejection_rate_baseline"` // Historical rejection rate; 0 disables
+	RejectionSpikeFactor          float64 `json:"rejection_spike_factor"`
+	RejectionSpikeMinTransactions int     `json:"rejection_spike_min_transactions"` // Smallest batch evaluated
+
+	// The day's context for rules comparing against the start of the day or the prior day
+	ProcessDate     time.Time                 `json:"-"`
+	OpeningAccounts map[string]models.Account `json:"-"` // Accounts as loaded before processing
+	PriorSummaries  []models.AccountSummary   `json:"-"` // Prior business day's account summary
+	// Transactions that failed validation, which count as rejections in the rejection spike rate
+	InvalidTransactions []models.Transaction `json:"-"`
+
+	Workers int `json:"workers"` // Detection stages evaluated concurrently; 1 is serial
+}
+
+// DefaultConfig returns the thresholds used when no config is supplied
+func DefaultConfig() Config {
+	return Config{
+		Limits:                           models.DefaultLimits(),
+		LargeCreditThreshold:             models.DefaultLargeTransactionThreshold,
+		LargeDebitThreshold:              models.DefaultLargeTransactionThreshold,
+		RapidWithdrawalThreshold:         RapidWithdrawalThreshold,
+		RapidWithdrawalTimeWindowMins:    RapidWithdrawalTimeWindowMins,
+		NearLimitMargin:                  50,
+		CarryForwardTolerance:            0.01,
+		BalanceDrainFraction:             0.9,
+		FalsePositiveWindowDays:          30,
+		RepeatOverdraftThreshold:         2,
+		SequentialIDMinRun:               5,
+		SequentialIDMaxGap:               1,
+		PassThroughWindowMins:            60,
+		PassThroughTolerance:             0.05,
+		StructuringBand:                  1000,
+		StructuringMinCount:              4,
+		SynchronizedTimestampMaxAccounts: 5,
+		RejectionRateBaseline:            0.05,
+		RejectionSpikeFactor:             3,
+		RejectionSpikeMinTransactions:    10,
+		Workers:                          1,
+	}
+}
+
 // DetectAnomalies analyzes processed transactions for suspicious patterns
 func DetectAnomalies(
 	transactions []models.Transaction,
 	accounts map[string]models.Account,
 ) []models.Anomaly {
+	return DetectAnomaliesWithConfig(transactions, accounts, DefaultConfig())
+}
+
+// DetectAnomaliesWithConfig analyzes processed transactions using the supplied thresholds
+func DetectAnomaliesWithConfig(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	// Built-in rules run first, then registered ones; results are concatenated in this order
+	// regardless of how many workers evaluate them
+	rules := append(builtinRules(), RegisteredRules()...)
+	stages := make([]func() []models.Anomaly, len(rules))
+	for i, rule := range rules {
+		stages[i] = func() []models.Anomaly { return rule.Evaluate(transactions, accounts, config) }
+	}
+
+	anomalies := runStages(stages, config.Workers)
+	assignAnomalyIDs(anomalies, transactions)
+
+	return anomalies
+}
+
+// runStages evaluates detection stages using up to workers goroutines and
+// concatenates their results in stage order
+func runStages(stages []func() []models.Anomaly, workers int) []models.Anomaly {
+	results := make([][]models.Anomaly, len(stages))
+	if workers <= 1 {
+		for i, stage := range stages {
+			results[i] = stage()
+		}
+	} else {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, workers)
+		for i, stage := range stages {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int, stage func() []models.Anomaly) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				results[i] = stage()
+			}(i, stage)
+		}
+		wg.Wait()
+	}
+
+	anomalies := []models.Anomaly{}
+	for _, result := range results {
+		anomalies = append(anomalies, result...)
+	}
+	return anomalies
+}
+
+// detectLargeTransactions checks each completed transaction for large amounts and
+// cooling-off violations
+func detectLargeTransactions(transactions []models.Transaction, config Config) []models.Anomaly {
 	anomalies := []models.Anomaly{}
 
-	// Track withdrawals by account for rapid withdrawal detection
-	withdrawalsByAccount := make(map[string][]models.Transaction)
+	// Track the last large transaction by account for cooling-off detection
+	lastLargeByAccount := make(map[string]models.Transaction)
 
 	// Process each transaction for anomalies
 	for _, transaction := range transactions {
@@ -33,82 +168,387 @@ func DetectAnomalies(
 			continue
 		}
 
-		// Check for large transactions
-		if transaction.Amount >= LargeTransactionThreshold {
+		// Check for large transactions; unexpected large inflows carry the most risk
+		switch {
+		case transaction.Type == "credit" && transaction.Amount >= models.Cents(config.LargeCreditThreshold):
+			anomalies = append(anomalies, models.Anomaly{
+				TransactionID: transaction.ID,
+				AccountID:     transaction.AccountID,
+				Timestamp:     transaction.Timestamp,
+				Type:          "large_credit",
+				Description:   fmt.Sprintf("Large credit: $%s", transaction.Amount),
+				Severity:      "high",
+			})
+		case transaction.Type == "debit" && transaction.Amount >= models.Cents(config.LargeDebitThreshold):
+			anomalies = append(anomalies, models.Anomaly{
+				TransactionID: transaction.ID,
+				AccountID:     transaction.AccountID,
+				Timestamp:     transaction.Timestamp,
+				Type:          "large_debit",
+				Description:   fmt.Sprintf("Large debit: $%s", transaction.Amount),
+				Severity:      "medium",
+			})
+		case transaction.Type == "transfer" && transaction.Amount >= models.Cents(config.LargeTransactionThreshold):
 			anomalies = append(anomalies, models.Anomaly{
 				TransactionID: transaction.ID,
 				AccountID:     transaction.AccountID,
 				Timestamp:     transaction.Timestamp,
 				Type:          "large_transaction",
-				Description:   fmt.Sprintf("Large transaction: $%.2f", transaction.Amount),
+				Description:   fmt.Sprintf("Large transaction: $%s", transaction.Amount),
 				Severity:      "medium",
 			})
 		}
 
-		// Track withdrawals for rapid withdrawal detection
-		if transaction.Type == "debit" {
-			withdrawalsByAccount[transaction.AccountID] = append(
-				withdrawalsByAccount[transaction.AccountID],
-				transaction,
-			)
+		if transaction.Amount >= models.Cents(config.LargeTransactionThreshold) {
+			// Check for a large transaction inside the cooling-off period of the previous one
+			if config.CoolingOffPeriodMins > 0 {
+				if previous, exists := lastLargeByAccount[transaction.AccountID]; exists {
+					gap := transaction.Timestamp.Sub(previous.Timestamp)
+					if gap.Minutes() < config.CoolingOffPeriodMins {
+						anomalies = append(anomalies, models.Anomaly{
+							TransactionID: transaction.ID,
+							AccountID:     transaction.AccountID,
+							Timestamp:     transaction.Timestamp,
+							Type:          "cooling_off_violation",
+							Description: fmt.Sprintf("Large transaction %d minutes after large transaction %s (minimum %d minutes)",
+								int(gap.Minutes()), previous.ID, int(config.CoolingOffPeriodMins)),
+							Severity: "medium",
+						})
+					}
+				}
+				lastLargeByAccount[transaction.AccountID] = transaction
+			}
 		}
+	}
+
+	return anomalies
+}
+
+// detectOverdrafts reports the accounts that went into overdraft during the day
+func detectOverdrafts(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+
+	// Track the transaction leaving each account at its lowest negative balance, in the order
+	// accounts first went into overdraft
+	worstOverdraft := make(map[string]models.Transaction)
+	overdraftOrder := []string{}
 
-		// Check for accounts in overdraft
-		account := accounts[transaction.AccountID]
-		if account.Balance < 0 {
-			severity := "low"
-			if account.Balance < OverdraftLimit/2 {
-				severity = "medium"
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+
+		// Check for transactions that left the account in overdraft
+		if transaction.BalanceAfter < 0 {
+			if config.VerboseOverdrafts {
+				anomalies = append(anomalies, overdraftAnomaly(transaction, transaction.BalanceAfter, accounts, config))
 			}
-			if account.Balance < OverdraftLimit*0.8 {
-				severity = "high"
+			if worst, exists := worstOverdraft[transaction.AccountID]; !exists || transaction.BalanceAfter < worst.BalanceAfter {
+				if !exists {
+					overdraftOrder = append(overdraftOrder, transaction.AccountID)
+				}
+				worstOverdraft[transaction.AccountID] = transaction
 			}
+		}
+	}
+
+	// Report each overdrawn account once at the lowest balance it reached
+	for _, accountID := range overdraftOrder {
+		transaction := worstOverdraft[accountID]
+		if !config.VerboseOverdrafts {
+			anomaly := overdraftAnomaly(transaction, transaction.BalanceAfter, accounts, config)
+			anomaly.Description = fmt.Sprintf("Account in overdraft: lowest balance $%s", transaction.BalanceAfter)
+			anomalies = append(anomalies, anomaly)
+		}
 
+		// Report accounts that keep going into overdraft
+		account := accounts[accountID]
+		if config.RepeatOverdraftThreshold > 0 && account.OverdraftCount > config.RepeatOverdraftThreshold {
 			anomalies = append(anomalies, models.Anomaly{
 				TransactionID: transaction.ID,
-				AccountID:     transaction.AccountID,
+				AccountID:     accountID,
 				Timestamp:     transaction.Timestamp,
-				Type:          "account_overdraft",
-				Description:   fmt.Sprintf("Account in overdraft: $%.2f", account.Balance),
-				Severity:      severity,
+				Type:          "repeat_overdraft",
+				Description: fmt.Sprintf("Account overdrawn %d times (more than %d)",
+					account.OverdraftCount, config.RepeatOverdraftThreshold),
+				Severity: "high",
 			})
 		}
 	}
 
-	// Detect rapid withdrawals (multiple withdrawals in a short time period)
-	for accountID, withdrawals := range withdrawalsByAccount {
-		// Sort withdrawals by timestamp (in a real system)
-		// For simplicity, we assume they're already in order
+	return anomalies
+}
 
-		// Check for rapid withdrawals
-		if len(withdrawals) >= RapidWithdrawalThreshold {
-			for i := RapidWithdrawalThreshold - 1; i < len(withdrawals); i++ {
-				start := i - (RapidWithdrawalThreshold - 1)
-				timeWindow := withdrawals[i].Timestamp.Sub(withdrawals[start].Timestamp)
+// overdraftAnomaly reports an account in overdraft at the given balance, graded by how far it has
+// used the overdraft limit processing applied to it, escalating the severity of accounts that
+// keep going into overdraft
+func overdraftAnomaly(
+	transaction models.Transaction,
+	balance models.Money,
+	accounts map[string]models.Account,
+	config Config,
+) models.Anomaly {
+	limit := config.OverdraftLimitFor(accounts[transaction.AccountID])
+	severity := "low"
+	if balance < limit/2 {
+		severity = "medium"
+	}
+	if balance < limit.Mul(0.8) {
+		severity = "high"
+	}
+	if overdraftCount := accounts[transaction.AccountID].OverdraftCount; config.RepeatOverdraftThreshold > 0 &&
+		overdraftCount > config.RepeatOverdraftThreshold {
+		severity = escalateSeverity(severity)
+	}
 
-				// If the time window between N withdrawals is less than the threshold
-				if timeWindow.Minutes() <= RapidWithdrawalTimeWindowMins {
-					totalAmount := 0.0
-					for j := start; j <= i; j++ {
-						totalAmount += withdrawals[j].Amount
-					}
+	return models.Anomaly{
+		TransactionID: transaction.ID,
+		AccountID:     transaction.AccountID,
+		Timestamp:     transaction.Timestamp,
+		Type:          "account_overdraft",
+		Description:   fmt.Sprintf("Account in overdraft: $%s", balance),
+		Severity:      severity,
+	}
+}
+
+// escalateSeverity returns the next severity up, leaving high as is
+func escalateSeverity(severity string) string {
+	switch severity {
+	case "low":
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// detectRapidWithdrawals flags multiple withdrawals on one account in a short time period
+func detectRapidWithdrawals(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+
+	// Track withdrawals by account, remembering the order accounts were first seen
+	withdrawalsByAccount := make(map[string][]models.Transaction)
+	accountOrder := []string{}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" || transaction.Type != "debit" {
+			continue
+		}
+		if _, seen := withdrawalsByAccount[transaction.AccountID]; !seen {
+			accountOrder = append(accountOrder, transaction.AccountID)
+		}
+		withdrawalsByAccount[transaction.AccountID] = append(
+			withdrawalsByAccount[transaction.AccountID],
+			transaction,
+		)
+	}
+
+	for _, accountID := range accountOrder {
+		withdrawals := withdrawalsByAccount[accountID]
+		sort.SliceStable(withdrawals, func(i, j int) bool {
+			return withdrawals[i].Timestamp.Before(withdrawals[j].Timestamp)
+		})
+
+		// Report a burst of withdrawals spanning from first to last
+		report := func(first, last int) {
+			totalAmount := models.Money(0)
+			for j := first; j <= last; j++ {
+				totalAmount += withdrawals[j].Amount
+			}
+			span := withdrawals[last].Timestamp.Sub(withdrawals[first].Timestamp)
+			anomalies = append(anomalies, models.Anomaly{
+				TransactionID: withdrawals[last].ID,
+				AccountID:     accountID,
+				Timestamp:     withdrawals[last].Timestamp,
+				Type:          "rapid_withdrawals",
+				Description: fmt.Sprintf("%d withdrawals totaling $%s in %d minutes",
+					last-first+1, totalAmount, int(span.Minutes())),
+				Severity: "high",
+			})
+		}
+
+		// Slide a time window over the withdrawals. A burst starts when the window holds at least
+		// the threshold count and lasts until it no longer does; withdrawals already reported in a
+		// burst do not count toward the next one.
+		start, burstStart, burstEnd := 0, -1, -1
+		for i := range withdrawals {
+			for withdrawals[i].Timestamp.Sub(withdrawals[start].Timestamp).Minutes() > config.RapidWithdrawalTimeWindowMins {
+				start++
+			}
+			windowStart := start
+			if burstStart < 0 && burstEnd >= windowStart {
+				windowStart = burstEnd + 1
+			}
 
-					anomalies = append(anomalies, models.Anomaly{
-						TransactionID: withdrawals[i].ID,
-						AccountID:     accountID,
-						Timestamp:     withdrawals[i].Timestamp,
-						Type:          "rapid_withdrawals",
-						Description: fmt.Sprintf("%d withdrawals totaling $%.2f in %d minutes",
-							RapidWithdrawalThreshold, totalAmount, int(timeWindow.Minutes())),
-						Severity: "high",
-					})
-
-					// Only report once per series to avoid duplicate alerts
-					break
+			if i-windowStart+1 >= config.RapidWithdrawalThreshold {
+				if burstStart < 0 {
+					burstStart = windowStart
 				}
+				burstEnd = i
+			} else if burstStart >= 0 {
+				report(burstStart, burstEnd)
+				burstStart = -1
 			}
 		}
+		if burstStart >= 0 {
+			report(burstStart, burstEnd)
+		}
+	}
+
+	return anomalies
+}
+
+// detectUnexpectedOverdrafts flags accounts that are not eligible for overdraft but closed negative
+func detectUnexpectedOverdrafts(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	// Find the last completed outflow for each overdrawn account of a no-overdraft type
+	lastOutflow := make(map[string]models.Transaction)
+	order := []string{}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" || transaction.Type == "credit" {
+			continue
+		}
+
+		account, exists := accounts[transaction.AccountID]
+		if !exists || account.Balance >= 0 || !config.NoOverdraftAccountTypes[account.AccountType] {
+			continue
+		}
+
+		if _, seen := lastOutflow[transaction.AccountID]; !seen {
+			order = append(order, transaction.AccountID)
+		}
+		lastOutflow[transaction.AccountID] = transaction
+	}
+
+	anomalies := []models.Anomaly{}
+	for _, accountID := range order {
+		transaction := lastOutflow[accountID]
+		account := accounts[accountID]
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: transaction.ID,
+			AccountID:     accountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "unexpected_overdraft",
+			Description: fmt.Sprintf("%s account not eligible for overdraft has balance $%s after %s",
+				account.AccountType, account.Balance, transaction.Type),
+			Severity: "high",
+		})
 	}
 
 	return anomalies
 }
+
+// detectPositionLimitBreaches flags accounts whose net flow for the day exceeds the long or short limit
+func detectPositionLimitBreaches(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.NetPositionLongLimit <= 0 && config.NetPositionShortLimit <= 0 {
+		return anomalies
+	}
+
+	// Compute net flow (credits minus debits) and the last transaction for each account
+	netFlow := make(map[string]models.Money)
+	lastTransaction := make(map[string]models.Transaction)
+	order := []string{}
+	track := func(accountID string, amount models.Money, transaction models.Transaction) {
+		if _, seen := lastTransaction[accountID]; !seen {
+			order = append(order, accountID)
+		}
+		netFlow[accountID] += amount
+		lastTransaction[accountID] = transaction
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+
+		// A reversal offsets the flow of the transaction it undid
+		legs, sign := transaction, models.Money(1)
+		if transaction.Type == "reversal" {
+			legs, sign = transaction.Reversed(), -1
+		}
+		switch legs.Type {
+		case "credit":
+			track(legs.AccountID, sign*legs.Amount, transaction)
+		case "debit":
+			track(legs.AccountID, -sign*legs.Amount, transaction)
+		case "transfer":
+			track(legs.AccountID, -sign*legs.Amount, transaction)
+			track(legs.DestinationAccountID, sign*legs.Amount, transaction)
+		}
+	}
+
+	for _, accountID := range order {
+		net := netFlow[accountID]
+		description := ""
+		if config.NetPositionLongLimit > 0 && net > models.Cents(config.NetPositionLongLimit) {
+			description = fmt.Sprintf("Net long position $%s exceeds limit of $%.2f", net, config.NetPositionLongLimit)
+		} else if config.NetPositionShortLimit > 0 && -net > models.Cents(config.NetPositionShortLimit) {
+			description = fmt.Sprintf("Net short position $%s exceeds limit of $%.2f", -net, config.NetPositionShortLimit)
+		}
+		if description == "" {
+			continue
+		}
+
+		transaction := lastTransaction[accountID]
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: transaction.ID,
+			AccountID:     accountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "position_limit_breach",
+			Description:   description,
+			Severity:      "high",
+		})
+	}
+
+	return anomalies
+}
+
+// detectRejectionSpike emits a single system-level anomaly when the batch rejection rate
+// exceeds the configured baseline by the spike factor. The rate covers the day's submitted
+// transactions: those rejected in validation count as rejections, and generated fee rows are
+// left out.
+func detectRejectionSpike(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.RejectionRateBaseline <= 0 {
+		return anomalies
+	}
+
+	submitted := len(config.InvalidTransactions)
+	rejected := len(config.InvalidTransactions)
+	latest := models.Transaction{Timestamp: config.ProcessDate}
+	for _, transaction := range transactions {
+		if transaction.Generated() {
+			continue
+		}
+		submitted++
+		if transaction.Status == "rejected" {
+			rejected++
+		}
+		if transaction.Timestamp.After(latest.Timestamp) {
+			latest = transaction
+		}
+	}
+	if submitted == 0 || submitted < config.RejectionSpikeMinTransactions {
+		return anomalies
+	}
+
+	rate := float64(rejected) / float64(submitted)
+	threshold := config.RejectionRateBaseline * config.RejectionSpikeFactor
+	if rate <= threshold {
+		return anomalies
+	}
+
+	return append(anomalies, models.Anomaly{
+		Timestamp: latest.Timestamp,
+		Type:      "rejection_spike",
+		Description: fmt.Sprintf("%d of %d transactions rejected (%.1f%%), above %.1f%% threshold from %.1f%% baseline",
+			rejected, submitted, rate*100, threshold*100, config.RejectionRateBaseline*100),
+		Severity: "high",
+	})
+}
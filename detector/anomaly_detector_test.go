@@ -0,0 +1,367 @@
+// detector/anomaly_detector_test.go
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectUnexpectedOverdrafts(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	accounts := map[string]models.Account{
+		"SAV1": {ID: "SAV1", AccountType: "savings", Balance: models.Cents(-25)},
+		"ISA1": {ID: "ISA1", AccountType: "isa", Balance: models.Cents(-10)},
+		"CHK1": {ID: "CHK1", AccountType: "checking", Balance: models.Cents(-500)},
+	}
+	var transactions []models.Transaction
+	for id := range accounts {
+		transactions = append(transactions, models.Transaction{ID: "TX-" + id, AccountID: id, Timestamp: timestamp,
+			Amount: models.Cents(100), Type: "debit", Status: "completed"})
+	}
+
+	tests := []struct {
+		name  string
+		types map[string]bool
+		want  map[string]bool
+	}{
+		{name: "default types", types: DefaultConfig().NoOverdraftAccountTypes, want: map[string]bool{"SAV1": true}},
+		{name: "flag types", types: map[string]bool{"savings": true, "isa": true}, want: map[string]bool{"SAV1": true, "ISA1": true}},
+		{name: "none", types: map[string]bool{}, want: map[string]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.NoOverdraftAccountTypes = tt.types
+			anomalies := detectUnexpectedOverdrafts(transactions, accounts, config)
+			if len(anomalies) != len(tt.want) {
+				t.Fatalf("got %d anomalies, want %d: %+v", len(anomalies), len(tt.want), anomalies)
+			}
+			for _, anomaly := range anomalies {
+				if !tt.want[anomaly.AccountID] {
+					t.Errorf("unexpected anomaly for %s", anomaly.AccountID)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectOverdrafts(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	var transactions []models.Transaction
+	for i, balance := range []float64{-100, -300, -600, -450, -200} {
+		transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("TX%d", i+1), AccountID: "ACC1",
+			Timestamp: timestamp.Add(time.Duration(i) * time.Minute), Amount: models.Cents(100), Type: "debit",
+			Status: "completed", BalanceAfter: models.Cents(balance)})
+	}
+
+	tests := []struct {
+		name         string
+		account      models.Account
+		verbose      bool
+		wantCount    int
+		wantSeverity string // Of the anomaly at the lowest balance, -600.00
+	}{
+		{name: "standard limit", account: models.Account{ID: "ACC1"}, wantCount: 1, wantSeverity: "medium"},
+		{name: "verbose", account: models.Account{ID: "ACC1"}, verbose: true, wantCount: 5, wantSeverity: "medium"},
+		{name: "own limit", account: models.Account{ID: "ACC1", OverdraftLimit: models.Cents(-700)}, wantCount: 1, wantSeverity: "high"},
+		{name: "approved overdraft", account: models.Account{ID: "ACC1", ApprovedOverdraft: true}, wantCount: 1, wantSeverity: "low"},
+		{name: "no overdraft type", account: models.Account{ID: "ACC1", AccountType: "savings"}, wantCount: 1, wantSeverity: "high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.VerboseOverdrafts = tt.verbose
+			anomalies := detectOverdrafts(transactions, map[string]models.Account{"ACC1": tt.account}, config)
+			if len(anomalies) != tt.wantCount {
+				t.Fatalf("got %d anomalies, want %d: %+v", len(anomalies), tt.wantCount, anomalies)
+			}
+			for _, anomaly := range anomalies {
+				if anomaly.Type != "account_overdraft" {
+					t.Errorf("anomaly type = %s, want account_overdraft", anomaly.Type)
+				}
+				if anomaly.TransactionID == "TX3" && anomaly.Severity != tt.wantSeverity {
+					t.Errorf("severity at the lowest balance = %s, want %s", anomaly.Severity, tt.wantSeverity)
+				}
+			}
+			if !tt.verbose && anomalies[0].TransactionID != "TX3" {
+				t.Errorf("overdraft reported at %s, want the lowest balance at TX3", anomalies[0].TransactionID)
+			}
+		})
+	}
+}
+
+func TestDetectRepeatOverdrafts(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	var transactions []models.Transaction
+	for i, balance := range []float64{-100, 50, -150, 20, -120} {
+		transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("TX%d", i+1), AccountID: "ACC1",
+			Timestamp: timestamp.Add(time.Duration(i) * time.Minute), Amount: models.Cents(100), Type: "debit",
+			Status: "completed", BalanceAfter: models.Cents(balance)})
+	}
+
+	tests := []struct {
+		name           string
+		overdrafts     int
+		threshold      int
+		wantRepeats    int
+		wantOverdrafts string // Severity of the account_overdraft anomaly
+	}{
+		{name: "three overdrafts", overdrafts: 3, threshold: 2, wantRepeats: 1, wantOverdrafts: "medium"},
+		{name: "at the threshold", overdrafts: 2, threshold: 2, wantOverdrafts: "low"},
+		{name: "disabled", overdrafts: 3, wantOverdrafts: "low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.RepeatOverdraftThreshold = tt.threshold
+			accounts := map[string]models.Account{"ACC1": {ID: "ACC1", OverdraftCount: tt.overdrafts, Balance: models.Cents(-120)}}
+
+			var repeat []models.Anomaly
+			for _, anomaly := range detectOverdrafts(transactions, accounts, config) {
+				switch anomaly.Type {
+				case "repeat_overdraft":
+					repeat = append(repeat, anomaly)
+				case "account_overdraft":
+					if anomaly.Severity != tt.wantOverdrafts {
+						t.Errorf("account_overdraft severity = %s, want %s", anomaly.Severity, tt.wantOverdrafts)
+					}
+				}
+			}
+			if len(repeat) != tt.wantRepeats {
+				t.Fatalf("repeat_overdraft anomalies = %+v, want %d", repeat, tt.wantRepeats)
+			}
+			if tt.wantRepeats > 0 && repeat[0].Severity != "high" {
+				t.Errorf("repeat_overdraft severity = %s, want high", repeat[0].Severity)
+			}
+		})
+	}
+}
+
+func TestDetectRejectionSpike(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	rows := func(count int, kind string, status string) []models.Transaction {
+		transactions := make([]models.Transaction, count)
+		for i := range transactions {
+			transactions[i] = models.Transaction{ID: fmt.Sprintf("%s-%s-%d", kind, status, i), AccountID: "ACC1",
+				Timestamp: timestamp.Add(time.Duration(i) * time.Minute), Amount: models.Cents(10), Type: kind, Status: status}
+		}
+		return transactions
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		invalid      []models.Transaction
+		wantSpike    bool
+	}{
+		{
+			name:         "rejections below the threshold",
+			transactions: append(rows(95, "debit", "completed"), rows(5, "debit", "rejected")...),
+		},
+		{
+			name:         "rejections above the threshold",
+			transactions: append(rows(80, "debit", "completed"), rows(20, "debit", "rejected")...),
+			wantSpike:    true,
+		},
+		{
+			name:         "validation failures count as rejections",
+			transactions: append(rows(80, "debit", "completed"), rows(5, "debit", "rejected")...),
+			invalid:      rows(15, "debit", "invalid"),
+			wantSpike:    true,
+		},
+		{
+			name:         "fee rows do not dilute the rate",
+			transactions: append(append(rows(80, "debit", "completed"), rows(20, "debit", "rejected")...), rows(80, "fee", "completed")...),
+			wantSpike:    true,
+		},
+		{
+			name:      "whole batch invalid",
+			invalid:   rows(20, "debit", "invalid"),
+			wantSpike: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ProcessDate = time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+			config.InvalidTransactions = tt.invalid
+			anomalies := detectRejectionSpike(tt.transactions, config)
+			if got := len(anomalies) > 0; got != tt.wantSpike {
+				t.Errorf("spike = %v, want %v (%v)", got, tt.wantSpike, anomalies)
+			}
+		})
+	}
+}
+
+func TestDetectLargeTransactionsByType(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		kind         string
+		amount       float64
+		wantType     string // Empty when nothing is flagged
+		wantSeverity string
+	}{
+		{name: "large credit", kind: "credit", amount: 20000, wantType: "large_credit", wantSeverity: "high"},
+		{name: "credit below its threshold", kind: "credit", amount: 19999},
+		{name: "large debit", kind: "debit", amount: 5000, wantType: "large_debit", wantSeverity: "medium"},
+		{name: "debit below its threshold", kind: "debit", amount: 4999},
+		{name: "large transfer", kind: "transfer", amount: 10000, wantType: "large_transaction", wantSeverity: "medium"},
+		{name: "transfer below the generic threshold", kind: "transfer", amount: 9999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.LargeCreditThreshold = 20000
+			config.LargeDebitThreshold = 5000
+			transactions := []models.Transaction{{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(tt.amount),
+				Type: tt.kind, Status: "completed"}}
+			if tt.kind == "transfer" {
+				transactions[0].DestinationAccountID = "ACC2"
+			}
+
+			var got []models.Anomaly
+			for _, anomaly := range detectLargeTransactions(transactions, config) {
+				if strings.HasPrefix(anomaly.Type, "large_") {
+					got = append(got, anomaly)
+				}
+			}
+			if tt.wantType == "" {
+				if len(got) != 0 {
+					t.Errorf("flagged %+v, want nothing", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Type != tt.wantType || got[0].Severity != tt.wantSeverity {
+				t.Errorf("flagged %+v, want one %s of %s severity", got, tt.wantType, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestDetectRapidWithdrawals(t *testing.T) {
+	day := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	// debits returns one completed debit at each time of day, numbered from TX1
+	debits := func(times ...time.Duration) []models.Transaction {
+		var transactions []models.Transaction
+		for i, offset := range times {
+			transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("TX%d", i+1), AccountID: "ACC1",
+				Timestamp: day.Add(offset), Amount: models.Cents(100), Type: "debit", Status: "completed"})
+		}
+		return transactions
+	}
+	at := func(hour, minute int) time.Duration {
+		return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		want         []string // Last transaction of each burst reported
+	}{
+		{name: "two bursts", transactions: debits(at(9, 0), at(9, 5), at(9, 10), at(14, 0), at(14, 10), at(14, 15), at(14, 20)),
+			want: []string{"TX3", "TX7"}},
+		{name: "one long burst", transactions: debits(at(9, 0), at(9, 10), at(9, 20), at(9, 30), at(9, 40)), want: []string{"TX5"}},
+		{name: "below the threshold", transactions: debits(at(9, 0), at(9, 5))},
+		{name: "spread beyond the window", transactions: debits(at(9, 0), at(9, 45), at(10, 30), at(11, 15))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, anomaly := range detectRapidWithdrawals(tt.transactions, DefaultConfig()) {
+				got = append(got, anomaly.TransactionID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("rapid withdrawal bursts end at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCoolingOffViolations(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	large := func(id string, offset time.Duration) models.Transaction {
+		return models.Transaction{ID: id, AccountID: "ACC1", Timestamp: start.Add(offset), Amount: models.Cents(12000),
+			Type: "transfer", DestinationAccountID: "ACC2", Status: "completed"}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		period       float64
+		want         []string // Transactions flagged as cooling_off_violation
+	}{
+		{name: "inside the period", transactions: []models.Transaction{large("TX1", 0), large("TX2", 20*time.Minute)}, period: 60, want: []string{"TX2"}},
+		{name: "compliant pair", transactions: []models.Transaction{large("TX1", 0), large("TX2", 90*time.Minute)}, period: 60},
+		{name: "exactly the period apart", transactions: []models.Transaction{large("TX1", 0), large("TX2", time.Hour)}, period: 60},
+		{name: "disabled", transactions: []models.Transaction{large("TX1", 0), large("TX2", time.Minute)}},
+		{name: "small transaction between", period: 60, transactions: []models.Transaction{large("TX1", 0),
+			{ID: "TX2", AccountID: "ACC1", Timestamp: start.Add(10 * time.Minute), Amount: models.Cents(50), Type: "debit", Status: "completed"},
+			large("TX3", 30*time.Minute)}, want: []string{"TX3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.CoolingOffPeriodMins = tt.period
+			var got []string
+			for _, anomaly := range detectLargeTransactions(tt.transactions, config) {
+				if anomaly.Type == "cooling_off_violation" {
+					got = append(got, anomaly.TransactionID)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("cooling-off violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectPositionLimitBreaches(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	row := func(id string, kind string, amount float64) models.Transaction {
+		return models.Transaction{ID: id, AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(amount), Type: kind, Status: "completed"}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		wantBreach   string // Transaction the breach is reported against; "" for none
+		wantLong     bool
+	}{
+		{name: "net long", transactions: []models.Transaction{row("TX1", "credit", 800), row("TX2", "credit", 400), row("TX3", "debit", 100)},
+			wantBreach: "TX3", wantLong: true},
+		{name: "net short", transactions: []models.Transaction{row("TX1", "debit", 300), row("TX2", "debit", 300)}, wantBreach: "TX2"},
+		{name: "within limits", transactions: []models.Transaction{row("TX1", "credit", 900), row("TX2", "debit", 400), row("TX3", "debit", 200)}},
+		{name: "rejected rows ignored", transactions: []models.Transaction{row("TX1", "debit", 300),
+			{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(400), Type: "debit", Status: "rejected"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.NetPositionLongLimit = 1000
+			config.NetPositionShortLimit = 500
+
+			anomalies := detectPositionLimitBreaches(tt.transactions, config)
+			if tt.wantBreach == "" {
+				if len(anomalies) != 0 {
+					t.Errorf("anomalies = %v, want none", anomalies)
+				}
+				return
+			}
+			if len(anomalies) != 1 {
+				t.Fatalf("anomalies = %v, want one breach", anomalies)
+			}
+			anomaly := anomalies[0]
+			if anomaly.Type != "position_limit_breach" || anomaly.Severity != "high" || anomaly.AccountID != "ACC1" || anomaly.TransactionID != tt.wantBreach {
+				t.Errorf("anomaly = %+v, want a high position_limit_breach on ACC1 against %s", anomaly, tt.wantBreach)
+			}
+			if got := strings.HasPrefix(anomaly.Description, "Net long"); got != tt.wantLong {
+				t.Errorf("description %q, want long %v", anomaly.Description, tt.wantLong)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// detector/anomaly_id.go
+package detector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// AnomalyID computes a stable identifier from an anomaly's defining fields (account,
+// type, date, triggering transaction, and key amount), so the same logical anomaly
+// gets the same ID across reruns
+func AnomalyID(anomaly models.Anomaly, amount float64) string {
+	key := fmt.Sprintf("%s|%s|%s|%s|%.2f",
+		anomaly.AccountID,
+		anomaly.Type,
+		anomaly.Timestamp.UTC().Format("2006-01-02"),
+		anomaly.TransactionID,
+		amount,
+	)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:20]
+}
+
+// assignAnomalyIDs sets the ID of each anomaly its rule left without one, using its triggering
+// transaction's amount as the key amount
+func assignAnomalyIDs(anomalies []models.Anomaly, transactions []models.Transaction) {
+	amounts := make(map[string]float64, len(transactions))
+	for _, transaction := range transactions {
+		amounts[transaction.ID] = transaction.Amount.Float()
+	}
+
+	for i := range anomalies {
+		if anomalies[i].ID != "" {
+			continue
+		}
+		anomalies[i].ID = AnomalyID(anomalies[i], amounts[anomalies[i].TransactionID])
+	}
+}
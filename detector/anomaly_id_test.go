@@ -0,0 +1,80 @@
+// detector/anomaly_id_test.go
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestAnomalyID(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	base := models.Anomaly{TransactionID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Type: "large_transaction"}
+	with := func(change func(anomaly *models.Anomaly)) models.Anomaly {
+		anomaly := base
+		change(&anomaly)
+		return anomaly
+	}
+
+	tests := []struct {
+		name     string
+		anomaly  models.Anomaly
+		amount   float64
+		wantSame bool
+	}{
+		{name: "identical", anomaly: base, amount: 12000, wantSame: true},
+		{name: "later the same day", anomaly: with(func(a *models.Anomaly) { a.Timestamp = timestamp.Add(5 * time.Hour) }), amount: 12000, wantSame: true},
+		{name: "different description", anomaly: with(func(a *models.Anomaly) { a.Description = "other" }), amount: 12000, wantSame: true},
+		{name: "different account", anomaly: with(func(a *models.Anomaly) { a.AccountID = "ACC2" }), amount: 12000},
+		{name: "different type", anomaly: with(func(a *models.Anomaly) { a.Type = "overdraft" }), amount: 12000},
+		{name: "different date", anomaly: with(func(a *models.Anomaly) { a.Timestamp = timestamp.AddDate(0, 0, 1) }), amount: 12000},
+		{name: "different transaction", anomaly: with(func(a *models.Anomaly) { a.TransactionID = "TX2" }), amount: 12000},
+		{name: "different amount", anomaly: base, amount: 12000.01},
+	}
+	want := AnomalyID(base, 12000)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnomalyID(tt.anomaly, tt.amount); (got == want) != tt.wantSame {
+				t.Errorf("AnomalyID() = %s against %s, want same %v", got, want, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestAnomalyIDsStableAcrossRuns(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(15000), Type: "credit", Status: "completed"},
+		{ID: "TX2", AccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(20000), Type: "credit", Status: "completed"},
+		{ID: "TX3", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(100), Type: "debit", Status: "rejected",
+			ProcessingMessage: "Insufficient funds"},
+	}
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(15000)}, "ACC2": {ID: "ACC2", Balance: models.Cents(20000)}}
+	run := func() []string {
+		var ids []string
+		for _, anomaly := range DetectAnomaliesWithConfig(transactions, accounts, DefaultConfig()) {
+			ids = append(ids, anomaly.ID)
+		}
+		return ids
+	}
+
+	first, second := run(), run()
+	if len(first) < 2 {
+		t.Fatalf("%d anomalies, want at least two", len(first))
+	}
+	seen := make(map[string]bool)
+	for i, id := range first {
+		if id == "" {
+			t.Errorf("anomaly %d has no ID", i)
+		}
+		if seen[id] {
+			t.Errorf("ID %s is shared by distinct anomalies", id)
+		}
+		seen[id] = true
+		if i >= len(second) || second[i] != id {
+			t.Errorf("IDs differ across runs: %v and %v", first, second)
+			break
+		}
+	}
+}
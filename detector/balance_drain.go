@@ -0,0 +1,63 @@
+// detector/balance_drain.go
+package detector
+
+import (
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// DetectBalanceDrains flags accounts whose completed outbound transfers over the day add up to at
+// least the configured fraction of their opening balance. The anomaly is raised on the transfer
+// that crossed the threshold; severity rises as the drained fraction approaches and exceeds the
+// whole balance. Accounts that opened at or below zero are skipped.
+func DetectBalanceDrains(
+	transactions []models.Transaction,
+	openingAccounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.BalanceDrainFraction <= 0 {
+		return anomalies
+	}
+
+	transferred := make(map[string]models.Money)
+	flagged := make(map[string]bool)
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" || transaction.Type != "transfer" || flagged[transaction.AccountID] {
+			continue
+		}
+		opening := openingAccounts[transaction.AccountID].Balance
+		if opening <= 0 {
+			continue
+		}
+
+		transferred[transaction.AccountID] += transaction.Amount
+		fraction := transferred[transaction.AccountID].Float() / opening.Float()
+		if fraction < config.BalanceDrainFraction {
+			continue
+		}
+
+		severity := "low"
+		if fraction >= 1 {
+			severity = "high"
+		} else if fraction >= (config.BalanceDrainFraction+1)/2 {
+			severity = "medium"
+		}
+
+		flagged[transaction.AccountID] = true
+		anomaly := models.Anomaly{
+			TransactionID: transaction.ID,
+			AccountID:     transaction.AccountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "balance_drain",
+			Description: fmt.Sprintf("Transfers out of $%s drained %.0f%% of the opening balance of $%s",
+				transferred[transaction.AccountID], fraction*100, opening),
+			Severity: severity,
+		}
+		anomaly.ID = AnomalyID(anomaly, transferred[transaction.AccountID].Float())
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return anomalies
+}
@@ -0,0 +1,75 @@
+// detector/carry_forward.go
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// DetectCarryForwardMismatches flags accounts whose opening balance reconstructed in today's summary
+// differs from the closing balance in the prior day's summary by more than the configured tolerance.
+// Accounts missing from the prior summary are skipped.
+func DetectCarryForwardMismatches(
+	summaries []models.AccountSummary,
+	priorSummaries []models.AccountSummary,
+	processDate time.Time,
+	config Config,
+) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+
+	priorClosing := make(map[string]models.Money, len(priorSummaries))
+	for _, summary := range priorSummaries {
+		priorClosing[summary.AccountID] = summary.ClosingBalance
+	}
+
+	for _, summary := range summaries {
+		closing, exists := priorClosing[summary.AccountID]
+		if !exists {
+			continue
+		}
+		difference := summary.OpeningBalance - closing
+		if difference.Abs() <= models.Cents(config.CarryForwardTolerance) {
+			continue
+		}
+
+		anomaly := models.Anomaly{
+			AccountID: summary.AccountID,
+			Timestamp: processDate,
+			Type:      "carry_forward_mismatch",
+			Description: fmt.Sprintf("Opening balance $%s does not match prior closing balance $%s (difference $%s)",
+				summary.OpeningBalance, closing, difference),
+			Severity: "high",
+		}
+		anomaly.ID = AnomalyID(anomaly, difference.Float())
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return anomalies
+}
+
+// detectCarryForwardMismatches checks the opening balance of every account in the batch against
+// the prior summary in config
+func detectCarryForwardMismatches(
+	_ []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	if len(config.PriorSummaries) == 0 {
+		return []models.Anomaly{}
+	}
+
+	accountIDs := make([]string, 0, len(accounts))
+	for accountID := range accounts {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	summaries := make([]models.AccountSummary, len(accountIDs))
+	for i, accountID := range accountIDs {
+		summaries[i] = models.AccountSummary{AccountID: accountID, OpeningBalance: config.OpeningAccounts[accountID].Balance}
+	}
+	return DetectCarryForwardMismatches(summaries, config.PriorSummaries, config.ProcessDate, config)
+}
@@ -0,0 +1,67 @@
+// detector/events.go
+package detector
+
+import (
+	"DailyTransactionBatchProcessing/models"
+)
+
+// DetectEvents produces informational events for the day's processed transactions.
+// openingAccounts are the accounts as loaded before processing, and priorSummaries
+// are the previous day's account summaries, if available.
+func DetectEvents(
+	transactions []models.Transaction,
+	openingAccounts map[string]models.Account,
+	priorSummaries []models.AccountSummary,
+) []models.Event {
+	events := []models.Event{}
+	events = append(events, detectFirstActivity(transactions, openingAccounts, priorSummaries)...)
+	return events
+}
+
+// detectFirstActivity emits one "first_activity" event for each account transacting for the first time
+func detectFirstActivity(
+	transactions []models.Transaction,
+	openingAccounts map[string]models.Account,
+	priorSummaries []models.AccountSummary,
+) []models.Event {
+	// Accounts with activity in a prior summary are not new
+	previouslyActive := make(map[string]bool)
+	for _, summary := range priorSummaries {
+		if summary.TransactionCount > 0 || summary.TransfersIn > 0 {
+			previouslyActive[summary.AccountID] = true
+		}
+	}
+
+	events := []models.Event{}
+	reported := make(map[string]bool)
+	check := func(accountID string, transaction models.Transaction) {
+		if accountID == "" || reported[accountID] || previouslyActive[accountID] {
+			return
+		}
+		if account, exists := openingAccounts[accountID]; exists && !account.LastTransactionTime.IsZero() {
+			return
+		}
+
+		reported[accountID] = true
+		events = append(events, models.Event{
+			TransactionID: transaction.ID,
+			AccountID:     accountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "first_activity",
+			Description:   "First transaction on account",
+		})
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+
+		check(transaction.AccountID, transaction)
+		if transaction.Type == "transfer" || transaction.ReversedType == "transfer" {
+			check(transaction.DestinationAccountID, transaction)
+		}
+	}
+
+	return events
+}
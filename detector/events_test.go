@@ -0,0 +1,41 @@
+// detector/events_test.go
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectFirstActivity(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "NEW1", Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "completed"},
+		{ID: "TX2", AccountID: "NEW1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(5), Type: "debit", Status: "completed"},
+		{ID: "TX3", AccountID: "OLD1", DestinationAccountID: "NEW2", Timestamp: timestamp, Amount: models.Cents(10), Type: "transfer", Status: "completed"},
+		{ID: "TX4", AccountID: "SUM1", Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "completed"},
+		{ID: "TX5", AccountID: "REJ1", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "rejected"},
+	}
+	opening := map[string]models.Account{
+		"NEW1": {ID: "NEW1"},
+		"NEW2": {ID: "NEW2"},
+		"OLD1": {ID: "OLD1", LastTransactionTime: timestamp.AddDate(0, 0, -3)},
+		"SUM1": {ID: "SUM1"},
+		"REJ1": {ID: "REJ1"},
+	}
+	priorSummaries := []models.AccountSummary{{AccountID: "SUM1", TransactionCount: 2}}
+
+	events := DetectEvents(transactions, opening, priorSummaries)
+	var got []string
+	for _, event := range events {
+		if event.Type != "first_activity" {
+			t.Errorf("event type = %q, want first_activity", event.Type)
+		}
+		got = append(got, event.AccountID+":"+event.TransactionID)
+	}
+	if want := []string{"NEW1:TX1", "NEW2:TX3"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("first activity = %v, want %v", got, want)
+	}
+}
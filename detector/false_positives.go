@@ -0,0 +1,95 @@
+// detector/false_positives.go
+package detector
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// FalsePositive records an alert an analyst confirmed as a false positive
+type FalsePositive struct {
+	AccountID  string
+	Type       string
+	MarkedDate time.Time
+}
+
+// LoadFalsePositives loads a false-positive registry CSV (account_id, anomaly_type, marked_date)
+func LoadFalsePositives(filePath string) ([]FalsePositive, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening false positive registry: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	registry := make([]FalsePositive, 0, len(records))
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 3 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		markedDate, err := time.Parse("2006-01-02", record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid marked date at line %d: %w", i+1, err)
+		}
+
+		registry = append(registry, FalsePositive{
+			AccountID:  record[0],
+			Type:       record[1],
+			MarkedDate: markedDate,
+		})
+	}
+
+	return registry, nil
+}
+
+// SuppressFalsePositives removes anomalies matching a registered false positive for the same
+// account and type while processDate falls within windowDays of the date it was marked.
+// It returns the anomalies to emit and the ones suppressed.
+func SuppressFalsePositives(
+	anomalies []models.Anomaly,
+	registry []FalsePositive,
+	processDate time.Time,
+	windowDays int,
+) ([]models.Anomaly, []models.Anomaly) {
+	if len(registry) == 0 || windowDays <= 0 {
+		return anomalies, nil
+	}
+
+	year, month, day := processDate.Date()
+	processDay := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	// Collect the account and type pairs with an unexpired false positive
+	active := make(map[string]bool)
+	for _, falsePositive := range registry {
+		expires := falsePositive.MarkedDate.AddDate(0, 0, windowDays)
+		if !processDay.Before(falsePositive.MarkedDate) && processDay.Before(expires) {
+			active[falsePositive.AccountID+"|"+falsePositive.Type] = true
+		}
+	}
+
+	kept := make([]models.Anomaly, 0, len(anomalies))
+	suppressed := []models.Anomaly{}
+	for _, anomaly := range anomalies {
+		if active[anomaly.AccountID+"|"+anomaly.Type] {
+			suppressed = append(suppressed, anomaly)
+			continue
+		}
+		kept = append(kept, anomaly)
+	}
+
+	return kept, suppressed
+}
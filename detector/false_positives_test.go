@@ -0,0 +1,54 @@
+// detector/false_positives_test.go
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestSuppressFalsePositives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "false_positives.csv")
+	content := "account_id,anomaly_type,marked_date\nACC1,large_transaction,2025-04-01\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := LoadFalsePositives(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anomalies := []models.Anomaly{
+		{TransactionID: "TX1", AccountID: "ACC1", Type: "large_transaction"},
+		{TransactionID: "TX2", AccountID: "ACC1", Type: "overdraft"},
+		{TransactionID: "TX3", AccountID: "ACC2", Type: "large_transaction"},
+	}
+	tests := []struct {
+		name           string
+		processDate    time.Time
+		wantSuppressed bool
+	}{
+		{name: "day marked", processDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), wantSuppressed: true},
+		{name: "within the window", processDate: time.Date(2025, 4, 10, 8, 0, 0, 0, time.UTC), wantSuppressed: true},
+		{name: "last day of the window", processDate: time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), wantSuppressed: true},
+		{name: "expired", processDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "before it was marked", processDate: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, suppressed := SuppressFalsePositives(anomalies, registry, tt.processDate, 30)
+			if tt.wantSuppressed {
+				if len(suppressed) != 1 || suppressed[0].TransactionID != "TX1" || len(kept) != 2 {
+					t.Errorf("kept %v and suppressed %v, want only TX1 suppressed", kept, suppressed)
+				}
+				return
+			}
+			if len(suppressed) != 0 || len(kept) != len(anomalies) {
+				t.Errorf("kept %v and suppressed %v, want every anomaly re-emitted", kept, suppressed)
+			}
+		})
+	}
+}
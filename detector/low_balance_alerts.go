@@ -0,0 +1,95 @@
+// detector/low_balance_alerts.go
+package detector
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadBalanceThresholds loads customer-configured low-balance alert thresholds from a CSV file (account_id, threshold)
+func LoadBalanceThresholds(filePath string, normalizer models.IDNormalizer) (map[string]models.Money, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening balance thresholds file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	thresholds := make(map[string]models.Money)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		threshold, err := models.ParseMoney(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold at line %d: %w", i+1, err)
+		}
+		thresholds[normalizer.Normalize(record[0])] = threshold
+	}
+
+	return thresholds, nil
+}
+
+// DetectCustomerLowBalance emits a "customer_low_balance_alert" event for each account that closes
+// below its own configured threshold. Accounts without a threshold are skipped.
+func DetectCustomerLowBalance(
+	transactions []models.Transaction,
+	closingAccounts map[string]models.Account,
+	thresholds map[string]models.Money,
+) []models.Event {
+	events := []models.Event{}
+	if len(thresholds) == 0 {
+		return events
+	}
+
+	// Find the last completed transaction touching each account
+	lastTransaction := make(map[string]models.Transaction)
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		lastTransaction[transaction.AccountID] = transaction
+		if transaction.Type == "transfer" || transaction.ReversedType == "transfer" {
+			lastTransaction[transaction.DestinationAccountID] = transaction
+		}
+	}
+
+	accountIDs := make([]string, 0, len(thresholds))
+	for accountID := range thresholds {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	for _, accountID := range accountIDs {
+		account, exists := closingAccounts[accountID]
+		if !exists || account.Balance >= thresholds[accountID] {
+			continue
+		}
+
+		transaction := lastTransaction[accountID]
+		events = append(events, models.Event{
+			TransactionID: transaction.ID,
+			AccountID:     accountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "customer_low_balance_alert",
+			Description: fmt.Sprintf("Closing balance $%s is below the customer alert threshold of $%s",
+				account.Balance, thresholds[accountID]),
+		})
+	}
+
+	return events
+}
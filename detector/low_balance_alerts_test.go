@@ -0,0 +1,64 @@
+// detector/low_balance_alerts_test.go
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectCustomerLowBalance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "balance_thresholds.csv")
+	if err := os.WriteFile(path, []byte("account_id,threshold\nacc1,100.00\nACC2,20.00\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	thresholds, err := LoadBalanceThresholds(path, models.IDNormalizer{Case: "upper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(30), Type: "debit", Status: "completed"},
+		{ID: "TX2", AccountID: "ACC3", DestinationAccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(5),
+			Type: "transfer", Status: "completed"},
+		{ID: "TX3", AccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "completed"},
+	}
+	tests := []struct {
+		name    string
+		closing map[string]models.Account
+		want    []string // Accounts alerted, each with its last transaction
+	}{
+		{name: "below its own threshold", want: []string{"ACC1:TX2"}, closing: map[string]models.Account{
+			"ACC1": {ID: "ACC1", Balance: models.Cents(75)},
+			"ACC2": {ID: "ACC2", Balance: models.Cents(50)},
+			"ACC3": {ID: "ACC3", Balance: models.Cents(1)},
+		}},
+		{name: "at the threshold", closing: map[string]models.Account{
+			"ACC1": {ID: "ACC1", Balance: models.Cents(100)},
+			"ACC2": {ID: "ACC2", Balance: models.Cents(20)},
+		}},
+		{name: "both below", want: []string{"ACC1:TX2", "ACC2:TX3"}, closing: map[string]models.Account{
+			"ACC1": {ID: "ACC1", Balance: models.Cents(-5)},
+			"ACC2": {ID: "ACC2", Balance: models.Cents(19.99)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, event := range DetectCustomerLowBalance(transactions, tt.closing, thresholds) {
+				if event.Type != "customer_low_balance_alert" {
+					t.Errorf("event type = %q, want customer_low_balance_alert", event.Type)
+				}
+				got = append(got, event.AccountID+":"+event.TransactionID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alerts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// detector/near_limit.go
+package detector
+
+import (
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// detectNearLimitBreaches flags completed outflows that left an account within the configured
+// margin of its overdraft limit without breaching it. Balances after each transaction are
+// replayed forward from opening balances derived from the closing balances.
+func detectNearLimitBreaches(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.NearLimitMargin <= 0 {
+		return anomalies
+	}
+
+	// Derive opening balances by backing the day's completed legs out of the closing balances
+	balances := make(map[string]models.Money, len(accounts))
+	for accountID, account := range accounts {
+		balances[accountID] = account.Balance
+	}
+	applyLegs := func(transaction models.Transaction, sign models.Money) {
+		if transaction.Type == "reversal" {
+			transaction, sign = transaction.Reversed(), -sign
+		}
+		switch transaction.Type {
+		case "credit":
+			balances[transaction.AccountID] += sign * transaction.Amount
+		case "debit", "fee":
+			balances[transaction.AccountID] -= sign * transaction.Amount
+		case "transfer":
+			balances[transaction.AccountID] -= sign * transaction.Amount
+			balances[transaction.DestinationAccountID] += sign * transaction.CreditedAmount()
+		}
+	}
+	for _, transaction := range transactions {
+		if transaction.Status == "completed" {
+			applyLegs(transaction, -1)
+		}
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		applyLegs(transaction, 1)
+		if transaction.Type != "debit" && transaction.Type != "transfer" && transaction.Type != "fee" {
+			continue
+		}
+
+		limit := config.OverdraftLimitFor(accounts[transaction.AccountID])
+		balance := balances[transaction.AccountID]
+		if balance >= 0 || balance < limit || balance >= limit+models.Cents(config.NearLimitMargin) {
+			continue
+		}
+
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: transaction.ID,
+			AccountID:     transaction.AccountID,
+			Timestamp:     transaction.Timestamp,
+			Type:          "near_limit",
+			Description: fmt.Sprintf("Balance $%s is within $%s of the overdraft limit of $%s",
+				balance, balance-limit, -limit),
+			Severity: "medium",
+		})
+	}
+
+	return anomalies
+}
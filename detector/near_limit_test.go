@@ -0,0 +1,52 @@
+// detector/near_limit_test.go
+package detector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectNearLimitBreaches(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	debit := func(id string, amount float64, minutes int) models.Transaction {
+		return models.Transaction{ID: id, AccountID: "ACC1", Timestamp: timestamp.Add(time.Duration(minutes) * time.Minute),
+			Amount: models.Cents(amount), Type: "debit", Status: "completed"}
+	}
+
+	tests := []struct {
+		name         string
+		account      models.Account // Closing state
+		transactions []models.Transaction
+		want         []string
+	}{
+		{name: "lands at -980", account: models.Account{ID: "ACC1", Balance: models.Cents(-980)},
+			transactions: []models.Transaction{debit("TX1", 1000, 0)}, want: []string{"TX1"}},
+		{name: "lands at -940", account: models.Account{ID: "ACC1", Balance: models.Cents(-940)},
+			transactions: []models.Transaction{debit("TX1", 960, 0)}},
+		{name: "recovers later in the day", account: models.Account{ID: "ACC1", Balance: models.Cents(20)},
+			transactions: []models.Transaction{debit("TX1", 1000, 0),
+				{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(1000), Type: "credit", Status: "completed"}},
+			want: []string{"TX1"}},
+		{name: "own overdraft limit", account: models.Account{ID: "ACC1", Balance: models.Cents(-180), OverdraftLimit: models.Cents(-200)},
+			transactions: []models.Transaction{debit("TX1", 200, 0)}, want: []string{"TX1"}},
+		{name: "approved overdraft", account: models.Account{ID: "ACC1", Balance: models.Cents(-980), ApprovedOverdraft: true},
+			transactions: []models.Transaction{debit("TX1", 1000, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, anomaly := range detectNearLimitBreaches(tt.transactions, map[string]models.Account{"ACC1": tt.account}, DefaultConfig()) {
+				if anomaly.Type != "near_limit" || anomaly.Severity != "medium" {
+					t.Errorf("anomaly = %+v, want a medium near_limit", anomaly)
+				}
+				got = append(got, anomaly.TransactionID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("near-limit flags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
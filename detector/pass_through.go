@@ -0,0 +1,70 @@
+// detector/pass_through.go
+package detector
+
+import (
+	"fmt"
+	"math"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// detectPassThroughs flags a completed credit followed within the configured window by a completed
+// debit or transfer out of the same account for nearly the same amount, a cash-out pattern
+func detectPassThroughs(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.PassThroughWindowMins <= 0 {
+		return anomalies
+	}
+
+	// Group completed transactions by account, remembering the order accounts were first seen
+	byAccount := make(map[string][]models.Transaction)
+	order := []string{}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		if _, seen := byAccount[transaction.AccountID]; !seen {
+			order = append(order, transaction.AccountID)
+		}
+		byAccount[transaction.AccountID] = append(byAccount[transaction.AccountID], transaction)
+	}
+
+	for _, accountID := range order {
+		accountTransactions := byAccount[accountID]
+		matched := make(map[int]bool)
+		for i, credit := range accountTransactions {
+			if credit.Type != "credit" {
+				continue
+			}
+
+			// Pair the credit with the first unmatched outflow of a similar amount inside the window
+			for j := i + 1; j < len(accountTransactions); j++ {
+				outflow := accountTransactions[j]
+				elapsed := outflow.Timestamp.Sub(credit.Timestamp).Minutes()
+				if elapsed > config.PassThroughWindowMins {
+					break
+				}
+				if matched[j] || elapsed < 0 || (outflow.Type != "debit" && outflow.Type != "transfer") {
+					continue
+				}
+				if math.Abs((outflow.Amount - credit.Amount).Float()) > credit.Amount.Float()*config.PassThroughTolerance {
+					continue
+				}
+
+				matched[j] = true
+				anomalies = append(anomalies, models.Anomaly{
+					TransactionID: outflow.ID,
+					AccountID:     accountID,
+					Timestamp:     outflow.Timestamp,
+					Type:          "pass_through",
+					Description: fmt.Sprintf("Credit %s of $%s followed by %s %s of $%s within %d minutes",
+						credit.ID, credit.Amount, outflow.Type, outflow.ID, outflow.Amount, int(elapsed)),
+					Severity: "high",
+				})
+				break
+			}
+		}
+	}
+
+	return anomalies
+}
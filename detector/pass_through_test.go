@@ -0,0 +1,52 @@
+// detector/pass_through_test.go
+package detector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectPassThroughs(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	row := func(id string, kind string, amount float64, minutes int) models.Transaction {
+		transaction := models.Transaction{ID: id, AccountID: "ACC1", Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+			Amount: models.Cents(amount), Type: kind, Status: "completed"}
+		if kind == "transfer" {
+			transaction.DestinationAccountID = "ACC9"
+		}
+		return transaction
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		want         []string // Outflows flagged as pass_through
+	}{
+		{name: "deposit then withdrawal", transactions: []models.Transaction{row("TX1", "credit", 5000, 0), row("TX2", "debit", 4900, 20)},
+			want: []string{"TX2"}},
+		{name: "deposit then transfer out", transactions: []models.Transaction{row("TX1", "credit", 5000, 0), row("TX2", "transfer", 5000, 5)},
+			want: []string{"TX2"}},
+		{name: "amounts differ", transactions: []models.Transaction{row("TX1", "credit", 5000, 0), row("TX2", "debit", 3000, 20)}},
+		{name: "outside the window", transactions: []models.Transaction{row("TX1", "credit", 5000, 0), row("TX2", "debit", 5000, 61)}},
+		{name: "withdrawal before the deposit", transactions: []models.Transaction{row("TX1", "debit", 5000, 0), row("TX2", "credit", 5000, 20)}},
+		{name: "one outflow per credit", transactions: []models.Transaction{row("TX1", "credit", 5000, 0), row("TX2", "debit", 5000, 10),
+			row("TX3", "debit", 5000, 20)}, want: []string{"TX2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, anomaly := range detectPassThroughs(tt.transactions, DefaultConfig()) {
+				if anomaly.Type != "pass_through" || anomaly.Severity != "high" {
+					t.Errorf("anomaly = %+v, want a high pass_through", anomaly)
+				}
+				got = append(got, anomaly.TransactionID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pass-throughs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// detector/rules.go
+package detector
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// AnomalyRule is a detection rule evaluated over a batch of processed transactions. The built-in
+// checks are rules too; custom rules registered with RegisterRule run after them.
+type AnomalyRule interface {
+	Name() string
+	Evaluate(transactions []models.Transaction, accounts map[string]models.Account, config Config) []models.Anomaly
+}
+
+// PluginSymbol is the exported symbol a detector plugin must define. It must be a
+// func() []detector.AnomalyRule returning the rules to register.
+const PluginSymbol = "AnomalyRules"
+
+var (
+	registryMu sync.Mutex
+	registry   []AnomalyRule
+)
+
+// RegisterRule adds a custom rule evaluated by every subsequent detection run. A rule replaces any
+// registered earlier under the same name, so loading a plugin twice doesn't duplicate its anomalies.
+func RegisterRule(rule AnomalyRule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, registered := range registry {
+		if registered.Name() == rule.Name() {
+			registry[i] = rule
+			return
+		}
+	}
+	registry = append(registry, rule)
+}
+
+// RegisteredRules returns the custom rules registered so far
+func RegisteredRules() []AnomalyRule {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	rules := make([]AnomalyRule, len(registry))
+	copy(rules, registry)
+	return rules
+}
+
+// ruleFunc adapts a detection function to AnomalyRule
+type ruleFunc struct {
+	name     string
+	evaluate func(transactions []models.Transaction, accounts map[string]models.Account, config Config) []models.Anomaly
+}
+
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+func (r ruleFunc) Evaluate(transactions []models.Transaction, accounts map[string]models.Account, config Config) []models.Anomaly {
+	return r.evaluate(transactions, accounts, config)
+}
+
+// batchRule adapts a detection function that needs only the transactions to AnomalyRule
+func batchRule(name string, detect func(transactions []models.Transaction, config Config) []models.Anomaly) AnomalyRule {
+	return ruleFunc{name: name, evaluate: func(transactions []models.Transaction, _ map[string]models.Account, config Config) []models.Anomaly {
+		return detect(transactions, config)
+	}}
+}
+
+// builtinRules returns the standard detection rules in the order their results are reported
+func builtinRules() []AnomalyRule {
+	return []AnomalyRule{
+		// Large amounts and cooling-off violations
+		batchRule("large_transactions", detectLargeTransactions),
+		// Accounts that went into overdraft
+		ruleFunc{name: "account_overdraft", evaluate: detectOverdrafts},
+		// Restricted accounts that were overdrawn
+		ruleFunc{name: "unexpected_overdraft", evaluate: detectUnexpectedOverdrafts},
+		// Outflows that narrowly avoided the overdraft limit
+		ruleFunc{name: "near_limit", evaluate: detectNearLimitBreaches},
+		// End-of-day net position limit breaches
+		batchRule("position_limit_breach", detectPositionLimitBreaches),
+		// Bursts of withdrawals
+		batchRule("rapid_withdrawals", detectRapidWithdrawals),
+		// Repeated debits kept just under the large transaction threshold
+		batchRule("structuring", detectStructuring),
+		// Deposits cashed straight back out
+		batchRule("pass_through", detectPassThroughs),
+		// Bursts of sequentially numbered transaction IDs
+		batchRule("sequential_id_burst", detectSequentialIDBursts),
+		// Many accounts sharing one exact timestamp
+		batchRule("synchronized_timestamps", detectSynchronizedTimestamps),
+		// A batch-wide surge in rejections
+		batchRule("rejection_spike", detectRejectionSpike),
+		// Opening balances that differ from the prior day's closing balances
+		ruleFunc{name: "carry_forward_mismatch", evaluate: detectCarryForwardMismatches},
+		// Transfers draining most of an account's opening balance
+		batchRule("balance_drain", func(transactions []models.Transaction, config Config) []models.Anomaly {
+			return DetectBalanceDrains(transactions, config.OpeningAccounts, config)
+		}),
+	}
+}
+
+// LoadPlugin opens a compiled Go plugin and returns the rules it exports. It does not register
+// them; pass each to RegisterRule to have detection runs evaluate it.
+func LoadPlugin(path string) ([]AnomalyRule, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening plugin %s: %w", path, err)
+	}
+
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %w", path, PluginSymbol, err)
+	}
+
+	factory, ok := symbol.(func() []AnomalyRule)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s symbol %s has type %T, want func() []detector.AnomalyRule", path, PluginSymbol, symbol)
+	}
+
+	return factory(), nil
+}
@@ -0,0 +1,183 @@
+// detector/rules_test.go
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDayContextRules(t *testing.T) {
+	processDate := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	opening := map[string]models.Account{
+		"ACC1": {ID: "ACC1", Balance: models.Cents(1000)},
+		"ACC2": {ID: "ACC2", Balance: models.Cents(500)},
+	}
+	closing := map[string]models.Account{
+		"ACC1": {ID: "ACC1", Balance: models.Cents(50)},
+		"ACC2": {ID: "ACC2", Balance: models.Cents(1450)},
+	}
+	transactions := []models.Transaction{{
+		ID: "TX1", AccountID: "ACC1", DestinationAccountID: "ACC2", Timestamp: processDate.Add(9 * time.Hour),
+		Amount: models.Cents(950), Type: "transfer", Status: "completed",
+	}}
+	prior := []models.AccountSummary{
+		{AccountID: "ACC1", ClosingBalance: models.Cents(1000)},
+		{AccountID: "ACC2", ClosingBalance: models.Cents(400)},
+	}
+
+	tests := []struct {
+		name      string
+		opening   map[string]models.Account
+		prior     []models.AccountSummary
+		wantTypes map[string]int
+	}{
+		{name: "no day context", wantTypes: map[string]int{}},
+		{name: "opening accounts only", opening: opening, wantTypes: map[string]int{"balance_drain": 1}},
+		{name: "prior summary", opening: opening, prior: prior, wantTypes: map[string]int{"balance_drain": 1, "carry_forward_mismatch": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ProcessDate = processDate
+			config.OpeningAccounts = tt.opening
+			config.PriorSummaries = tt.prior
+
+			counts := make(map[string]int)
+			for _, anomaly := range DetectAnomaliesWithConfig(transactions, closing, config) {
+				switch anomaly.Type {
+				case "balance_drain":
+					if want := AnomalyID(anomaly, 950); anomaly.ID != want {
+						t.Errorf("balance drain ID = %s, want %s", anomaly.ID, want)
+					}
+				case "carry_forward_mismatch":
+					if anomaly.AccountID != "ACC2" {
+						t.Errorf("carry-forward mismatch on %s, want ACC2", anomaly.AccountID)
+					}
+				default:
+					continue
+				}
+				counts[anomaly.Type]++
+			}
+			for anomalyType, want := range tt.wantTypes {
+				if counts[anomalyType] != want {
+					t.Errorf("%d %s anomalies, want %d", counts[anomalyType], anomalyType, want)
+				}
+			}
+			if len(counts) != len(tt.wantTypes) {
+				t.Errorf("anomaly types %v, want %v", counts, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestLoadPluginErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPlugin := filepath.Join(dir, "not_a_plugin.so")
+	if err := os.WriteFile(notPlugin, []byte("not an ELF file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing", path: filepath.Join(dir, "missing.so"), wantErr: "error opening plugin"},
+		{name: "not a plugin", path: notPlugin, wantErr: "error opening plugin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(RegisteredRules())
+			rules, err := LoadPlugin(tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPlugin() = %v, %v, want an error containing %q", rules, err, tt.wantErr)
+			}
+			if len(RegisteredRules()) != before {
+				t.Error("a failed load registered rules")
+			}
+		})
+	}
+}
+
+// isolateRegistry empties the rule registry for a test and restores it afterwards
+func isolateRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = nil
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterRule(t *testing.T) {
+	isolateRegistry(t)
+
+	flag := func(severity string) ruleFunc {
+		return ruleFunc{name: "test_rule", evaluate: func([]models.Transaction, map[string]models.Account, Config) []models.Anomaly {
+			return []models.Anomaly{{TransactionID: "TX1", Type: "test_rule", Severity: severity}}
+		}}
+	}
+	RegisterRule(flag("low"))
+	RegisterRule(flag("high"))
+
+	rules := RegisteredRules()
+	if len(rules) != 1 {
+		t.Fatalf("RegisteredRules() has %d rules, want the re-registered one once", len(rules))
+	}
+	if got := rules[0].Evaluate(nil, nil, DefaultConfig()); len(got) != 1 || got[0].Severity != "high" {
+		t.Errorf("registered rule reports %v, want the later registration's high severity", got)
+	}
+}
+
+// weekendRule flags every completed transaction made on a weekend
+type weekendRule struct{}
+
+func (weekendRule) Name() string {
+	return "weekend_activity"
+}
+
+func (weekendRule) Evaluate(transactions []models.Transaction, _ map[string]models.Account, _ Config) []models.Anomaly {
+	var anomalies []models.Anomaly
+	for _, transaction := range transactions {
+		if day := transaction.Timestamp.Weekday(); transaction.Status == "completed" && (day == time.Saturday || day == time.Sunday) {
+			anomalies = append(anomalies, models.Anomaly{TransactionID: transaction.ID, AccountID: transaction.AccountID,
+				Timestamp: transaction.Timestamp, Type: "weekend_activity", Severity: "low"})
+		}
+	}
+	return anomalies
+}
+
+func TestCustomRuleAnomaliesReported(t *testing.T) {
+	isolateRegistry(t)
+	saturday := time.Date(2025, 4, 19, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: saturday, Amount: models.Cents(20), Type: "debit", Status: "completed"},
+		{ID: "TX2", AccountID: "ACC1", DestinationAccountID: "ACC2", Timestamp: saturday.Add(-24 * time.Hour),
+			Amount: models.Cents(15000), Type: "transfer", Status: "completed"},
+	}
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(1000)}, "ACC2": {ID: "ACC2"}}
+
+	counts := func() map[string]int {
+		counts := make(map[string]int)
+		for _, anomaly := range DetectAnomaliesWithConfig(transactions, accounts, DefaultConfig()) {
+			counts[anomaly.Type]++
+		}
+		return counts
+	}
+	if before := counts(); before["weekend_activity"] != 0 || before["large_transaction"] != 1 {
+		t.Fatalf("anomalies before registering = %v, want only the built-in large_transaction", before)
+	}
+	RegisterRule(weekendRule{})
+	if after := counts(); after["weekend_activity"] != 1 || after["large_transaction"] != 1 {
+		t.Errorf("anomalies after registering = %v, want one weekend_activity alongside large_transaction", after)
+	}
+}
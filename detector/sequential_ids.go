@@ -0,0 +1,92 @@
+// detector/sequential_ids.go
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// numericIDSuffix returns the number formed by the trailing digits of a transaction ID
+func numericIDSuffix(id string) (int64, bool) {
+	start := len(id)
+	for start > 0 && id[start-1] >= '0' && id[start-1] <= '9' {
+		start--
+	}
+	if start == len(id) {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(id[start:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// detectSequentialIDBursts flags accounts whose transactions in the batch carry IDs forming a
+// tight numeric sequence, a sign of automated generation. IDs without a numeric part are skipped.
+func detectSequentialIDBursts(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.SequentialIDMinRun <= 1 {
+		return anomalies
+	}
+
+	type numbered struct {
+		number      int64
+		transaction models.Transaction
+	}
+
+	// Group numeric IDs by account, remembering the order accounts were first seen
+	byAccount := make(map[string][]numbered)
+	order := []string{}
+	for _, transaction := range transactions {
+		number, ok := numericIDSuffix(transaction.ID)
+		if !ok {
+			continue
+		}
+		if _, seen := byAccount[transaction.AccountID]; !seen {
+			order = append(order, transaction.AccountID)
+		}
+		byAccount[transaction.AccountID] = append(byAccount[transaction.AccountID], numbered{number, transaction})
+	}
+
+	for _, accountID := range order {
+		ids := byAccount[accountID]
+		if len(ids) < config.SequentialIDMinRun {
+			continue
+		}
+		sort.SliceStable(ids, func(i, j int) bool { return ids[i].number < ids[j].number })
+
+		// Find the longest run of IDs whose consecutive gaps are within the allowed step
+		bestStart, bestLen := 0, 1
+		start := 0
+		for i := 1; i < len(ids); i++ {
+			gap := ids[i].number - ids[i-1].number
+			if gap <= 0 || gap > config.SequentialIDMaxGap {
+				start = i
+				continue
+			}
+			if i-start+1 > bestLen {
+				bestStart, bestLen = start, i-start+1
+			}
+		}
+		if bestLen < config.SequentialIDMinRun {
+			continue
+		}
+
+		first := ids[bestStart].transaction
+		last := ids[bestStart+bestLen-1].transaction
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: last.ID,
+			AccountID:     accountID,
+			Timestamp:     last.Timestamp,
+			Type:          "sequential_id_burst",
+			Description:   fmt.Sprintf("%d transactions with sequential IDs from %s to %s", bestLen, first.ID, last.ID),
+			Severity:      "medium",
+		})
+	}
+
+	return anomalies
+}
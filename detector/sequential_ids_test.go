@@ -0,0 +1,54 @@
+// detector/sequential_ids_test.go
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectSequentialIDBursts(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	batch := func(accountID string, ids ...string) []models.Transaction {
+		transactions := make([]models.Transaction, len(ids))
+		for i, id := range ids {
+			transactions[i] = models.Transaction{ID: id, AccountID: accountID, Timestamp: timestamp.Add(time.Duration(i) * time.Second),
+				Amount: models.Cents(10), Type: "credit", Status: "completed"}
+		}
+		return transactions
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		want         string // Description of the expected burst; "" for none
+	}{
+		{name: "sequential burst", transactions: batch("ACC1", "TX1005", "TX1001", "TX1003", "TX1002", "TX1004"),
+			want: "5 transactions with sequential IDs from TX1001 to TX1005"},
+		{name: "random IDs", transactions: batch("ACC1", "TX8817", "TX1201", "TX5530", "TX3309", "TX7064")},
+		{name: "run too short", transactions: batch("ACC1", "TX1001", "TX1002", "TX1003", "TX1004", "TX2000")},
+		{name: "spread across accounts", transactions: append(batch("ACC1", "TX1001", "TX1003", "TX1005"),
+			batch("ACC2", "TX1002", "TX1004")...)},
+		{name: "non-numeric IDs skipped", transactions: batch("ACC1", "TX1001", "TX1002", "alpha", "TX1003", "beta", "TX1004", "TX1005"),
+			want: "5 transactions with sequential IDs from TX1001 to TX1005"},
+		{name: "only non-numeric IDs", transactions: batch("ACC1", "a", "b", "c", "d", "e")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := detectSequentialIDBursts(tt.transactions, DefaultConfig())
+			if tt.want == "" {
+				if len(anomalies) != 0 {
+					t.Errorf("anomalies = %+v, want none", anomalies)
+				}
+				return
+			}
+			if len(anomalies) != 1 || anomalies[0].Type != "sequential_id_burst" || anomalies[0].AccountID != "ACC1" {
+				t.Fatalf("anomalies = %+v, want one sequential_id_burst on ACC1", anomalies)
+			}
+			if anomalies[0].Description != tt.want {
+				t.Errorf("description = %q, want %q", anomalies[0].Description, tt.want)
+			}
+		})
+	}
+}
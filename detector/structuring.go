@@ -0,0 +1,68 @@
+// detector/structuring.go
+package detector
+
+import (
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// detectStructuring flags accounts with at least StructuringMinCount completed debits in one day
+// whose amounts fall within StructuringBand below the large transaction threshold, a pattern of
+// keeping withdrawals just under the reporting limit
+func detectStructuring(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.StructuringBand <= 0 || config.StructuringMinCount <= 0 {
+		return anomalies
+	}
+	ceiling := models.Cents(config.LargeTransactionThreshold)
+	floor := models.Cents(config.LargeTransactionThreshold - config.StructuringBand)
+
+	// Group in-band debits by account and day, remembering the order groups were first seen
+	type accountDay struct {
+		accountID string
+		date      string
+	}
+	debitsByDay := make(map[accountDay][]models.Transaction)
+	order := []accountDay{}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" || transaction.Type != "debit" {
+			continue
+		}
+		if transaction.Amount < floor || transaction.Amount >= ceiling {
+			continue
+		}
+		key := accountDay{accountID: transaction.AccountID, date: transaction.Timestamp.Format("2006-01-02")}
+		if _, seen := debitsByDay[key]; !seen {
+			order = append(order, key)
+		}
+		debitsByDay[key] = append(debitsByDay[key], transaction)
+	}
+
+	for _, key := range order {
+		debits := debitsByDay[key]
+		if len(debits) < config.StructuringMinCount {
+			continue
+		}
+
+		totalAmount := models.Money(0)
+		last := debits[0]
+		for _, debit := range debits {
+			totalAmount += debit.Amount
+			if debit.Timestamp.After(last.Timestamp) {
+				last = debit
+			}
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: last.ID,
+			AccountID:     key.accountID,
+			Timestamp:     last.Timestamp,
+			Type:          "structuring",
+			Description: fmt.Sprintf("%d debits totaling $%s on %s within $%.2f below the $%.2f threshold",
+				len(debits), totalAmount, key.date, config.StructuringBand, config.LargeTransactionThreshold),
+			Severity: "high",
+		})
+	}
+
+	return anomalies
+}
@@ -0,0 +1,61 @@
+// detector/structuring_test.go
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectStructuring(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	// debits returns count completed debits on ACC1 an hour apart from the start, numbered from TX1
+	debits := func(count int, amount float64, start time.Time) []models.Transaction {
+		var transactions []models.Transaction
+		for i := 0; i < count; i++ {
+			transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("TX%d", i+1), AccountID: "ACC1",
+				Timestamp: start.Add(time.Duration(i) * time.Hour), Amount: models.Cents(amount), Type: "debit", Status: "completed"})
+		}
+		return transactions
+	}
+	nextDay := append(debits(2, 9800, start), debits(2, 9800, start.Add(24*time.Hour))...)
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		band         float64
+		minCount     int
+		want         []string // Last transaction of each flagged account day
+	}{
+		{name: "four in the band", transactions: debits(4, 9800, start), want: []string{"TX4"}},
+		{name: "three in the band", transactions: debits(3, 9800, start)},
+		{name: "split across two days", transactions: nextDay},
+		{name: "below the band", transactions: debits(4, 8500, start)},
+		{name: "at the threshold", transactions: debits(4, 10000, start)},
+		{name: "narrower band", transactions: debits(4, 9800, start), band: 100},
+		{name: "lower count", transactions: debits(3, 9800, start), minCount: 3, want: []string{"TX3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			if tt.band != 0 {
+				config.StructuringBand = tt.band
+			}
+			if tt.minCount != 0 {
+				config.StructuringMinCount = tt.minCount
+			}
+			var got []string
+			for _, anomaly := range detectStructuring(tt.transactions, config) {
+				if anomaly.Type != "structuring" || anomaly.Severity != "high" {
+					t.Errorf("anomaly %s of %s severity, want structuring of high", anomaly.Type, anomaly.Severity)
+				}
+				got = append(got, anomaly.TransactionID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("structuring flagged at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
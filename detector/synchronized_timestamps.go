@@ -0,0 +1,51 @@
+// detector/synchronized_timestamps.go
+package detector
+
+import (
+	"fmt"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// detectSynchronizedTimestamps emits a data-quality anomaly for each exact timestamp shared by
+// transactions on more than the configured number of distinct accounts, a sign of automated injection
+func detectSynchronizedTimestamps(transactions []models.Transaction, config Config) []models.Anomaly {
+	anomalies := []models.Anomaly{}
+	if config.SynchronizedTimestampMaxAccounts <= 0 {
+		return anomalies
+	}
+
+	// Collect the distinct accounts and first transaction at each timestamp, in first-seen order
+	accountsAt := make(map[time.Time]map[string]bool)
+	firstAt := make(map[time.Time]models.Transaction)
+	order := []time.Time{}
+	for _, transaction := range transactions {
+		ts := transaction.Timestamp.UTC()
+		if _, seen := accountsAt[ts]; !seen {
+			accountsAt[ts] = make(map[string]bool)
+			firstAt[ts] = transaction
+			order = append(order, ts)
+		}
+		accountsAt[ts][transaction.AccountID] = true
+	}
+
+	for _, ts := range order {
+		count := len(accountsAt[ts])
+		if count <= config.SynchronizedTimestampMaxAccounts {
+			continue
+		}
+
+		first := firstAt[ts]
+		anomalies = append(anomalies, models.Anomaly{
+			TransactionID: first.ID,
+			Timestamp:     first.Timestamp,
+			Type:          "synchronized_timestamp",
+			Description: fmt.Sprintf("Transactions on %d distinct accounts share the exact timestamp %s",
+				count, ts.Format(time.RFC3339Nano)),
+			Severity: "medium",
+		})
+	}
+
+	return anomalies
+}
@@ -0,0 +1,54 @@
+// detector/synchronized_timestamps_test.go
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestDetectSynchronizedTimestamps(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 3, 0, 0, 0, time.UTC)
+	batch := func(count int, accounts int, spread time.Duration) []models.Transaction {
+		transactions := make([]models.Transaction, count)
+		for i := range transactions {
+			transactions[i] = models.Transaction{ID: fmt.Sprintf("TX%d", i), AccountID: fmt.Sprintf("ACC%d", i%accounts),
+				Timestamp: timestamp.Add(time.Duration(i) * spread), Amount: models.Cents(10), Type: "credit", Status: "completed"}
+		}
+		return transactions
+	}
+	zoned := batch(10, 10, 0)
+	for i := range zoned[5:] {
+		zoned[5+i].Timestamp = zoned[5+i].Timestamp.In(time.FixedZone("EDT", -4*60*60))
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		wantFlag     bool
+	}{
+		{name: "ten accounts at one timestamp", transactions: batch(10, 10, 0), wantFlag: true},
+		{name: "spread timestamps", transactions: batch(10, 10, time.Second)},
+		{name: "few distinct accounts", transactions: batch(10, 5, 0)},
+		{name: "one instant in two zones", transactions: zoned, wantFlag: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := detectSynchronizedTimestamps(tt.transactions, DefaultConfig())
+			if !tt.wantFlag {
+				if len(anomalies) != 0 {
+					t.Errorf("anomalies = %+v, want none", anomalies)
+				}
+				return
+			}
+			if len(anomalies) != 1 || anomalies[0].Type != "synchronized_timestamp" || anomalies[0].TransactionID != "TX0" {
+				t.Fatalf("anomalies = %+v, want one synchronized_timestamp against TX0", anomalies)
+			}
+			if want := "Transactions on 10 distinct accounts share the exact timestamp 2025-04-15T03:00:00Z"; anomalies[0].Description != want {
+				t.Errorf("description = %q, want %q", anomalies[0].Description, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// detector/testdata/plugin/rule.go
+// Command plugin is a detector plugin fixture flagging every transaction tagged review=yes
+package main
+
+import (
+	"DailyTransactionBatchProcessing/detector"
+	"DailyTransactionBatchProcessing/models"
+)
+
+type taggedRule struct{}
+
+func (taggedRule) Name() string {
+	return "tagged_for_review"
+}
+
+func (taggedRule) Evaluate(transactions []models.Transaction, _ map[string]models.Account, _ detector.Config) []models.Anomaly {
+	var anomalies []models.Anomaly
+	for _, transaction := range transactions {
+		if transaction.Tags["review"] == "yes" {
+			anomalies = append(anomalies, models.Anomaly{
+				AccountID:     transaction.AccountID,
+				TransactionID: transaction.ID,
+				Timestamp:     transaction.Timestamp,
+				Type:          "tagged_for_review",
+				Description:   "Transaction tagged for review",
+				Severity:      "low",
+			})
+		}
+	}
+	return anomalies
+}
+
+// AnomalyRules is the symbol the detector looks up
+func AnomalyRules() []detector.AnomalyRule {
+	return []detector.AnomalyRule{taggedRule{}}
+}
+
+func main() {}
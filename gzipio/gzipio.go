@@ -0,0 +1,81 @@
+// gzipio/gzipio.go
+package gzipio
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsGzip reports whether a file path names a gzip-compressed file
+func IsGzip(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".gz")
+}
+
+// TrimExt returns the file path without a trailing .gz extension
+func TrimExt(filePath string) string {
+	if IsGzip(filePath) {
+		return filePath[:len(filePath)-len(filepath.Ext(filePath))]
+	}
+	return filePath
+}
+
+// Open opens a file for reading, decompressing it when the path ends in .gz
+func Open(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !IsGzip(filePath) {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: reader, file: file}, nil
+}
+
+// Create creates a file for writing, compressing what is written when the path ends in .gz
+func Create(filePath string) (io.WriteCloser, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !IsGzip(filePath) {
+		return file, nil
+	}
+	return &gzipWriteCloser{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+// gzipReadCloser closes the underlying file along with the gzip stream
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *gzipReadCloser) Close() error {
+	err := r.Reader.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// gzipWriteCloser flushes the gzip stream before closing the underlying file
+type gzipWriteCloser struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (w *gzipWriteCloser) Close() error {
+	err := w.Writer.Close()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
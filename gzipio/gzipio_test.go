@@ -0,0 +1,88 @@
+// gzipio/gzipio_test.go
+package gzipio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	content := []byte("transaction_id,account_id\nTX1,ACC1\nTX2,ACC2\n")
+	tests := []struct {
+		name       string
+		compressed bool
+	}{
+		{name: "transactions.csv"},
+		{name: "transactions.csv.gz", compressed: true},
+		{name: "transactions.csv.GZ", compressed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			writer, err := Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := writer.Write(content); err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.HasPrefix(raw, []byte{0x1f, 0x8b}); got != tt.compressed {
+				t.Errorf("file is gzip-compressed = %v, want %v", got, tt.compressed)
+			}
+
+			reader, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("read back %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsUncompressedGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.csv.gz")
+	if err := os.WriteFile(path, []byte("transaction_id\nTX1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reader, err := Open(path); err == nil {
+		reader.Close()
+		t.Error("opening a plain file named .gz succeeded")
+	}
+}
+
+func TestTrimExt(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "data/transactions_2025-04-15.csv.gz", want: "data/transactions_2025-04-15.csv"},
+		{path: "data/accounts.csv.GZ", want: "data/accounts.csv"},
+		{path: "data/accounts.csv", want: "data/accounts.csv"},
+		{path: "state.gob.gz", want: "state.gob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := TrimExt(tt.path); got != tt.want {
+				t.Errorf("TrimExt(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
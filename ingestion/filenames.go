@@ -0,0 +1,48 @@
+// ingestion/filenames.go
+package ingestion
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// filenameDatePattern matches a YYYY-MM-DD date embedded in a file name
+var filenameDatePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// DateFromFilename extracts the date from a conventionally named file such as
+// transactions_2025-04-15.csv. It reports false when no valid date is present.
+func DateFromFilename(filePath string) (time.Time, bool) {
+	matches := filenameDatePattern.FindAllString(filepath.Base(filePath), -1)
+	// Use the last date in the name, which follows any prefix
+	for i := len(matches) - 1; i >= 0; i-- {
+		date, err := time.Parse("2006-01-02", matches[i])
+		if err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CheckFileDate returns an error when the date in a file's name is not one of the allowed
+// dates. Files without a date in their name pass.
+func CheckFileDate(filePath string, allowed ...time.Time) error {
+	fileDate, ok := DateFromFilename(filePath)
+	if !ok {
+		return nil
+	}
+	for _, date := range allowed {
+		if fileDate.Format("2006-01-02") == date.Format("2006-01-02") {
+			return nil
+		}
+	}
+
+	names := make([]string, len(allowed))
+	for i, date := range allowed {
+		names[i] = date.Format("2006-01-02")
+	}
+	return fmt.Errorf("%s is dated %s, expected %s",
+		filepath.Base(filePath), fileDate.Format("2006-01-02"), strings.Join(names, " or "))
+}
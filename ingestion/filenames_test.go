@@ -0,0 +1,64 @@
+// ingestion/filenames_test.go
+package ingestion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateFromFilename(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "conventional name", path: "transactions_2025-04-15.csv", want: time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "in a directory", path: "input/2024-01-01/transactions_2025-04-15.json", want: time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "last date wins", path: "rerun_2025-04-16_transactions_2025-04-15.csv", want: time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "invalid date skipped", path: "transactions_2025-04-15_2025-13-40.csv", want: time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "no date", path: "transactions.csv"},
+		{name: "only an invalid date", path: "transactions_2025-02-30.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DateFromFilename(tt.path)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("DateFromFilename(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckFileDate(t *testing.T) {
+	monday := time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)
+	friday := time.Date(2025, 4, 11, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		path    string
+		allowed []time.Time
+		wantErr string
+	}{
+		{name: "matching", path: "transactions_2025-04-14.csv", allowed: []time.Time{monday}},
+		{name: "previous business day allowed", path: "accounts_2025-04-11.csv", allowed: []time.Time{monday, friday}},
+		{name: "undated", path: "accounts.csv", allowed: []time.Time{monday}},
+		{name: "mismatched", path: "data/transactions_2025-04-15.csv", allowed: []time.Time{monday},
+			wantErr: "transactions_2025-04-15.csv is dated 2025-04-15, expected 2025-04-14"},
+		{name: "mismatched against either", path: "accounts_2025-04-10.csv", allowed: []time.Time{monday, friday},
+			wantErr: "accounts_2025-04-10.csv is dated 2025-04-10, expected 2025-04-14 or 2025-04-11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFileDate(tt.path, tt.allowed...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckFileDate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("CheckFileDate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// ingestion/load_approvals.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Approval decisions recorded in an approvals file
+const (
+	ApprovalApproved = "approved"
+	ApprovalDenied   = "denied"
+)
+
+// LoadApprovals loads manual approval decisions from a CSV file (transaction_id, decision),
+// returning whether each listed transaction was approved
+func LoadApprovals(filePath string) (map[string]bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening approvals file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	approvals := make(map[string]bool)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(record[1])) {
+		case ApprovalApproved:
+			approvals[record[0]] = true
+		case ApprovalDenied:
+			approvals[record[0]] = false
+		default:
+			return nil, fmt.Errorf("invalid approval decision at line %d: must be '%s' or '%s'", i+1, ApprovalApproved, ApprovalDenied)
+		}
+	}
+
+	return approvals, nil
+}
@@ -0,0 +1,42 @@
+// ingestion/load_frozen.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadFrozenAccounts loads the accounts frozen under investigation from a CSV file whose first
+// column is the account ID; further columns, such as a reason, are ignored
+func LoadFrozenAccounts(filePath string, normalizer models.IDNormalizer) (map[string]bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening frozen accounts file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	frozen := make(map[string]bool)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 1 || record[0] == "" {
+			return nil, fmt.Errorf("invalid record format at line %d: missing account ID", i+1)
+		}
+		frozen[normalizer.Normalize(record[0])] = true
+	}
+
+	return frozen, nil
+}
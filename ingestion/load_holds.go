@@ -0,0 +1,65 @@
+// ingestion/load_holds.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadHolds loads hold metadata from a CSV file (hold_id, account_id, amount, placed_at,
+// expires_at). Holds without an explicit expiry expire the given lifetime after placement.
+func LoadHolds(filePath string, lifetime time.Duration) ([]models.Hold, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening holds file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	holds := make([]models.Hold, 0, len(records))
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		amount, err := models.ParseMoney(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount at line %d: %w", i+1, err)
+		}
+		placedAt, err := time.Parse(time.RFC3339, record[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid placement time at line %d: %w", i+1, err)
+		}
+
+		expiresAt := placedAt.Add(lifetime)
+		if len(record) > 4 && record[4] != "" {
+			expiresAt, err = time.Parse(time.RFC3339, record[4])
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiry time at line %d: %w", i+1, err)
+			}
+		}
+
+		holds = append(holds, models.Hold{
+			ID:        record[0],
+			AccountID: record[1],
+			Amount:    amount,
+			PlacedAt:  placedAt,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	return holds, nil
+}
@@ -0,0 +1,187 @@
+// ingestion/load_processed.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// processedColumns are the processed transactions file columns in the order
+// LoadProcessedTransactions reads them; the first requiredProcessedColumns must be present
+var processedColumns = []string{
+	"transaction_id",
+	"account_id",
+	"timestamp",
+	"amount",
+	"type",
+	"status",
+	"description",
+	"destination_account_id",
+	"processing_message",
+	"currency",
+	"tags",
+	"original_amount",
+	"original_currency",
+	"exchange_rate",
+	"destination_amount",
+	"balance_after",
+	"original_transaction_id",
+	"reversed_type",
+}
+
+const requiredProcessedColumns = 6
+
+// ReplayColumns are the processed transactions file columns a re-run needs to replay the
+// outcomes a prior run recorded: those processing sets, besides the required ones
+var ReplayColumns = []string{
+	"processing_message",
+	"currency",
+	"original_amount",
+	"original_currency",
+	"exchange_rate",
+	"destination_amount",
+	"balance_after",
+	"reversed_type",
+}
+
+// LoadProcessedTransactions loads a processed transactions file written by a prior run,
+// including the conversion audit fields. Columns are matched by header name, so files written
+// with a subset of the columns load with the missing fields empty.
+func LoadProcessedTransactions(filePath string) ([]models.Transaction, error) {
+	file, err := gzipio.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening processed transactions file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadProcessedTransactions(file)
+}
+
+// ReadProcessedTransactions reads processed transactions CSV written by a prior run
+func ReadProcessedTransactions(r io.Reader) ([]models.Transaction, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	return parseProcessedRecords(records)
+}
+
+// ReadReplayLedger reads a prior run's processed transactions CSV to replay, returning an error
+// naming the ReplayColumns it was written without
+func ReadReplayLedger(r io.Reader) ([]models.Transaction, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return []models.Transaction{}, nil
+	}
+
+	present := make(map[string]bool, len(records[0]))
+	for _, name := range records[0] {
+		present[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	var missing []string
+	for _, name := range ReplayColumns {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("processed transactions file lacks the %s columns needed to replay it", strings.Join(missing, ", "))
+	}
+	return parseProcessedRecords(records)
+}
+
+// ReadProcessedTransactionsJSON reads processed transactions a prior run wrote as a JSON array
+func ReadProcessedTransactionsJSON(r io.Reader) ([]models.Transaction, error) {
+	transactions := []models.Transaction{}
+	if err := json.NewDecoder(r).Decode(&transactions); err != nil {
+		return nil, fmt.Errorf("error reading JSON: %w", err)
+	}
+	return transactions, nil
+}
+
+// parseProcessedRecords parses processed transactions CSV records, the first being the header
+func parseProcessedRecords(records [][]string) ([]models.Transaction, error) {
+	if len(records) == 0 {
+		return []models.Transaction{}, nil
+	}
+
+	// Map the columns by header name
+	columns, err := models.MapColumns(records[0], processedColumns, requiredProcessedColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid processed transactions file header: %w", err)
+	}
+
+	transactions := make([]models.Transaction, 0, len(records))
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		// Put the values in processedColumns order, with missing trailing columns empty
+		record = columns.Reorder(record)
+		record = append(record, make([]string, len(processedColumns)-len(record))...)
+
+		transaction := models.Transaction{
+			ID:                   record[0],
+			AccountID:            record[1],
+			Type:                 record[4],
+			Status:               record[5],
+			Description:          record[6],
+			DestinationAccountID: record[7],
+			ProcessingMessage:    record[8],
+			Currency:             record[9],
+			OriginalCurrency:     record[12],
+
+			OriginalTransactionID: record[16],
+			ReversedType:          record[17],
+		}
+
+		transaction.Timestamp, err = time.Parse(time.RFC3339, record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp at line %d: %w", i+1, err)
+		}
+		transaction.Tags, err = models.ParseTags(record[10])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tags at line %d: %w", i+1, err)
+		}
+
+		// Parse the amount columns, leaving empty optional ones at zero
+		amounts := []struct {
+			index  int
+			target *models.Money
+		}{
+			{3, &transaction.Amount},
+			{11, &transaction.OriginalAmount},
+			{14, &transaction.DestinationAmount},
+			{15, &transaction.BalanceAfter},
+		}
+		for _, amount := range amounts {
+			if record[amount.index] == "" {
+				continue
+			}
+			if *amount.target, err = models.ParseMoney(record[amount.index]); err != nil {
+				return nil, fmt.Errorf("invalid amount at line %d: %w", i+1, err)
+			}
+		}
+		if record[13] != "" {
+			if transaction.ExchangeRate, err = models.ParseAmount(record[13]); err != nil {
+				return nil, fmt.Errorf("invalid exchange rate at line %d: %w", i+1, err)
+			}
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
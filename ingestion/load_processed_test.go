@@ -0,0 +1,189 @@
+// ingestion/load_processed_test.go
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/output"
+	"DailyTransactionBatchProcessing/processor"
+)
+
+func TestProcessedTransactionsRoundTrip(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(0.01), Type: "credit", Status: "completed", BalanceAfter: models.Cents(0.01)},
+		{ID: "T2", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(1234567.89), Type: "debit", Status: "rejected", ProcessingMessage: "Insufficient funds"},
+		{ID: "T3", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(19.99), Type: "debit", Status: "completed", BalanceAfter: models.Cents(-19.98)},
+		{ID: "T3-FEE", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(0.3), Type: "fee", Status: "completed", BalanceAfter: models.Cents(-20.28), OriginalTransactionID: "T3"},
+		{
+			ID: "T4", AccountID: "A1", DestinationAccountID: "B1", Timestamp: timestamp, Type: "transfer", Status: "completed",
+			Amount: models.Cents(108.57), Currency: "USD", OriginalAmount: models.Cents(100), OriginalCurrency: "EUR",
+			ExchangeRate: 1.0857, DestinationAmount: models.Cents(100), BalanceAfter: models.Cents(-128.85),
+		},
+	}
+
+	for _, name := range []string{"processed_transactions.csv", "processed_transactions.csv.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := output.WriteProcessedTransactions(transactions, path); err != nil {
+				t.Fatalf("writing: %v", err)
+			}
+			loaded, err := LoadProcessedTransactions(path)
+			if err != nil {
+				t.Fatalf("loading: %v", err)
+			}
+			if len(loaded) != len(transactions) {
+				t.Fatalf("loaded %d transactions, want %d", len(loaded), len(transactions))
+			}
+			for i, want := range transactions {
+				got := loaded[i]
+				if got.Amount != want.Amount || got.OriginalAmount != want.OriginalAmount ||
+					got.DestinationAmount != want.DestinationAmount || got.BalanceAfter != want.BalanceAfter {
+					t.Errorf("%s amounts = %d/%d/%d/%d cents, want %d/%d/%d/%d", want.ID,
+						got.Amount, got.OriginalAmount, got.DestinationAmount, got.BalanceAfter,
+						want.Amount, want.OriginalAmount, want.DestinationAmount, want.BalanceAfter)
+				}
+				if got.ExchangeRate != want.ExchangeRate {
+					t.Errorf("%s exchange rate = %g, want %g", want.ID, got.ExchangeRate, want.ExchangeRate)
+				}
+			}
+			if loaded[3].OriginalTransactionID != "T3" {
+				t.Errorf("fee row lost its original transaction ID: %+v", loaded[3])
+			}
+		})
+	}
+}
+
+func TestReadReplayLedger(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "completed", BalanceAfter: models.Cents(10)},
+		{ID: "T2", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(500), Type: "debit", Status: "rejected", ProcessingMessage: "Insufficient funds"},
+	}
+	all := strings.Join(processedColumns, ",")
+
+	tests := []struct {
+		name    string
+		columns string
+		format  string
+		wantErr string
+	}{
+		{name: "all columns", format: output.FormatCSV},
+		{name: "descriptive columns dropped", format: output.FormatCSV,
+			columns: strings.Join(append(processedColumns[:6:6], ReplayColumns...), ",")},
+		{name: "balance dropped", format: output.FormatCSV, columns: strings.Replace(all, ",balance_after", "", 1),
+			wantErr: "lacks the balance_after columns"},
+		{name: "processing columns dropped", format: output.FormatCSV, columns: strings.Join(processedColumns[:6], ","),
+			wantErr: "lacks the processing_message, currency, original_amount"},
+		{name: "json", format: output.FormatJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "processed_transactions."+tt.format)
+			read := ReadReplayLedger
+			var err error
+			if tt.format == output.FormatJSON {
+				read = ReadProcessedTransactionsJSON
+				err = output.WriteJSON(transactions, path)
+			} else {
+				var columns []string
+				if tt.columns != "" {
+					columns = strings.Split(tt.columns, ",")
+				}
+				err = output.WriteProcessedTransactionsColumns(transactions, path, columns)
+			}
+			if err != nil {
+				t.Fatalf("writing: %v", err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			loaded, err := read(file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reading: %v", err)
+			}
+			if len(loaded) != 2 || loaded[0].BalanceAfter != transactions[0].BalanceAfter ||
+				loaded[1].Status != "rejected" || loaded[1].ProcessingMessage != "Insufficient funds" {
+				t.Errorf("read %+v, want %+v", loaded, transactions)
+			}
+		})
+	}
+}
+
+func TestTagsPassThrough(t *testing.T) {
+	wantTags := map[string]string{"batch": "b-7", "channel": "mobile", "ref": "x=y"}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		load    func(string) ([]models.Transaction, error)
+	}{
+		{
+			name: "csv",
+			file: "transactions.csv",
+			content: "transaction_id,account_id,timestamp,amount,transaction_type,status,tags\n" +
+				"TX1,ACC1,2025-04-15T09:00:00Z,25.00,credit,pending,channel=mobile;batch=b-7;ref=x=y\n",
+			load: LoadTransactions,
+		},
+		{
+			name: "json",
+			file: "transactions.jsonl",
+			content: `{"id":"TX1","account_id":"ACC1","timestamp":"2025-04-15T09:00:00Z","amount":25,"type":"credit","status":"pending",` +
+				`"tags":{"channel":"mobile","batch":"b-7","ref":"x=y"}}` + "\n",
+			load: LoadTransactionsJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			inputPath := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(inputPath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			transactions, err := tt.load(inputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			accounts := map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+			_, processed := processor.ProcessTransactions(transactions, accounts)
+			if processed[0].Status != "completed" {
+				t.Fatalf("status = %s (%s), want completed", processed[0].Status, processed[0].ProcessingMessage)
+			}
+
+			outputPath := filepath.Join(dir, "processed_transactions.csv")
+			if err := output.WriteProcessedTransactions(processed, outputPath); err != nil {
+				t.Fatal(err)
+			}
+			written, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(written), "batch=b-7;channel=mobile;ref=x=y") {
+				t.Errorf("processed output does not carry the tags in key order:\n%s", written)
+			}
+			loaded, err := LoadProcessedTransactions(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded[0].Tags, wantTags) {
+				t.Errorf("tags = %v, want %v", loaded[0].Tags, wantTags)
+			}
+		})
+	}
+}
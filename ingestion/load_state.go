@@ -0,0 +1,27 @@
+// ingestion/load_state.go
+package ingestion
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadState reads a gob-encoded pipeline state snapshot written by output.WriteState
+func LoadState(filePath string) (models.State, error) {
+	file, err := gzipio.Open(filePath)
+	if err != nil {
+		return models.State{}, fmt.Errorf("error opening state file: %w", err)
+	}
+	defer file.Close()
+
+	var state models.State
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&state); err != nil {
+		return models.State{}, fmt.Errorf("error decoding state: %w", err)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,47 @@
+// ingestion/load_state_test.go
+package ingestion
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/output"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	state := models.State{
+		Stage: "processed",
+		Accounts: map[string]models.Account{
+			"ACC1": {ID: "ACC1", Balance: models.Cents(-12.34), OverdraftCount: 2, LastTransactionTime: timestamp, AccountType: "checking",
+				HeldAmount: models.Cents(5), Currency: "USD", ApprovedOverdraft: true, DailyDebits: models.Cents(100)},
+			"ACC2": {ID: "ACC2", Balance: models.Cents(1e6), Currency: "EUR"},
+		},
+		Transactions: []models.Transaction{
+			{ID: "TX1", AccountID: "ACC1", DestinationAccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(108.57), Type: "transfer",
+				Status: "completed", OriginalAmount: models.Cents(100), OriginalCurrency: "EUR", ExchangeRate: 1.0857,
+				Tags: map[string]string{"channel": "mobile"}, BalanceAfter: models.Cents(-12.34)},
+			{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(0.01), Type: "debit", Status: "rejected",
+				ProcessingMessage: "Insufficient funds"},
+		},
+	}
+
+	for _, name := range []string{"state.gob", "state.gob.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := output.WriteState(state, path); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadState(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded, state) {
+				t.Errorf("loaded state = %+v, want %+v", loaded, state)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// ingestion/load_summaries.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadAccountSummaries loads a prior day's account summary CSV file
+func LoadAccountSummaries(filePath string) ([]models.AccountSummary, error) {
+	file, err := gzipio.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening account summary file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadAccountSummaries(file)
+}
+
+// ReadAccountSummaries reads a prior day's account summary CSV
+func ReadAccountSummaries(r io.Reader) ([]models.AccountSummary, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	summaries := make([]models.AccountSummary, 0, len(records))
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		// Ensure we have the expected number of fields
+		if len(record) < 8 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		summary := models.AccountSummary{
+			AccountID: record[0],
+			Date:      record[1],
+		}
+
+		amounts := []*models.Money{&summary.OpeningBalance, &summary.ClosingBalance, &summary.TotalDebits, &summary.TotalCredits}
+		for j, target := range amounts {
+			value, err := models.ParseMoney(record[2+j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount at line %d: %w", i+1, err)
+			}
+			*target = value
+		}
+
+		if summary.TransactionCount, err = strconv.Atoi(record[6]); err != nil {
+			return nil, fmt.Errorf("invalid transaction count at line %d: %w", i+1, err)
+		}
+		if summary.OverdraftCount, err = strconv.Atoi(record[7]); err != nil {
+			return nil, fmt.Errorf("invalid overdraft count at line %d: %w", i+1, err)
+		}
+
+		// Summaries written before incoming transfers were counted separately have no transfers_in column
+		if len(record) > 10 {
+			if summary.TransfersIn, err = strconv.Atoi(record[10]); err != nil {
+				return nil, fmt.Errorf("invalid transfers in count at line %d: %w", i+1, err)
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
@@ -2,28 +2,66 @@
 package ingestion
 
 import (
+	"DailyTransactionBatchProcessing/gzipio"
 	"DailyTransactionBatchProcessing/models"
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"os"
+	"regexp"
 	"strconv"
 	"time"
 )
 
+// EpochSeconds is a timestamp layout name matching Unix epoch seconds
+const EpochSeconds = "epoch"
+
+// LoaderConfig holds options for parsing transaction files
+type LoaderConfig struct {
+	TimestampLayouts []string `json:"timestamp_layouts"` // Layouts tried in order; EpochSeconds accepts Unix seconds
+}
+
+// DefaultLoaderConfig returns the parsing options used when none are supplied
+func DefaultLoaderConfig() LoaderConfig {
+	return LoaderConfig{
+		TimestampLayouts: []string{time.RFC3339},
+	}
+}
+
+// transactionColumns are the transaction file columns in the order parseTransaction reads them;
+// the first requiredTransactionColumns must be present
+var transactionColumns = []string{
+	"transaction_id",
+	"account_id",
+	"timestamp",
+	"amount",
+	"transaction_type",
+	"status",
+	"description",
+	"destination_account_id",
+	"currency",
+	"tags",
+	"original_transaction_id",
+}
+
+const requiredTransactionColumns = 6
+
 // LoadTransactions loads transaction data from a CSV file
 func LoadTransactions(filePath string) ([]models.Transaction, error) {
-	file, err := os.Open("data/transactions_2025-04-15.csv")
+	return LoadTransactionsWithConfig(filePath, DefaultLoaderConfig())
+}
+
+// LoadTransactionsWithConfig loads transaction data from a CSV file using the supplied parsing options.
+// Records that fail to parse are skipped and reported together as models.ParseErrors, returned
+// with the records that did parse.
+func LoadTransactionsWithConfig(filePath string, config LoaderConfig) ([]models.Transaction, error) {
+	file, err := gzipio.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("error opening transactions file: %w", err)
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-
-		}
-	}(file)
+	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("error reading CSV: %w", err)
@@ -34,34 +72,51 @@ func LoadTransactions(filePath string) ([]models.Transaction, error) {
 		return nil, fmt.Errorf("transaction file is empty or missing data rows")
 	}
 
-	// Skip header row
+	// Map the columns by header name
+	columns, err := models.MapColumns(records[0], transactionColumns, requiredTransactionColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transactions file header: %w", err)
+	}
+
 	transactions := make([]models.Transaction, 0, len(records)-1)
+	var parseErrors models.ParseErrors
 	for i, record := range records {
 		// Skip header row
 		if i == 0 {
 			continue
 		}
 
-		// Ensure we have the expected number of fields
-		if len(record) < 6 {
-			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
-		}
-
 		// Parse transaction data
-		transaction, err := parseTransaction(record, i+1)
+		transaction, err := parseTransaction(columns.Reorder(record), i+1, config)
 		if err != nil {
-			return nil, err
+			err.Record = record // As it appears in the file, not reordered
+			parseErrors = append(parseErrors, err)
+			continue
 		}
 
 		transactions = append(transactions, transaction)
 	}
 
+	if len(parseErrors) > 0 {
+		return transactions, parseErrors
+	}
 	return transactions, nil
 }
 
-// parseTransaction parses a CSV record into a Transaction struct
-func parseTransaction(record []string, lineNum int) (models.Transaction, error) {
-	// Expected format: [transactionID, accountID, timestamp, amount, transactionType, status, description(optional)]
+// parseTransaction parses a CSV record, with its columns in transactionColumns order, into a Transaction struct
+func parseTransaction(record []string, lineNum int, config LoaderConfig) (models.Transaction, *models.ParseError) {
+	// Expected format: [transactionID, accountID, timestamp, amount, transactionType, status,
+	// description(optional), destinationAccountID(transfers), currency(optional), tags(optional),
+	// originalTransactionID(reversals)]
+	fail := func(field string, err error) (models.Transaction, *models.ParseError) {
+		return models.Transaction{}, &models.ParseError{LineNumber: lineNum, Field: field, Record: record, Err: err}
+	}
+
+	// Ensure we have the expected number of fields
+	if len(record) < 6 {
+		return fail("", errors.New("insufficient fields"))
+	}
+
 	transaction := models.Transaction{
 		ID:          record[0],
 		AccountID:   record[1],
@@ -69,30 +124,30 @@ func parseTransaction(record []string, lineNum int) (models.Transaction, error)
 	}
 
 	// Parse timestamp
-	timestamp, err := time.Parse(time.RFC3339, record[2])
+	timestamp, err := ParseTimestamp(record[2], config.TimestampLayouts)
 	if err != nil {
-		return transaction, fmt.Errorf("invalid timestamp at line %d: %w", lineNum, err)
+		return fail("timestamp", err)
 	}
 	transaction.Timestamp = timestamp
 
 	// Parse amount
-	amount, err := strconv.ParseFloat(record[3], 64)
+	amount, err := models.ParseMoney(record[3])
 	if err != nil {
-		return transaction, fmt.Errorf("invalid amount at line %d: %w", lineNum, err)
+		return fail("amount", err)
 	}
 	transaction.Amount = amount
 
 	// Parse transaction type
 	transactionType := record[4]
-	if transactionType != "credit" && transactionType != "debit" && transactionType != "transfer" {
-		return transaction, fmt.Errorf("invalid transaction type at line %d: must be 'credit', 'debit', or 'transfer'", lineNum)
+	if err := checkTransactionType(transactionType); err != nil {
+		return fail("transaction type", err)
 	}
 	transaction.Type = transactionType
 
 	// Parse status
 	status := record[5]
-	if status != "pending" && status != "completed" && status != "rejected" {
-		return transaction, fmt.Errorf("invalid status at line %d: must be 'pending', 'completed', or 'rejected'", lineNum)
+	if err := checkTransactionStatus(status); err != nil {
+		return fail("status", err)
 	}
 	transaction.Status = status
 
@@ -103,23 +158,168 @@ func parseTransaction(record []string, lineNum int) (models.Transaction, error)
 
 	// For transfer transactions, ensure destination account is specified
 	if transactionType == "transfer" && len(record) < 8 {
-		return transaction, fmt.Errorf("transfer transaction at line %d is missing destination account", lineNum)
+		return fail("destination account", errors.New("transfer is missing destination account"))
 	} else if transactionType == "transfer" {
 		transaction.DestinationAccountID = record[7]
 	}
 
+	// Parse optional currency field
+	if len(record) > 8 {
+		transaction.Currency = record[8]
+	}
+
+	// Parse optional tags field
+	if len(record) > 9 {
+		tags, err := models.ParseTags(record[9])
+		if err != nil {
+			return fail("tags", err)
+		}
+		transaction.Tags = tags
+	}
+
+	// For reversals, ensure the original transaction is referenced
+	if len(record) > 10 {
+		transaction.OriginalTransactionID = record[10]
+	}
+	if transactionType == "reversal" && transaction.OriginalTransactionID == "" {
+		return fail("original transaction", errors.New("reversal is missing original transaction ID"))
+	}
+
 	return transaction, nil
 }
 
+// checkTransactionType returns an error unless the type is one accepted in input files
+func checkTransactionType(transactionType string) error {
+	if transactionType != "credit" && transactionType != "debit" && transactionType != "transfer" && transactionType != "reversal" {
+		return fmt.Errorf("%q must be 'credit', 'debit', 'transfer', or 'reversal'", transactionType)
+	}
+	return nil
+}
+
+// checkTransactionStatus returns an error unless the status is one accepted in input files
+func checkTransactionStatus(status string) error {
+	if status != "pending" && status != "completed" && status != "rejected" && status != "awaiting_approval" {
+		return fmt.Errorf("%q must be 'pending', 'completed', 'rejected', or 'awaiting_approval'", status)
+	}
+	return nil
+}
+
+// ParseTimestamp parses a timestamp using the first of the layouts that matches, normalizing
+// to UTC when the value carries no zone
+func ParseTimestamp(value string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if layout == EpochSeconds {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				return time.Unix(seconds, 0).UTC(), nil
+			}
+			continue
+		}
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q matches none of the accepted layouts %q", value, layouts)
+}
+
+// NormalizeAccountIDs normalizes the source and destination account IDs of each transaction
+func NormalizeAccountIDs(transactions []models.Transaction, normalizer models.IDNormalizer) {
+	for i := range transactions {
+		transactions[i].AccountID = normalizer.Normalize(transactions[i].AccountID)
+		if transactions[i].DestinationAccountID != "" {
+			transactions[i].DestinationAccountID = normalizer.Normalize(transactions[i].DestinationAccountID)
+		}
+	}
+}
+
+// NormalizeTimestamps converts each transaction timestamp to the business time zone, so date
+// boundaries fall at that zone's midnight
+func NormalizeTimestamps(transactions []models.Transaction, location *time.Location) {
+	for i := range transactions {
+		transactions[i].Timestamp = transactions[i].Timestamp.In(location)
+	}
+}
+
+// Stale pending policies
+const (
+	StalePendingProcess = "process" // Process stale pending transactions as usual
+	StalePendingExpire  = "expire"  // Expire stale pending transactions
+)
+
+// ValidationConfig holds optional validation rules
+type ValidationConfig struct {
+	ProcessDate  time.Time `json:"process_date"`  // Processing date; zero disables stale pending detection
+	StalePending string    `json:"stale_pending"` // Policy for pending transactions older than the processing date
+
+	// Transactions a previous batch held over for retry, by ID; their age is expected, so they
+	// are exempt from the stale pending check
+	HeldOver map[string]bool `json:"-"`
+
+	// Patterns recognizing transaction IDs, used to catch account IDs from swapped columns
+	TransactionIDPatterns []*regexp.Regexp `json:"transaction_id_patterns"`
+
+	// Manual approval: transactions at or above the threshold post only once approved
+	ApprovalThreshold float64         `json:"approval_threshold"` // 0 disables the workflow
+	Approvals         map[string]bool `json:"approvals"`          // Decisions by transaction ID; true is approved
+
+	// Accept credits to accounts that do not exist yet, which processing creates
+	AutoCreateOnCredit bool `json:"autocreate_on_credit"`
+
+	// Accounts frozen under investigation; transactions from or to them are rejected
+	FrozenAccounts map[string]bool `json:"frozen_accounts"`
+}
+
+// DefaultValidationConfig returns the validation rules used when none are supplied
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		StalePending: StalePendingProcess,
+	}
+}
+
 // ValidateTransactions validates a slice of transactions against a map of accounts
 func ValidateTransactions(transactions []models.Transaction, accounts map[string]models.Account) ([]models.Transaction, []models.Transaction) {
+	// The default rules have no approval threshold, so nothing awaits approval
+	validTransactions, invalidTransactions, _ := ValidateTransactionsWithConfig(transactions, accounts, DefaultValidationConfig())
+	return validTransactions, invalidTransactions
+}
+
+// ValidateTransactionsWithConfig validates transactions using the supplied validation rules,
+// returning the valid transactions, the invalid ones, and those held awaiting manual approval
+func ValidateTransactionsWithConfig(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config ValidationConfig,
+) ([]models.Transaction, []models.Transaction, []models.Transaction) {
 	validTransactions := make([]models.Transaction, 0)
 	invalidTransactions := make([]models.Transaction, 0)
+	awaitingApproval := make([]models.Transaction, 0)
+
+	// Start of the processing day, used to detect stale pending transactions
+	var dayStart time.Time
+	if !config.ProcessDate.IsZero() {
+		year, month, day := config.ProcessDate.Date()
+		dayStart = time.Date(year, month, day, 0, 0, 0, 0, config.ProcessDate.Location())
+	}
+
+	// Transaction IDs already seen, so replayed records are applied only once
+	seenIDs := make(map[string]bool, len(transactions))
+
+	// Valid transactions by ID, and the originals already claimed by a reversal
+	validByID := make(map[string]models.Transaction)
+	reversedIDs := make(map[string]bool)
 
 	for _, transaction := range transactions {
 		valid := true
 		reason := ""
 
+		// Keep only the first occurrence of each transaction ID
+		if seenIDs[transaction.ID] {
+			transaction.ValidationMessage = fmt.Sprintf("Duplicate transaction ID %s", transaction.ID)
+			invalidTransactions = append(invalidTransactions, transaction)
+			continue
+		}
+		seenIDs[transaction.ID] = true
+
 		// Skip already rejected transactions
 		if transaction.Status == "rejected" {
 			transaction.ValidationMessage = "Already rejected in input file"
@@ -127,13 +327,25 @@ func ValidateTransactions(transactions []models.Transaction, accounts map[string
 			continue
 		}
 
-		// Only process pending transactions
-		if transaction.Status != "pending" {
+		// Only process pending transactions and those awaiting approval
+		if transaction.Status != "pending" && transaction.Status != "awaiting_approval" {
 			transaction.ValidationMessage = "Only pending transactions can be processed"
 			invalidTransactions = append(invalidTransactions, transaction)
 			continue
 		}
 
+		// Handle pending transactions carried over from a prior day
+		if !dayStart.IsZero() && transaction.Timestamp.Before(dayStart) && !config.HeldOver[transaction.ID] {
+			staleDate := transaction.Timestamp.Format("2006-01-02")
+			if config.StalePending == StalePendingExpire {
+				transaction.Status = "expired"
+				transaction.ValidationMessage = fmt.Sprintf("Stale pending transaction from %s expired", staleDate)
+				invalidTransactions = append(invalidTransactions, transaction)
+				continue
+			}
+			transaction.ValidationMessage = fmt.Sprintf("Stale pending transaction from %s carried over", staleDate)
+		}
+
 		// Validate amount is positive
 		if transaction.Amount <= 0 {
 			valid = false
@@ -141,7 +353,7 @@ func ValidateTransactions(transactions []models.Transaction, accounts map[string
 		}
 
 		// Validate account exists
-		if _, exists := accounts[transaction.AccountID]; !exists {
+		if _, exists := accounts[transaction.AccountID]; !exists && !(config.AutoCreateOnCredit && transaction.Type == "credit") {
 			valid = false
 			reason = fmt.Sprintf("Account %s does not exist", transaction.AccountID)
 		}
@@ -160,13 +372,85 @@ func ValidateTransactions(transactions []models.Transaction, accounts map[string
 			}
 		}
 
+		// For reversals, validate the original is an earlier valid transaction on the same account
+		if transaction.Type == "reversal" {
+			original, exists := validByID[transaction.OriginalTransactionID]
+			if transaction.OriginalTransactionID == "" {
+				valid = false
+				reason = "Reversal is missing original transaction ID"
+			} else if !exists {
+				valid = false
+				reason = fmt.Sprintf("Original transaction %s not found", transaction.OriginalTransactionID)
+			} else if reversedIDs[original.ID] {
+				valid = false
+				reason = fmt.Sprintf("Original transaction %s is already reversed", original.ID)
+			} else if original.AccountID != transaction.AccountID {
+				valid = false
+				reason = fmt.Sprintf("Reversal account %s does not match original transaction account %s", transaction.AccountID, original.AccountID)
+			} else if transaction.Amount != original.Amount {
+				valid = false
+				reason = fmt.Sprintf("Reversal amount must match original transaction amount of $%s", original.Amount)
+			}
+		}
+
+		// Reject transactions touching a frozen account, including the destination of a reversed transfer
+		destinationID := transaction.DestinationAccountID
+		if original, exists := validByID[transaction.OriginalTransactionID]; exists && transaction.Type == "reversal" {
+			destinationID = original.DestinationAccountID
+		}
+		if config.FrozenAccounts[transaction.AccountID] || (destinationID != "" && config.FrozenAccounts[destinationID]) {
+			valid = false
+			reason = "Account frozen"
+		}
+
+		// Catch records whose transaction and account ID columns were swapped
+		if _, exists := accounts[transaction.ID]; exists {
+			valid = false
+			reason = fmt.Sprintf("Data quality: transaction ID %s matches a known account ID (columns may be swapped)", transaction.ID)
+		} else if matchesAny(transaction.AccountID, config.TransactionIDPatterns) {
+			valid = false
+			reason = fmt.Sprintf("Data quality: account ID %s looks like a transaction ID (columns may be swapped)", transaction.AccountID)
+		}
+
+		// Hold high-value transactions until they are manually approved
+		if valid && config.ApprovalThreshold > 0 && transaction.Amount >= models.Cents(config.ApprovalThreshold) {
+			approved, decided := config.Approvals[transaction.ID]
+			switch {
+			case !decided:
+				transaction.Status = "awaiting_approval"
+				transaction.ValidationMessage = fmt.Sprintf("Awaiting manual approval (at or above $%.2f)", config.ApprovalThreshold)
+				awaitingApproval = append(awaitingApproval, transaction)
+				continue
+			case !approved:
+				transaction.Status = "rejected"
+				valid = false
+				reason = "Denied in manual approval"
+			}
+		}
+
 		if valid {
+			transaction.Status = "pending"
 			validTransactions = append(validTransactions, transaction)
+			if transaction.Type == "reversal" {
+				reversedIDs[transaction.OriginalTransactionID] = true
+			} else {
+				validByID[transaction.ID] = transaction
+			}
 		} else {
 			transaction.ValidationMessage = reason
 			invalidTransactions = append(invalidTransactions, transaction)
 		}
 	}
 
-	return validTransactions, invalidTransactions
+	return validTransactions, invalidTransactions, awaitingApproval
+}
+
+// matchesAny reports whether value matches any of the patterns
+func matchesAny(value string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,93 @@
+// ingestion/load_transactions_json.go
+package ingestion
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// maxJSONLineSize is the longest transaction line the JSON loader accepts
+const maxJSONLineSize = 1024 * 1024
+
+// IsJSONLines reports whether a transactions file should be read as newline-delimited JSON,
+// looking past a .gz extension
+func IsJSONLines(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(gzipio.TrimExt(filePath))) {
+	case ".json", ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+// LoadTransactionsJSON loads transaction data from a newline-delimited JSON file holding one
+// transaction object per line. Blank lines are skipped, and lines that fail to parse are
+// reported together as models.ParseErrors, returned with the transactions that did parse.
+func LoadTransactionsJSON(filePath string) ([]models.Transaction, error) {
+	file, err := gzipio.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening transactions file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLineSize)
+
+	transactions := []models.Transaction{}
+	var parseErrors models.ParseErrors
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		transaction, field, err := parseTransactionJSON(line)
+		if err != nil {
+			parseErrors = append(parseErrors, &models.ParseError{LineNumber: lineNum, Field: field, Record: []string{line}, Err: err})
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading transactions file: %w", err)
+	}
+
+	if len(transactions) == 0 && len(parseErrors) == 0 {
+		return nil, fmt.Errorf("transaction file is empty or missing data rows")
+	}
+
+	if len(parseErrors) > 0 {
+		return transactions, parseErrors
+	}
+	return transactions, nil
+}
+
+// parseTransactionJSON decodes one JSON line, returning the failing field on error
+func parseTransactionJSON(line string) (models.Transaction, string, error) {
+	var transaction models.Transaction
+	if err := json.Unmarshal([]byte(line), &transaction); err != nil {
+		return transaction, "", err
+	}
+	if err := checkTransactionType(transaction.Type); err != nil {
+		return transaction, "transaction type", err
+	}
+	if err := checkTransactionStatus(transaction.Status); err != nil {
+		return transaction, "status", err
+	}
+	if transaction.Type == "transfer" && transaction.DestinationAccountID == "" {
+		return transaction, "destination account", errors.New("transfer is missing destination account")
+	}
+	if transaction.Type == "reversal" && transaction.OriginalTransactionID == "" {
+		return transaction, "original transaction", errors.New("reversal is missing original transaction ID")
+	}
+	return transaction, "", nil
+}
@@ -0,0 +1,63 @@
+// ingestion/load_transactions_json_test.go
+package ingestion
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// openFiles returns how many file descriptors the test process holds open
+func openFiles(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("cannot count open files: %v", err)
+	}
+	return len(entries)
+}
+
+func TestLoadersCloseTheirFiles(t *testing.T) {
+	const jsonLine = `{"id":"TX1","account_id":"ACC1","timestamp":"2025-04-15T09:00:00Z","amount":10,"type":"debit","status":"pending"}` + "\n"
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		load      func(path string) error
+		wantLines []int // Lines reported as unparseable
+	}{
+		{
+			name: "json", file: "transactions.jsonl", content: jsonLine + "\n" + jsonLine,
+			load: func(path string) error { _, err := LoadTransactionsJSON(path); return err },
+		},
+		{
+			name: "json with a bad line", file: "transactions.jsonl", content: jsonLine + "{\n",
+			load:      func(path string) error { _, err := LoadTransactionsJSON(path); return err },
+			wantLines: []int{2},
+		},
+		{
+			name: "parallel", file: "transactions.csv", content: testTransactionsHeader + transactionLines(100),
+			load: func(path string) error { _, err := LoadTransactionsParallel(path, 4); return err },
+		},
+		{
+			name: "parallel with a bad record", file: "transactions.csv", content: testTransactionsHeader + "TX1,ACC1,not-a-time,1.00,debit,pending,,\n",
+			load:      func(path string) error { _, err := LoadTransactionsParallel(path, 2); return err },
+			wantLines: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.file, tt.content)
+			before := openFiles(t)
+			for i := 0; i < 20; i++ {
+				err := tt.load(path)
+				if got := lineNumbers(err); !reflect.DeepEqual(got, tt.wantLines) {
+					t.Fatalf("load reported lines %v, want %v (error %v)", got, tt.wantLines, err)
+				}
+			}
+			if after := openFiles(t); after > before {
+				t.Errorf("%d files left open after 20 loads", after-before)
+			}
+		})
+	}
+}
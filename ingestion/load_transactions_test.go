@@ -0,0 +1,544 @@
+// ingestion/load_transactions_test.go
+package ingestion
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/processor"
+)
+
+func TestValidateTransactionsApproval(t *testing.T) {
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}}
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transaction := func(id string, amount float64, status string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(amount), Type: "debit", Status: status}
+	}
+
+	tests := []struct {
+		name         string
+		transaction  models.Transaction
+		threshold    float64
+		approvals    map[string]bool
+		wantValid    []string
+		wantInvalid  []string
+		wantAwaiting []string
+	}{
+		{name: "workflow off", transaction: transaction("TX1", 5000, "pending"), wantValid: []string{"TX1"}},
+		{name: "below threshold", transaction: transaction("TX1", 10, "pending"), threshold: 1000, wantValid: []string{"TX1"}},
+		{name: "undecided", transaction: transaction("TX1", 5000, "pending"), threshold: 1000, wantAwaiting: []string{"TX1"}},
+		{name: "at threshold", transaction: transaction("TX1", 1000, "pending"), threshold: 1000, wantAwaiting: []string{"TX1"}},
+		{
+			name: "resubmitted undecided", transaction: transaction("TX1", 5000, "awaiting_approval"), threshold: 1000,
+			approvals: map[string]bool{"TX2": true}, wantAwaiting: []string{"TX1"},
+		},
+		{
+			name: "approved", transaction: transaction("TX1", 5000, "awaiting_approval"), threshold: 1000,
+			approvals: map[string]bool{"TX1": true}, wantValid: []string{"TX1"},
+		},
+		{
+			name: "denied", transaction: transaction("TX1", 5000, "awaiting_approval"), threshold: 1000,
+			approvals: map[string]bool{"TX1": false}, wantInvalid: []string{"TX1"},
+		},
+		{name: "invalid before approval", transaction: transaction("TX1", -5000, "pending"), threshold: 1000, wantInvalid: []string{"TX1"}},
+	}
+	ids := func(transactions []models.Transaction) []string {
+		var result []string
+		for _, transaction := range transactions {
+			result = append(result, transaction.ID)
+		}
+		return result
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.ApprovalThreshold = tt.threshold
+			config.Approvals = tt.approvals
+
+			valid, invalid, awaiting := ValidateTransactionsWithConfig([]models.Transaction{tt.transaction}, accounts, config)
+			if got := ids(valid); !reflect.DeepEqual(got, tt.wantValid) {
+				t.Errorf("valid = %v, want %v", got, tt.wantValid)
+			}
+			if got := ids(invalid); !reflect.DeepEqual(got, tt.wantInvalid) {
+				t.Errorf("invalid = %v, want %v", got, tt.wantInvalid)
+			}
+			if got := ids(awaiting); !reflect.DeepEqual(got, tt.wantAwaiting) {
+				t.Errorf("awaiting approval = %v, want %v", got, tt.wantAwaiting)
+			}
+			for _, transaction := range awaiting {
+				if transaction.Status != "awaiting_approval" {
+					t.Errorf("%s awaiting approval has status %q", transaction.ID, transaction.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTransactionsStalePending(t *testing.T) {
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}}
+	processDate := time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 4, 16, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		timestamp   time.Time
+		processDate time.Time
+		policy      string
+		wantStatus  string
+		wantMessage string
+	}{
+		{name: "same day", timestamp: today, processDate: processDate, policy: StalePendingExpire, wantStatus: "pending"},
+		{name: "stale processed", timestamp: yesterday, processDate: processDate, policy: StalePendingProcess,
+			wantStatus: "pending", wantMessage: "Stale pending transaction from 2025-04-15 carried over"},
+		{name: "stale expired", timestamp: yesterday, processDate: processDate, policy: StalePendingExpire,
+			wantStatus: "expired", wantMessage: "Stale pending transaction from 2025-04-15 expired"},
+		{name: "no processing date", timestamp: yesterday, policy: StalePendingExpire, wantStatus: "pending"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.ProcessDate = tt.processDate
+			config.StalePending = tt.policy
+			transaction := models.Transaction{ID: "TX1", AccountID: "ACC1", Timestamp: tt.timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending"}
+
+			valid, invalid, _ := ValidateTransactionsWithConfig([]models.Transaction{transaction}, accounts, config)
+			if wantValid := tt.wantStatus == "pending"; (len(valid) == 1) != wantValid {
+				t.Fatalf("valid = %v, invalid = %v, want valid %v", valid, invalid, wantValid)
+			}
+			got := append(valid, invalid...)[0]
+			if got.Status != tt.wantStatus || got.ValidationMessage != tt.wantMessage {
+				t.Errorf("got %s %q, want %s %q", got.Status, got.ValidationMessage, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestBusinessTimeZoneDayBoundary(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}}
+	lateEvening := time.Date(2025, 4, 15, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		location   *time.Location
+		wantDay    string
+		wantStatus string // The stale-pending outcome on 2025-04-16 in the business zone
+	}{
+		{name: "UTC", location: time.UTC, wantDay: "2025-04-15", wantStatus: "expired"},
+		{name: "UTC+9", location: tokyo, wantDay: "2025-04-16", wantStatus: "pending"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := []models.Transaction{{ID: "TX1", AccountID: "ACC1", Timestamp: lateEvening, Amount: models.Cents(10),
+				Type: "debit", Status: "pending"}}
+			NormalizeTimestamps(transactions, tt.location)
+			if got := transactions[0].Timestamp.Format("2006-01-02"); got != tt.wantDay {
+				t.Errorf("business day = %s, want %s", got, tt.wantDay)
+			}
+			if !transactions[0].Timestamp.Equal(lateEvening) {
+				t.Errorf("normalizing moved the instant to %s", transactions[0].Timestamp)
+			}
+
+			config := DefaultValidationConfig()
+			config.ProcessDate = time.Date(2025, 4, 16, 0, 0, 0, 0, tt.location)
+			config.StalePending = StalePendingExpire
+			valid, invalid, _ := ValidateTransactionsWithConfig(transactions, accounts, config)
+			if got := append(valid, invalid...)[0].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateTransactionsHeldOver(t *testing.T) {
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}}
+	yesterday := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		policy      string
+		heldOver    map[string]bool
+		wantValid   bool
+		wantMessage string
+	}{
+		{name: "not expired", policy: StalePendingExpire, heldOver: map[string]bool{"TX1": true}, wantValid: true},
+		{name: "not flagged", policy: StalePendingProcess, heldOver: map[string]bool{"TX1": true}, wantValid: true},
+		{name: "other transaction held over", policy: StalePendingExpire, heldOver: map[string]bool{"TX2": true},
+			wantMessage: "Stale pending transaction from 2025-04-15 expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.ProcessDate = time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)
+			config.StalePending = tt.policy
+			config.HeldOver = tt.heldOver
+			transaction := models.Transaction{ID: "TX1", AccountID: "ACC1", Timestamp: yesterday, Amount: models.Cents(10), Type: "debit", Status: "pending"}
+
+			valid, invalid, _ := ValidateTransactionsWithConfig([]models.Transaction{transaction}, accounts, config)
+			if (len(valid) == 1) != tt.wantValid {
+				t.Fatalf("valid = %v, invalid = %v, want valid %v", valid, invalid, tt.wantValid)
+			}
+			if got := append(valid, invalid...)[0]; got.ValidationMessage != tt.wantMessage {
+				t.Errorf("validation message = %q, want %q", got.ValidationMessage, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestValidateTransactionsFrozenAccounts(t *testing.T) {
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}, "ACC2": {ID: "ACC2"}, "ACC3": {ID: "ACC3"}}
+	timestamp := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+	transfer := func(id, from, to string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: from, DestinationAccountID: to, Timestamp: timestamp,
+			Amount: models.Cents(10), Type: "transfer", Status: "pending"}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		wantInvalid  []string
+	}{
+		{name: "transfer to frozen destination", transactions: []models.Transaction{transfer("TX1", "ACC1", "ACC2"), transfer("TX2", "ACC1", "ACC3")},
+			wantInvalid: []string{"TX1"}},
+		{name: "transfer from frozen source", transactions: []models.Transaction{transfer("TX1", "ACC2", "ACC1")}, wantInvalid: []string{"TX1"}},
+		{name: "debit on frozen account", transactions: []models.Transaction{
+			{ID: "TX1", AccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending"}},
+			wantInvalid: []string{"TX1"}},
+		{name: "unrelated transfer", transactions: []models.Transaction{transfer("TX1", "ACC1", "ACC3")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.FrozenAccounts = map[string]bool{"ACC2": true}
+
+			valid, invalid, _ := ValidateTransactionsWithConfig(tt.transactions, accounts, config)
+			var gotInvalid []string
+			for _, transaction := range invalid {
+				gotInvalid = append(gotInvalid, transaction.ID)
+				if transaction.ValidationMessage != "Account frozen" {
+					t.Errorf("%s validation message = %q, want %q", transaction.ID, transaction.ValidationMessage, "Account frozen")
+				}
+			}
+			if !reflect.DeepEqual(gotInvalid, tt.wantInvalid) {
+				t.Errorf("invalid = %v, want %v", gotInvalid, tt.wantInvalid)
+			}
+			if len(valid)+len(invalid) != len(tt.transactions) {
+				t.Errorf("%d valid and %d invalid of %d transactions", len(valid), len(invalid), len(tt.transactions))
+			}
+		})
+	}
+}
+
+func TestValidateTransactionsDuplicateIDs(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending"},
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp.Add(time.Minute), Amount: models.Cents(20), Type: "debit", Status: "pending"},
+		{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(2 * time.Minute), Amount: models.Cents(5), Type: "credit", Status: "pending"},
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp.Add(3 * time.Minute), Amount: models.Cents(30), Type: "debit", Status: "pending"},
+	}
+
+	valid, invalid := ValidateTransactions(transactions, accounts)
+	if len(valid) != 2 || valid[0].Amount != models.Cents(10) || valid[1].ID != "TX2" {
+		t.Fatalf("valid = %+v, want the first TX1 and TX2", valid)
+	}
+	if len(invalid) != 2 {
+		t.Fatalf("invalid = %+v, want the two repeats of TX1", invalid)
+	}
+	for _, transaction := range invalid {
+		if transaction.ValidationMessage != "Duplicate transaction ID TX1" {
+			t.Errorf("repeat of TX1 for %s has message %q", transaction.Amount, transaction.ValidationMessage)
+		}
+	}
+
+	updated, processed := processor.ProcessTransactions(valid, accounts)
+	if len(processed) != 2 || updated["ACC1"].Balance != models.Cents(95) {
+		t.Errorf("processing the valid transactions left ACC1 at %s, want 95.00", updated["ACC1"].Balance)
+	}
+}
+
+func TestNormalizeAccountIDs(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		normalizer models.IDNormalizer
+		accountID  string
+		wantValid  bool
+	}{
+		{name: "exact match", accountID: "ACC1", wantValid: true},
+		{name: "whitespace without normalization", accountID: " ACC1 "},
+		{name: "whitespace trimmed", normalizer: models.IDNormalizer{Trim: true}, accountID: " ACC1 ", wantValid: true},
+		{name: "case without normalization", accountID: "acc1"},
+		{name: "case and whitespace normalized", normalizer: models.IDNormalizer{Trim: true, Case: "upper"}, accountID: "\tacc1 ", wantValid: true},
+		{name: "lowercased on both sides", normalizer: models.IDNormalizer{Case: "lower"}, accountID: "Acc1", wantValid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := processor.NormalizeAccountIDs(map[string]models.Account{"ACC1": {ID: "ACC1"}, "ACC2": {ID: "ACC2"}}, tt.normalizer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The account appears as both the source of a credit and the destination of a transfer
+			transactions := []models.Transaction{
+				{ID: "TX1", AccountID: tt.accountID, Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "pending"},
+				{ID: "TX2", AccountID: "ACC2", DestinationAccountID: tt.accountID, Timestamp: timestamp, Amount: models.Cents(10),
+					Type: "transfer", Status: "pending"},
+			}
+			NormalizeAccountIDs(transactions, tt.normalizer)
+
+			valid, invalid := ValidateTransactions(transactions, accounts)
+			if got := len(valid) == len(transactions); got != tt.wantValid {
+				t.Errorf("valid = %v, want %v (%+v)", got, tt.wantValid, invalid)
+			}
+		})
+	}
+
+	if _, err := processor.NormalizeAccountIDs(map[string]models.Account{"ACC1": {ID: "ACC1"}, "acc1": {ID: "acc1"}},
+		models.IDNormalizer{Case: "upper"}); err == nil {
+		t.Error("accounts normalizing to the same ID were accepted")
+	}
+}
+
+func TestValidateTransactionsSwappedColumns(t *testing.T) {
+	accounts := map[string]models.Account{"ACC1": {ID: "ACC1"}, "TX9": {ID: "TX9"}}
+	timestamp := time.Date(2025, 4, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		id          string
+		accountID   string
+		wantMessage string // "" for valid
+	}{
+		{name: "swapped columns", id: "ACC1", accountID: "TX1000001",
+			wantMessage: "Data quality: transaction ID ACC1 matches a known account ID (columns may be swapped)"},
+		{name: "account ID shaped like a transaction ID", id: "X1", accountID: "TX9",
+			wantMessage: "Data quality: account ID TX9 looks like a transaction ID (columns may be swapped)"},
+		{name: "ordinary record", id: "TX1000001", accountID: "ACC1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.TransactionIDPatterns = []*regexp.Regexp{regexp.MustCompile(`^TX\d+$`)}
+			transactions := []models.Transaction{{ID: tt.id, AccountID: tt.accountID, Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "pending"}}
+
+			valid, invalid, _ := ValidateTransactionsWithConfig(transactions, accounts, config)
+			if tt.wantMessage == "" {
+				if len(valid) != 1 {
+					t.Errorf("invalid = %+v, want the record valid", invalid)
+				}
+				return
+			}
+			if len(invalid) != 1 || invalid[0].ValidationMessage != tt.wantMessage {
+				t.Errorf("invalid = %+v, want one with %q", invalid, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestLoadersRejectNonFiniteAmounts(t *testing.T) {
+	for _, amount := range []string{"NaN", "nan", "Inf", "+Inf", "-Inf", "Infinity"} {
+		t.Run(amount, func(t *testing.T) {
+			transactionsPath := writeTestFile(t, "transactions.csv", "transaction_id,account_id,timestamp,amount,transaction_type,status\n"+
+				"TX1,ACC1,2025-04-15T09:00:00Z,"+amount+",credit,pending\n"+
+				"TX2,ACC1,2025-04-15T09:05:00Z,10.00,credit,pending\n")
+			transactions, err := LoadTransactions(transactionsPath)
+			var parseErrors models.ParseErrors
+			if !errors.As(err, &parseErrors) || len(parseErrors) != 1 {
+				t.Fatalf("LoadTransactions() error = %v, want one parse error", err)
+			}
+			if parseErrors[0].Field != "amount" || !errors.Is(parseErrors[0], models.ErrNonFiniteAmount) {
+				t.Errorf("parse error = %v, want a non-finite amount", parseErrors[0])
+			}
+			if len(transactions) != 1 || transactions[0].ID != "TX2" {
+				t.Errorf("loaded %+v, want only TX2", transactions)
+			}
+
+			accountsPath := writeTestFile(t, "accounts.csv", "account_id,balance\nACC1,"+amount+"\n")
+			if _, err := processor.LoadAccounts(accountsPath); !errors.Is(err, models.ErrNonFiniteAmount) {
+				t.Errorf("LoadAccounts() error = %v, want a non-finite amount", err)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	layouts := []string{time.RFC3339, "2006-01-02 15:04:05", EpochSeconds}
+	want := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "rfc3339", value: "2025-04-15T09:30:00Z", want: want},
+		{name: "space-separated datetime", value: "2025-04-15 09:30:00", want: want},
+		{name: "unix epoch", value: "1744709400", want: want},
+		{name: "malformed", value: "15/04/2025 9.30", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value, layouts)
+			if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) = %v, %v, want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// The loader rejects records matching none of its layouts and keeps the rest
+	path := writeTestFile(t, "transactions.csv", "transaction_id,account_id,timestamp,amount,transaction_type,status\n"+
+		"TX1,ACC1,2025-04-15 09:30:00,10.00,credit,pending\n"+
+		"TX2,ACC1,1744709400,10.00,credit,pending\n"+
+		"TX3,ACC1,yesterday,10.00,credit,pending\n")
+	transactions, err := LoadTransactionsWithConfig(path, LoaderConfig{TimestampLayouts: layouts})
+	var parseErrors models.ParseErrors
+	if !errors.As(err, &parseErrors) || len(parseErrors) != 1 || parseErrors[0].Field != "timestamp" {
+		t.Fatalf("LoadTransactionsWithConfig() error = %v, want one timestamp parse error", err)
+	}
+	if len(transactions) != 2 || !transactions[0].Timestamp.Equal(want) || !transactions[1].Timestamp.Equal(want) {
+		t.Errorf("loaded %+v, want TX1 and TX2 at %v", transactions, want)
+	}
+}
+
+func TestLoadTransactionsReadsGivenPath(t *testing.T) {
+	paths := map[string]string{
+		"2025-04-15": writeTestFile(t, "transactions_2025-04-15.csv", testTransactionsHeader+
+			"TX1,ACC1,2025-04-15T09:00:00Z,10.00,debit,pending,Coffee,\n"),
+		"2025-04-16": writeTestFile(t, "transactions_2025-04-16.csv", testTransactionsHeader+
+			"TX2,ACC2,2025-04-16T09:00:00Z,20.00,credit,pending,Salary,\n"),
+	}
+	tests := []struct {
+		date   string
+		wantID string
+	}{
+		{date: "2025-04-15", wantID: "TX1"},
+		{date: "2025-04-16", wantID: "TX2"},
+	}
+	loaded := make(map[string][]models.Transaction)
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			transactions, err := LoadTransactions(paths[tt.date])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(transactions) != 1 || transactions[0].ID != tt.wantID {
+				t.Fatalf("loaded %+v, want only %s", transactions, tt.wantID)
+			}
+			loaded[tt.date] = transactions
+		})
+	}
+	if reflect.DeepEqual(loaded["2025-04-15"], loaded["2025-04-16"]) {
+		t.Error("two different files loaded the same transactions")
+	}
+
+	missing := filepath.Join(t.TempDir(), "transactions_2025-04-17.csv")
+	_, err := LoadTransactions(missing)
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), missing) {
+		t.Errorf("loading a missing file returned %v, want a not-exist error naming %s", err, missing)
+	}
+}
+
+func TestLoadersMapColumnsByName(t *testing.T) {
+	transactions, err := LoadTransactions(writeTestFile(t, "transactions.csv",
+		"Amount,status,transaction_type,timestamp,account_id,transaction_id\n12.50,pending,debit,2025-04-15T09:00:00Z,ACC1,TX1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.Transaction{ID: "TX1", AccountID: "ACC1", Timestamp: time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC),
+		Amount: models.Cents(12.5), Type: "debit", Status: "pending"}
+	if len(transactions) != 1 || !reflect.DeepEqual(transactions[0], want) {
+		t.Errorf("reordered transactions parsed as %+v, want %+v", transactions, want)
+	}
+
+	accounts, err := processor.LoadAccounts(writeTestFile(t, "accounts.csv", "currency,balance,account_id\nEUR,250.75,ACC1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account := accounts["ACC1"]; account.Balance != models.Cents(250.75) || account.Currency != "EUR" {
+		t.Errorf("reordered account parsed as %+v, want balance 250.75 in EUR", account)
+	}
+
+	tests := []struct {
+		name    string
+		load    func(path string) error
+		content string
+		wantErr string
+	}{
+		{
+			name:    "transactions without amount",
+			load:    func(path string) error { _, err := LoadTransactions(path); return err },
+			content: "transaction_id,account_id,timestamp,transaction_type,status\nTX1,ACC1,2025-04-15T09:00:00Z,debit,pending\n",
+			wantErr: `invalid transactions file header: missing required column "amount"`,
+		},
+		{
+			name:    "accounts without balance",
+			load:    func(path string) error { _, err := processor.LoadAccounts(path); return err },
+			content: "account_id,currency\nACC1,EUR\n",
+			wantErr: `invalid accounts file header: missing required column "balance"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.load(writeTestFile(t, "input.csv", tt.content)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGzippedInputsLoadLikeUncompressed(t *testing.T) {
+	transactionsCSV := testTransactionsHeader + transactionLines(25)
+	accountsCSV := "account_id,balance,currency\nACC1,100.00,USD\nACC2,-20.50,EUR\n"
+	dir := t.TempDir()
+	// writeGzip writes content compressed to a .gz file in the test directory
+	writeGzip := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		writer, err := gzipio.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	plainTransactions, err := LoadTransactions(writeTestFile(t, "transactions.csv", transactionsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzippedTransactions, err := LoadTransactions(writeGzip("transactions.csv.gz", transactionsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plainTransactions) != 25 || !reflect.DeepEqual(gzippedTransactions, plainTransactions) {
+		t.Errorf("gzipped file loaded %d transactions differing from the %d uncompressed", len(gzippedTransactions), len(plainTransactions))
+	}
+
+	plainAccounts, err := processor.LoadAccounts(writeTestFile(t, "accounts.csv", accountsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzippedAccounts, err := processor.LoadAccounts(writeGzip("accounts.csv.gz", accountsCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plainAccounts) != 2 || !reflect.DeepEqual(gzippedAccounts, plainAccounts) {
+		t.Errorf("gzipped accounts = %+v, want %+v", gzippedAccounts, plainAccounts)
+	}
+}
@@ -0,0 +1,162 @@
+// ingestion/parallel_load.go
+package ingestion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// LoadTransactionsParallel loads transaction data from a CSV file by splitting it into
+// byte-range chunks aligned to line boundaries and parsing the chunks concurrently.
+// The result preserves the original line order. Records must not contain quoted
+// newlines, since chunk boundaries are placed at line breaks. Records that fail to parse
+// are reported as models.ParseErrors, as LoadTransactionsWithConfig does. Gzip-compressed
+// files cannot be split and are read serially.
+func LoadTransactionsParallel(filePath string, chunks int) ([]models.Transaction, error) {
+	return LoadTransactionsParallelWithConfig(filePath, chunks, DefaultLoaderConfig())
+}
+
+// LoadTransactionsParallelWithConfig loads transaction data in parallel using the supplied parsing options
+func LoadTransactionsParallelWithConfig(filePath string, chunks int, config LoaderConfig) ([]models.Transaction, error) {
+	if gzipio.IsGzip(filePath) {
+		return LoadTransactionsWithConfig(filePath, config)
+	}
+	if chunks < 1 {
+		chunks = 1
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening transactions file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading transactions file info: %w", err)
+	}
+	size := info.Size()
+
+	// Read the header to find where the data rows start
+	headerReader := csv.NewReader(io.NewSectionReader(file, 0, size))
+	header, err := headerReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("transaction file is empty or missing data rows")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	columns, err := models.MapColumns(header, transactionColumns, requiredTransactionColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transactions file header: %w", err)
+	}
+	dataStart := headerReader.InputOffset()
+
+	// Compute chunk boundaries aligned to the start of a line
+	boundaries := []int64{dataStart}
+	chunkSize := (size - dataStart) / int64(chunks)
+	for i := 1; i < chunks; i++ {
+		offset, err := alignToLine(file, dataStart+int64(i)*chunkSize, size)
+		if err != nil {
+			return nil, err
+		}
+		if offset > boundaries[len(boundaries)-1] && offset < size {
+			boundaries = append(boundaries, offset)
+		}
+	}
+	boundaries = append(boundaries, size)
+
+	// Read the raw records of each chunk concurrently
+	chunkRecords := make([][][]string, len(boundaries)-1)
+	chunkErrors := make([]error, len(boundaries)-1)
+	var wg sync.WaitGroup
+	for i := 0; i < len(boundaries)-1; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reader := csv.NewReader(io.NewSectionReader(file, boundaries[i], boundaries[i+1]-boundaries[i]))
+			reader.FieldsPerRecord = -1
+			chunkRecords[i], chunkErrors[i] = reader.ReadAll()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range chunkErrors {
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+	}
+
+	// Determine the line number each chunk starts at
+	startLines := make([]int, len(chunkRecords))
+	total := 0
+	for i, records := range chunkRecords {
+		startLines[i] = total + 2
+		total += len(records)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("transaction file is empty or missing data rows")
+	}
+
+	// Parse each chunk concurrently
+	chunkTransactions := make([][]models.Transaction, len(chunkRecords))
+	chunkParseErrors := make([]models.ParseErrors, len(chunkRecords))
+	for i, records := range chunkRecords {
+		wg.Add(1)
+		go func(i int, records [][]string) {
+			defer wg.Done()
+			chunkTransactions[i] = make([]models.Transaction, 0, len(records))
+			for j, record := range records {
+				transaction, err := parseTransaction(columns.Reorder(record), startLines[i]+j, config)
+				if err != nil {
+					err.Record = record // As it appears in the file, not reordered
+					chunkParseErrors[i] = append(chunkParseErrors[i], err)
+					continue
+				}
+				chunkTransactions[i] = append(chunkTransactions[i], transaction)
+			}
+		}(i, records)
+	}
+	wg.Wait()
+
+	// Join the chunks in file order
+	transactions := make([]models.Transaction, 0, total)
+	var parseErrors models.ParseErrors
+	for i := range chunkRecords {
+		transactions = append(transactions, chunkTransactions[i]...)
+		parseErrors = append(parseErrors, chunkParseErrors[i]...)
+	}
+
+	if len(parseErrors) > 0 {
+		return transactions, parseErrors
+	}
+	return transactions, nil
+}
+
+// alignToLine returns the offset of the first line starting at or after offset
+func alignToLine(file *os.File, offset int64, limit int64) (int64, error) {
+	buf := make([]byte, 4096)
+	position := offset - 1
+	for position < limit {
+		n, err := file.ReadAt(buf, position)
+		if index := bytes.IndexByte(buf[:n], '\n'); index >= 0 {
+			return position + int64(index) + 1, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return limit, nil
+			}
+			return 0, fmt.Errorf("error reading transactions file: %w", err)
+		}
+		position += int64(n)
+	}
+	return limit, nil
+}
@@ -0,0 +1,177 @@
+// ingestion/parallel_load_test.go
+package ingestion
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/output"
+)
+
+const testTransactionsHeader = "transaction_id,account_id,timestamp,amount,transaction_type,status,description,destination_account_id\n"
+
+// writeTestFile writes content to a file in a test's temporary directory and returns its path
+func writeTestFile(t testing.TB, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return path
+}
+
+// transactionLines returns count transaction records, one per line
+func transactionLines(count int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&b, "TX%06d,ACC%03d,2025-04-15T09:%02d:%02dZ,%d.%02d,debit,pending,Purchase %d,\n",
+			i, i%50, i/60%60, i%60, i%900+1, i%100, i)
+	}
+	return b.String()
+}
+
+// lineNumbers returns the line number of each parse error in an error returned by a loader
+func lineNumbers(err error) []int {
+	var parseErrors models.ParseErrors
+	if !errors.As(err, &parseErrors) {
+		return nil
+	}
+	lines := make([]int, len(parseErrors))
+	for i, parseErr := range parseErrors {
+		lines[i] = parseErr.LineNumber
+	}
+	return lines
+}
+
+func TestLoadTransactionsParallelMatchesSerial(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLines []int // Lines reported as unparseable
+	}{
+		{name: "one record", content: testTransactionsHeader + transactionLines(1)},
+		{name: "many records", content: testTransactionsHeader + transactionLines(1000)},
+		{name: "no trailing newline", content: testTransactionsHeader + strings.TrimSuffix(transactionLines(37), "\n")},
+		{name: "long records", content: testTransactionsHeader + strings.ReplaceAll(transactionLines(200), "Purchase", strings.Repeat("x", 5000))},
+		{
+			name:    "reordered columns",
+			content: "status,transaction_type,amount,timestamp,account_id,transaction_id\npending,credit,10.00,2025-04-15T09:00:00Z,ACC1,TX1\npending,debit,2.50,2025-04-15T09:01:00Z,ACC2,TX2\n",
+		},
+		{
+			name:      "malformed records",
+			content:   testTransactionsHeader + transactionLines(20) + "TXBAD1,ACC1,not-a-time,1.00,debit,pending,,\n" + transactionLines(20) + "TXBAD2,ACC1,2025-04-15T09:00:00Z,1.00,bogus,pending,,\n",
+			wantLines: []int{22, 43},
+		},
+	}
+	for _, tt := range tests {
+		path := writeTestFile(t, "transactions.csv", tt.content)
+		want, wantErr := LoadTransactions(path)
+		if got := lineNumbers(wantErr); !reflect.DeepEqual(got, tt.wantLines) {
+			t.Fatalf("%s: serial load reported lines %v, want %v (error %v)", tt.name, got, tt.wantLines, wantErr)
+		}
+
+		for _, chunks := range []int{1, 2, 3, 8, 64} {
+			t.Run(fmt.Sprintf("%s/%d chunks", tt.name, chunks), func(t *testing.T) {
+				got, err := LoadTransactionsParallel(path, chunks)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("parallel load returned %d transactions differing from the %d loaded serially", len(got), len(want))
+				}
+				if lines := lineNumbers(err); !reflect.DeepEqual(lines, tt.wantLines) {
+					t.Errorf("parallel load reported lines %v, want %v (error %v)", lines, tt.wantLines, err)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadTransactionsParallelHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty file", content: "", wantErr: "empty"},
+		{name: "header only", content: testTransactionsHeader, wantErr: "empty"},
+		{name: "missing column", content: "transaction_id,account_id,timestamp,amount,transaction_type\nTX1,ACC1,2025-04-15T09:00:00Z,1.00,debit\n", wantErr: `missing required column "status"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, "transactions.csv", tt.content)
+			_, err := LoadTransactionsParallel(path, 4)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseErrorsKeepRawRecord(t *testing.T) {
+	// Reordered columns, one the loader doesn't know, and a quoted field holding a comma
+	content := "status,branch,transaction_type,amount,timestamp,account_id,transaction_id,description\n" +
+		"pending,north,debit,not-an-amount,2025-04-15T09:00:00Z,ACC1,TX1,\"Coffee, large\"\n"
+	want := []string{"pending", "north", "debit", "not-an-amount", "2025-04-15T09:00:00Z", "ACC1", "TX1", "Coffee, large"}
+	path := writeTestFile(t, "transactions.csv", content)
+
+	loaders := map[string]func() ([]models.Transaction, error){
+		"serial":   func() ([]models.Transaction, error) { return LoadTransactions(path) },
+		"parallel": func() ([]models.Transaction, error) { return LoadTransactionsParallel(path, 2) },
+	}
+	for name, load := range loaders {
+		t.Run(name, func(t *testing.T) {
+			_, err := load()
+			var parseErrors models.ParseErrors
+			if !errors.As(err, &parseErrors) || len(parseErrors) != 1 {
+				t.Fatalf("error = %v, want one parse error", err)
+			}
+			if got := parseErrors[0].Record; !reflect.DeepEqual(got, want) {
+				t.Errorf("Record = %q, want the raw %q", got, want)
+			}
+
+			// The ingestion errors file holds the record as the CSV line it was read from
+			errorsPath := filepath.Join(t.TempDir(), "ingestion_errors.csv")
+			if err := output.WriteIngestionErrors(parseErrors, errorsPath); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(errorsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			rows, err := csv.NewReader(file).ReadAll()
+			if err != nil || len(rows) != 2 {
+				t.Fatalf("ingestion errors file has rows %q (%v), want a header and one row", rows, err)
+			}
+			written, err := csv.NewReader(strings.NewReader(rows[1][3])).Read()
+			if err != nil || !reflect.DeepEqual(written, want) {
+				t.Errorf("written record %q parses as %q (%v), want %q", rows[1][3], written, err, want)
+			}
+		})
+	}
+}
+
+func benchmarkLoad(b *testing.B, load func(path string) ([]models.Transaction, error)) {
+	path := writeTestFile(b, "transactions.csv", testTransactionsHeader+transactionLines(200000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := load(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadTransactionsSerial(b *testing.B) {
+	benchmarkLoad(b, LoadTransactions)
+}
+
+func BenchmarkLoadTransactionsParallel(b *testing.B) {
+	benchmarkLoad(b, func(path string) ([]models.Transaction, error) {
+		return LoadTransactionsParallel(path, 4)
+	})
+}
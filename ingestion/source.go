@@ -0,0 +1,41 @@
+// ingestion/source.go
+package ingestion
+
+import (
+	"os"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// FileTransactionSource is the default TransactionSource. It reads a CSV file, split across
+// Chunks concurrent readers when above 1, or newline-delimited JSON when the path names one.
+type FileTransactionSource struct {
+	Path     string
+	HeldPath string // Held transactions file the previous batch wrote; empty when holds are off
+	Chunks   int
+	Config   LoaderConfig
+}
+
+// LoadTransactions reads the file, returning models.ParseErrors alongside the transactions
+// that parsed when some records are malformed
+func (s FileTransactionSource) LoadTransactions() ([]models.Transaction, error) {
+	if IsJSONLines(s.Path) {
+		return LoadTransactionsJSON(s.Path)
+	}
+	if s.Chunks > 1 {
+		return LoadTransactionsParallelWithConfig(s.Path, s.Chunks, s.Config)
+	}
+	return LoadTransactionsWithConfig(s.Path, s.Config)
+}
+
+// LoadHeldTransactions reads the previous batch's held transactions file, returning none when
+// there is no such file
+func (s FileTransactionSource) LoadHeldTransactions() ([]models.Transaction, error) {
+	if s.HeldPath == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(s.HeldPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadProcessedTransactions(s.HeldPath)
+}
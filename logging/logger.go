@@ -0,0 +1,90 @@
+// Package logging adds severity levels on top of the standard logger
+// logging/logger.go
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Level is the severity of a log message
+type Level int
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+// minLevel is the least severe level that is written
+var minLevel = LevelInfo
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN"
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q: must be debug, info, warn, or error", name)
+}
+
+// SetLevel sets the least severe level that is written
+func SetLevel(level Level) {
+	minLevel = level
+}
+
+// Enabled reports whether messages at the level are written
+func Enabled(level Level) bool {
+	return level >= minLevel
+}
+
+// Logf writes a message at the given level through the standard logger, so the
+// destination set with log.SetOutput applies
+func Logf(level Level, format string, args ...any) {
+	if !Enabled(level) {
+		return
+	}
+	log.Printf(level.String()+" "+format, args...)
+}
+
+// Debugf logs detail useful when investigating individual records
+func Debugf(format string, args ...any) {
+	Logf(LevelDebug, format, args...)
+}
+
+// Infof logs progress and batch summaries
+func Infof(format string, args ...any) {
+	Logf(LevelInfo, format, args...)
+}
+
+// Warnf logs problems the batch continues past
+func Warnf(format string, args ...any) {
+	Logf(LevelWarn, format, args...)
+}
+
+// Errorf logs failures
+func Errorf(format string, args ...any) {
+	Logf(LevelError, format, args...)
+}
+
+// Fatalf logs a failure at ERROR regardless of the level and exits
+func Fatalf(format string, args ...any) {
+	log.Printf(LevelError.String()+" "+format, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,54 @@
+// logging/logger_test.go
+package logging
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	savedOutput, savedFlags, savedLevel := log.Writer(), log.Flags(), minLevel
+	defer func() {
+		log.SetOutput(savedOutput)
+		log.SetFlags(savedFlags)
+		SetLevel(savedLevel)
+	}()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+
+	tests := []struct {
+		level string
+		want  []string // Lines written by one message at each level
+	}{
+		{level: "debug", want: []string{"DEBUG rejected TX1", "INFO processed 2", "WARN skipped 1", "ERROR failed"}},
+		{level: "info", want: []string{"INFO processed 2", "WARN skipped 1", "ERROR failed"}},
+		{level: "WARN", want: []string{"WARN skipped 1", "ERROR failed"}},
+		{level: "error", want: []string{"ERROR failed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			level, err := ParseLevel(tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			SetLevel(level)
+			buf.Reset()
+
+			Debugf("rejected %s", "TX1")
+			Infof("processed %d", 2)
+			Warnf("skipped %d", 1)
+			Errorf("failed")
+			if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logged %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("an unknown level parsed")
+	}
+}
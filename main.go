@@ -2,32 +2,166 @@
 package main
 
 import (
+	"DailyTransactionBatchProcessing/alerting"
+	"DailyTransactionBatchProcessing/calendar"
 	"DailyTransactionBatchProcessing/detector"
 	"DailyTransactionBatchProcessing/ingestion"
+	"DailyTransactionBatchProcessing/logging"
+	"DailyTransactionBatchProcessing/models"
 	"DailyTransactionBatchProcessing/output"
 	"DailyTransactionBatchProcessing/processor"
 
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
 	"time"
 )
 
+// Input file date check modes
+const (
+	dateCheckOff   = "off"
+	dateCheckWarn  = "warn"
+	dateCheckAbort = "abort"
+)
+
+// now is the pipeline's clock; every time-dependent default reads it so a run can be pinned with -now
+var now = time.Now
+
+// Where the batch reads its inputs and prior-run state and writes its outputs; tests replace
+// these with in-memory doubles
+var (
+	newTransactionSource = func(path string, heldPath string, chunks int, config ingestion.LoaderConfig) models.TransactionSource {
+		return ingestion.FileTransactionSource{Path: path, HeldPath: heldPath, Chunks: chunks, Config: config}
+	}
+	newAccountStore = func(path string, fallbackPath string, savePath string) models.AccountStore {
+		return output.FileAccountStore{Path: path, FallbackPath: fallbackPath, SavePath: savePath}
+	}
+	reportSink models.ReportSink = output.FileSink{}
+)
+
 func main() {
+	// Measure the run's wall-clock duration, unaffected by a pinned -now
+	startTime := time.Now()
+
 	// Parse command line arguments
-	dateFlag := flag.String("date", "", "Processing date in YYYY-MM-DD format (defaults to yesterday)")
+	dateFlag := flag.String("date", "", "Processing date in YYYY-MM-DD format (defaults to the transactions file date, then the previous business day)")
 	inputDirFlag := flag.String("input", "./data", "Directory containing transaction data files")
+	transactionsFlag := flag.String("transactions", "", "Transactions file path, read as newline-delimited JSON when it ends in .json, .jsonl or .ndjson and decompressed when it ends in .gz (defaults to transactions_<date>.csv in the input directory)")
 	outputDirFlag := flag.String("output", "./output", "Directory for output files")
 	logFileFlag := flag.String("log", "", "Log file path (defaults to stdout)")
+	logLevelFlag := flag.String("log-level", "info", "Least severe log level written (debug|info|warn|error)")
+	workersFlag := flag.Int("workers", runtime.GOMAXPROCS(0), "Parallelism for loading, processing, detection, and output (1 is fully serial)")
+	readChunksFlag := flag.Int("read-chunks", 0, "Number of chunks to parse the transactions file in parallel (defaults to -workers)")
+	coolingOffFlag := flag.Float64("cooling-off-mins", 0, "Minimum minutes between large transactions on an account (0 disables)")
+	holdCoolingOffFlag := flag.Bool("hold-cooling-off", false, "Hold large transactions that violate the cooling-off period instead of only flagging them")
+	anonymizeFlag := flag.Bool("anonymize", false, "Replace account IDs in output files with salted tokens")
+	anonymizeSaltFlag := flag.String("anonymize-salt", "", "Salt for account ID tokens (defaults to a random salt per run)")
+	frozenAccountsFlag := flag.String("frozen-accounts", "", "Comma-separated account IDs frozen under investigation, added to those in -frozen")
+	excludedDestinationsFlag := flag.String("excluded-destinations", "", "Comma-separated account IDs excluded as transfer destinations")
+	positionLongFlag := flag.Float64("position-long-limit", 0, "Maximum net inflow per account per day before flagging (0 disables)")
+	positionShortFlag := flag.Float64("position-short-limit", 0, "Maximum net outflow per account per day before flagging (0 disables)")
+	falsePositivesFlag := flag.String("false-positives", "", "False-positive registry CSV used to suppress repeat alerts")
+	falsePositiveWindowFlag := flag.Int("fp-window-days", detector.DefaultConfig().FalsePositiveWindowDays, "Days a confirmed false positive suppresses matching alerts")
+	feesFlag := flag.String("fees", "", "Comma-separated transaction fees as type=fee, where a fee is a flat amount, a percentage, or both, e.g. debit=0.50,transfer=1+0.1%")
+	overdraftFeesFlag := flag.String("overdraft-fees", "", "Comma-separated overdraft fee tiers by overdraft count, e.g. 25,35 (empty disables)")
+	repeatOverdraftFlag := flag.Int("repeat-overdraft-threshold", detector.DefaultConfig().RepeatOverdraftThreshold, "Escalate overdraft anomalies for accounts overdrawn more than this many times (0 disables)")
+	verboseOverdraftsFlag := flag.Bool("verbose-overdrafts", false, "Report an overdraft anomaly for every transaction leaving an account overdrawn instead of one per account")
+	maxAnomaliesPerAccountFlag := flag.Int("max-anomalies-per-account", 0, "Maximum anomalies emitted per account, keeping the most severe (0 means no cap)")
+	overdraftFeeCapFlag := flag.Float64("overdraft-fee-cap", 0, "Maximum total overdraft fees per account per day (0 means no cap)")
+	overdraftFeeGraceFlag := flag.Int("overdraft-fee-grace-days", 0, "Days after an account opens before overdraft fees apply (0 disables)")
+	pluginsFlag := flag.String("plugins", "", "Comma-separated paths of Go plugins providing custom anomaly rules")
+	holidaysFlag := flag.String("holidays", "", "CSV file of bank holidays (YYYY-MM-DD in the first column)")
+	weekendFlag := flag.String("weekend", "Sat,Sun", "Comma-separated non-business weekdays")
+	overdraftLimitFlag := flag.Float64("overdraft-limit", models.DefaultOverdraftLimit, "Overdraft limit for accounts without an arranged overdraft")
+	dailyWithdrawalLimitFlag := flag.Float64("daily-withdrawal-limit", models.DefaultMaxDailyWithdrawalLimit, "Maximum total debits per account per day")
+	structuringBandFlag := flag.Float64("structuring-band", detector.DefaultConfig().StructuringBand, "Width of the band below the large transaction threshold in which repeated debits count as structuring (0 disables)")
+	structuringMinCountFlag := flag.Int("structuring-min-count", detector.DefaultConfig().StructuringMinCount, "Debits in the structuring band on one account in a day that trigger a structuring anomaly")
+	balanceDrainFlag := flag.Float64("balance-drain-fraction", 0.9, "Flag accounts that transfer out at least this share of their opening balance in a day (0 disables)")
+	largeCreditFlag := flag.Float64("large-credit-threshold", models.DefaultLargeTransactionThreshold, "Credits at or above this amount are flagged as large_credit")
+	largeDebitFlag := flag.Float64("large-debit-threshold", models.DefaultLargeTransactionThreshold, "Debits at or above this amount are flagged as large_debit")
+	largeTransactionFlag := flag.Float64("large-transaction-threshold", models.DefaultLargeTransactionThreshold, "Amount at or above which a transaction is considered large")
+	approvedOverdraftFlag := flag.Float64("approved-overdraft-limit", models.DefaultApprovedOverdraftLimit, "Overdraft limit for accounts with an arranged overdraft")
+	noOverdraftTypesFlag := flag.String("no-overdraft-types", "savings", "Comma-separated account types that may never go below zero, enforced in processing and flagged in detection")
+	trimIDsFlag := flag.Bool("trim-ids", false, "Trim whitespace from account IDs in all input files")
+	idCaseFlag := flag.String("id-case", "", "Normalize account ID case in all input files (upper|lower)")
+	backupFlag := flag.Bool("backup", false, "Back up existing output files for the date before overwriting them")
+	limitsFlag := flag.String("limits", "", "Per-account limits CSV (account_id,daily_withdrawal,daily_transfer,single_transaction)")
+	ratesFlag := flag.String("rates", "", "Exchange rates CSV (from_currency,to_currency,rate) for converting foreign-currency transactions")
+	conversionPairsFlag := flag.String("conversion-pairs", "", "Comma-separated currency pairs transfers may convert between, e.g. EUR/USD,USD/EUR")
+	reprocessFlag := flag.String("reprocess", "", "Prior run's processed transactions file to re-apply with the current -rates instead of ingesting transactions")
+	approvalThresholdFlag := flag.Float64("approval-threshold", 0, "Transactions at or above this amount require manual approval before posting (0 disables)")
+	frozenFlag := flag.String("frozen", "", "Frozen accounts CSV (account_id first); transactions from or to a frozen account are rejected in validation")
+	approvalsFlag := flag.String("approvals", "", "Approvals CSV (transaction_id,decision) with approved or denied decisions")
+	timestampLayoutsFlag := flag.String("timestamp-layouts", "", "Semicolon-separated Go time layouts tried in order for transaction timestamps; \"epoch\" accepts Unix seconds (defaults to RFC3339)")
+	holdInsufficientFlag := flag.Bool("hold-insufficient", false, "Hold transactions that would exceed the overdraft limit and retry them in the next batch instead of rejecting them")
+	autocreateFlag := flag.Bool("autocreate-on-credit", false, "Create a zero-balance account for a credit to an account missing from the accounts file")
+	highValueFlag := flag.Float64("high-value", 0, "Write a summary of accounts closing above this balance or with any anomaly (0 disables)")
+	reviewThresholdFlag := flag.Float64("review-threshold", 0, "Hold transactions above this amount for manual review and write them to a review file (0 disables)")
+	dryRunFlag := flag.Bool("dry-run", false, "Run every stage but only log the outputs that would be written")
+	strictFlag := flag.Bool("strict", false, "Verify processing invariants and abort if they are violated")
+	formatFlag := flag.String("format", output.FormatCSV, "Report format (csv|json); the accounts, account summary, and held transactions files stay CSV so the next run can read them")
+	sortOutputFlag := flag.String("sort-output", output.SortProcessing, "Order of the processed transactions file (time|account-time; defaults to processing order)")
+	txColumnsFlag := flag.String("tx-columns", "", "Comma-separated columns to write to the processed transactions CSV files, in order (defaults to all)")
+	aggregateBelowFlag := flag.Float64("aggregate-below", 0, "Roll completed transactions below this amount into one record per account and type (0 disables)")
+	aggregateByCounterpartyFlag := flag.Bool("aggregate-by-counterparty", false, "Also group aggregated transactions by destination account")
+	aggregateKeepDetailFlag := flag.Bool("aggregate-keep-detail", false, "Write the unaggregated processed transactions to a separate detail file")
+	filenameTemplateFlag := flag.String("filename-template", output.DefaultFileNameTemplate, "Go template for output filenames, given {{.Type}}, {{.Date}}, and {{.Ext}}")
+	filenameTemplatesFlag := flag.String("filename-templates", "", "Comma-separated per-output filename templates as type=template, e.g. fraud_alerts=alerts-{{.Date}}.csv")
+	dateCheckFlag := flag.String("date-check", dateCheckOff, "Check input file dates against the processing date (off|warn|abort)")
+	dumpStateFlag := flag.Bool("dump-state", false, "Write binary (gob) snapshots of the validated and processed state")
+	overdraftTransitionsFlag := flag.Bool("overdraft-transitions", false, "Write the accounts that entered overdraft and those that cured it during the day")
+	ledgerFlag := flag.Bool("ledger", false, "Write one ledger row per account-impacting leg, with transfer legs sharing a group ID")
+	hourlyHistogramFlag := flag.Bool("hourly-histogram", false, "Write per-account transaction counts by hour of day")
+	tzFlag := flag.String("tz", "UTC", "IANA business time zone for the processing day, date boundaries, and hour-of-day reporting")
+	holdsFlag := flag.String("holds", "", "Holds CSV (hold_id,account_id,amount,placed_at,expires_at) for reporting expiring holds")
+	holdLifetimeFlag := flag.Float64("hold-lifetime-days", 7, "Days a hold lasts when the holds file gives no expiry")
+	holdHorizonFlag := flag.Float64("hold-expiry-horizon-days", 2, "Report holds expiring within this many days after the processing day")
+	balanceThresholdsFlag := flag.String("balance-thresholds", "", "Customer low-balance alert thresholds CSV (account_id,threshold)")
+	anomalyPageSizeFlag := flag.Int("anomaly-page-size", 0, "Split the anomalies file into numbered parts of at most this many rows (0 writes one file)")
+	postHookFlag := flag.String("post-hook", "", "Command run after all outputs are written, given the run date and output directory")
+	postHookStrictFlag := flag.Bool("post-hook-strict", false, "Fail the batch if the post-processing hook fails")
+	txIDPatternsFlag := flag.String("tx-id-patterns", "", "Comma-separated regular expressions matching transaction IDs, used to reject account IDs from swapped columns")
+	stalePendingFlag := flag.String("stale-pending", ingestion.StalePendingProcess, "Handling of pending transactions older than the processing date (process|expire)")
+	outputRetriesFlag := flag.Int("output-retries", output.DefaultRetryConfig().Retries, "Times to retry creating an output file that fails to open before giving up")
+	outputRetryBackoffFlag := flag.Float64("output-retry-backoff-ms", float64(output.DefaultRetryConfig().Backoff.Milliseconds()), "Milliseconds to wait before the first output file retry, doubling for each further one")
+	kafkaBrokersFlag := flag.String("alert-kafka-brokers", "", "Comma-separated Kafka bootstrap brokers (host:port) to publish anomalies to")
+	kafkaTopicFlag := flag.String("alert-kafka-topic", "", "Kafka topic each anomaly is published to as JSON, keyed by account ID")
+	kafkaTLSFlag := flag.Bool("alert-kafka-tls", false, "Connect to the Kafka brokers over TLS, verified against the system roots")
+	alertIntervalFlag := flag.Float64("alert-interval-ms", 0, "Minimum spacing between alert sends in milliseconds (0 sends without pacing)")
+	alertJitterFlag := flag.Float64("alert-jitter-ms", 0, "Maximum random delay added to each alert's spacing in milliseconds")
+	alertSeedFlag := flag.Int64("alert-jitter-seed", 1, "Seed for the alert jitter, so a re-run paces alerts the same way")
+	nowFlag := flag.String("now", "", "Fix the current time (RFC3339) for deterministic test runs")
 	flag.Parse()
 
+	level, err := logging.ParseLevel(*logLevelFlag)
+	if err != nil {
+		logging.Fatalf("Invalid -log-level: %v", err)
+	}
+	logging.SetLevel(level)
+
+	// Pin the clock in test mode
+	if *nowFlag != "" {
+		fixed, err := time.Parse(time.RFC3339, *nowFlag)
+		if err != nil {
+			logging.Fatalf("Invalid -now time: %v", err)
+		}
+		now = func() time.Time { return fixed }
+	}
+
 	// Configure logging
 	if *logFileFlag != "" {
 		logFile, err := os.OpenFile(*logFileFlag, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 		if err != nil {
-			log.Fatalf("Failed to open log file: %v", err)
+			logging.Fatalf("Failed to open log file: %v", err)
 		}
 		defer func(logFile *os.File) {
 			err := logFile.Close()
@@ -38,94 +172,837 @@ func main() {
 		log.SetOutput(logFile)
 	}
 
+	// Configure the business calendar
+	businessCalendar := calendar.DefaultCalendar()
+	weekend, err := calendar.ParseWeekend(*weekendFlag)
+	if err != nil {
+		logging.Fatalf("Invalid weekend: %v", err)
+	}
+	businessCalendar.Weekend = weekend
+	if *holidaysFlag != "" {
+		businessCalendar.Holidays, err = calendar.LoadHolidays(*holidaysFlag)
+		if err != nil {
+			logging.Fatalf("Failed to load holidays: %v", err)
+		}
+	}
+
+	// Dates and day boundaries follow the business time zone
+	location, err := time.LoadLocation(*tzFlag)
+	if err != nil {
+		logging.Fatalf("Invalid time zone: %v", err)
+	}
+
 	// Determine processing date
 	var processDate time.Time
-	var err error
 	if *dateFlag != "" {
-		processDate, err = time.Parse("2006-01-02", *dateFlag)
+		processDate, err = time.ParseInLocation("2006-01-02", *dateFlag, location)
 		if err != nil {
-			log.Fatalf("Invalid date format: %v", err)
+			logging.Fatalf("Invalid date format: %v", err)
 		}
+	} else if fileDate, ok := ingestion.DateFromFilename(*transactionsFlag); *transactionsFlag != "" && ok {
+		// Infer the date from the transactions file name so the two can't drift
+		processDate = time.Date(fileDate.Year(), fileDate.Month(), fileDate.Day(), 0, 0, 0, 0, location)
+		logging.Infof("Using processing date %s from transactions file name", processDate.Format("2006-01-02"))
 	} else {
-		// Default to yesterday
-		processDate = time.Now().AddDate(0, 0, -1)
+		// Default to the previous business day
+		processDate = businessCalendar.PreviousBusinessDay(now().In(location))
 	}
 	dateStr := processDate.Format("2006-01-02")
+	metrics := models.Metrics{Date: dateStr}
+
+	if *stalePendingFlag != ingestion.StalePendingProcess && *stalePendingFlag != ingestion.StalePendingExpire {
+		logging.Fatalf("Invalid stale pending policy: %s", *stalePendingFlag)
+	}
+
+	if *dateCheckFlag != dateCheckOff && *dateCheckFlag != dateCheckWarn && *dateCheckFlag != dateCheckAbort {
+		logging.Fatalf("Invalid date check mode: %s", *dateCheckFlag)
+	}
+
+	if *idCaseFlag != "" && *idCaseFlag != "upper" && *idCaseFlag != "lower" {
+		logging.Fatalf("Invalid account ID case: %s", *idCaseFlag)
+	}
+	idNormalizer := models.IDNormalizer{Trim: *trimIDsFlag, Case: *idCaseFlag}
 
-	log.Printf("Starting batch processing for date: %s", dateStr)
+	if *workersFlag < 1 {
+		logging.Fatalf("Invalid worker count: %d", *workersFlag)
+	}
+	if *outputRetriesFlag < 0 || *outputRetryBackoffFlag < 0 {
+		logging.Fatalf("Invalid output retry settings: %d retries, %gms backoff", *outputRetriesFlag, *outputRetryBackoffFlag)
+	}
+	output.SetRetryConfig(output.RetryConfig{
+		Retries: *outputRetriesFlag,
+		Backoff: time.Duration(*outputRetryBackoffFlag * float64(time.Millisecond)),
+	})
+	output.SetReportSink(reportSink)
+
+	var kafkaBrokers []string
+	for _, broker := range strings.Split(*kafkaBrokersFlag, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			kafkaBrokers = append(kafkaBrokers, broker)
+		}
+	}
+	if (len(kafkaBrokers) > 0) != (*kafkaTopicFlag != "") {
+		logging.Fatalf("-alert-kafka-brokers and -alert-kafka-topic must be set together")
+	}
+	if *alertIntervalFlag < 0 || *alertJitterFlag < 0 {
+		logging.Fatalf("Invalid alert pacing: %gms interval, %gms jitter", *alertIntervalFlag, *alertJitterFlag)
+	}
+
+	readChunks := *readChunksFlag
+	if readChunks == 0 {
+		readChunks = *workersFlag
+	}
+
+	logging.Infof("Starting batch processing for date: %s", dateStr)
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(*outputDirFlag, 0755); err != nil {
-		log.Fatalf("Failed to create output directory: %v", err)
+	if !*dryRunFlag {
+		if err := os.MkdirAll(*outputDirFlag, 0755); err != nil {
+			logging.Fatalf("Failed to create output directory: %v", err)
+		}
+	}
+
+	// Name output files from the configured templates
+	fileNamer, err := output.NewFileNamer(*filenameTemplateFlag, parseTemplateOverrides(*filenameTemplatesFlag))
+	if err != nil {
+		logging.Fatalf("Invalid output filename template: %v", err)
+	}
+	outputPath := func(artifact string, date string, ext string) string {
+		name, err := fileNamer.Name(artifact, date, ext)
+		if err != nil {
+			logging.Fatalf("Failed to name output file: %v", err)
+		}
+		return filepath.Join(*outputDirFlag, name)
+	}
+
+	// Load custom anomaly rules from plugins, continuing without any that fail
+	for _, path := range strings.Split(*pluginsFlag, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		rules, err := detector.LoadPlugin(path)
+		if err != nil {
+			logging.Warnf("Skipping plugin: %v", err)
+			continue
+		}
+		for _, rule := range rules {
+			detector.RegisterRule(rule)
+		}
+		logging.Infof("Registered %d anomaly rules from plugin %s", len(rules), path)
+	}
+
+	// Configure account ID anonymization
+	var anonymizer *output.Anonymizer
+	if *anonymizeFlag {
+		salt := []byte(*anonymizeSaltFlag)
+		if len(salt) == 0 {
+			salt = make([]byte, 16)
+			if _, err := rand.Read(salt); err != nil {
+				logging.Fatalf("Failed to generate anonymization salt: %v", err)
+			}
+		}
+		anonymizer = output.NewAnonymizer(salt)
 	}
 
 	// Step 1: Load account data from the previous day
 	accountsFilePath := filepath.Join(*inputDirFlag, fmt.Sprintf("accounts_%s.csv", dateStr))
-	if _, err := os.Stat(accountsFilePath); os.IsNotExist(err) {
-		accountsFilePath = filepath.Join(*inputDirFlag, "accounts.csv")
+	accountsOutputPath := outputPath("accounts", now().In(location).Format("2006-01-02"), "csv")
+	accountStore := newAccountStore(accountsFilePath, filepath.Join(*inputDirFlag, "accounts.csv"), accountsOutputPath)
+	accounts, err := accountStore.LoadAccounts()
+	if err != nil {
+		logging.Fatalf("Failed to load accounts: %v", err)
 	}
-	accounts, err := processor.LoadAccounts(accountsFilePath)
+	accounts, err = processor.NormalizeAccountIDs(accounts, idNormalizer)
 	if err != nil {
-		log.Fatalf("Failed to load accounts: %v", err)
+		logging.Fatalf("Failed to normalize account IDs: %v", err)
 	}
-	log.Printf("Loaded %d accounts", len(accounts))
+	processor.ResetDailyTotals(accounts, processDate)
+	logging.Infof("Loaded %d accounts", len(accounts))
 
-	// Step 2: Ingest transactions
-	transactionsFilePath := filepath.Join(*inputDirFlag, fmt.Sprintf("transactions_%s.csv", dateStr))
-	transactions, err := ingestion.LoadTransactions(transactionsFilePath)
-	if err != nil {
-		log.Fatalf("Failed to load transactions: %v", err)
+	validationConfig := ingestion.DefaultValidationConfig()
+	var validTransactions, invalidTransactions, awaitingApproval []models.Transaction
+	var priorProcessed []models.Transaction
+	var parseErrors models.ParseErrors
+	if *reprocessFlag != "" {
+		// Reprocess a prior run's transactions instead of ingesting the day's file
+		priorProcessed, err = ingestion.LoadProcessedTransactions(*reprocessFlag)
+		if err != nil {
+			logging.Fatalf("Failed to load processed transactions to reprocess: %v", err)
+		}
+		ingestion.NormalizeAccountIDs(priorProcessed, idNormalizer)
+		ingestion.NormalizeTimestamps(priorProcessed, location)
+		validTransactions = processor.PrepareReprocess(priorProcessed)
+		logging.Infof("Reprocessing %d transactions from %s", len(validTransactions), *reprocessFlag)
+		metrics.LoadedTransactions = len(validTransactions)
+	} else {
+		// Step 2: Ingest transactions
+		transactionsFilePath := *transactionsFlag
+		if transactionsFilePath == "" {
+			transactionsFilePath = compressedFallback(filepath.Join(*inputDirFlag, fmt.Sprintf("transactions_%s.csv", dateStr)))
+		}
+		// Make sure the input files belong to the processing date. Accounts may carry either the
+		// processing date or the previous business day's closing date.
+		if *dateCheckFlag != dateCheckOff {
+			dateErrs := []error{
+				ingestion.CheckFileDate(accountsFilePath, processDate, businessCalendar.PreviousBusinessDay(processDate)),
+				ingestion.CheckFileDate(transactionsFilePath, processDate),
+			}
+			for _, err := range dateErrs {
+				if err == nil {
+					continue
+				}
+				if *dateCheckFlag == dateCheckAbort {
+					logging.Fatalf("Input file date mismatch: %v", err)
+				}
+				logging.Warnf("Input file date mismatch: %v", err)
+			}
+		}
+
+		loaderConfig := ingestion.DefaultLoaderConfig()
+		if *timestampLayoutsFlag != "" {
+			loaderConfig.TimestampLayouts = strings.Split(*timestampLayoutsFlag, ";")
+		}
+		var heldPath string
+		if *holdInsufficientFlag {
+			heldPath = outputPath("held_transactions", businessCalendar.PreviousBusinessDay(processDate).Format("2006-01-02"), "csv")
+		}
+		transactionSource := newTransactionSource(transactionsFilePath, heldPath, readChunks, loaderConfig)
+		var transactions []models.Transaction
+		transactions, err = transactionSource.LoadTransactions()
+		if errors.As(err, &parseErrors) {
+			for _, parseErr := range parseErrors {
+				logging.Warnf("Skipped transaction record: %v", parseErr)
+			}
+		} else if err != nil {
+			logging.Fatalf("Failed to load transactions: %v", err)
+		}
+
+		// Retry the transactions the previous batch held for lack of funds once the day's own have
+		// posted, so the day's credits can cover them
+		held, err := transactionSource.LoadHeldTransactions()
+		if err != nil {
+			logging.Fatalf("Failed to load held transactions: %v", err)
+		}
+		if len(held) > 0 {
+			logging.Infof("Retrying %d held transactions from %s", len(held), heldPath)
+			transactions = append(transactions, processor.PrepareReprocess(held)...)
+			validationConfig.HeldOver = make(map[string]bool, len(held))
+			for _, transaction := range held {
+				validationConfig.HeldOver[transaction.ID] = true
+			}
+		}
+		ingestion.NormalizeAccountIDs(transactions, idNormalizer)
+		ingestion.NormalizeTimestamps(transactions, location)
+		logging.Infof("Loaded %d transactions", len(transactions))
+		metrics.LoadedTransactions = len(transactions)
+		metrics.UnparseableRecords = len(parseErrors)
+
+		// Step 3: Validate transactions
+		validationConfig.ProcessDate = processDate
+		validationConfig.StalePending = *stalePendingFlag
+		for _, pattern := range strings.Split(*txIDPatternsFlag, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				logging.Fatalf("Invalid transaction ID pattern %q: %v", pattern, err)
+			}
+			validationConfig.TransactionIDPatterns = append(validationConfig.TransactionIDPatterns, re)
+		}
+		validationConfig.AutoCreateOnCredit = *autocreateFlag
+		validationConfig.FrozenAccounts = parseIDSet(*frozenAccountsFlag, idNormalizer)
+		if *frozenFlag != "" {
+			frozen, err := ingestion.LoadFrozenAccounts(*frozenFlag, idNormalizer)
+			if err != nil {
+				logging.Fatalf("Failed to load frozen accounts: %v", err)
+			}
+			for id := range frozen {
+				validationConfig.FrozenAccounts[id] = true
+			}
+		}
+		if len(validationConfig.FrozenAccounts) > 0 {
+			logging.Infof("Loaded %d frozen accounts", len(validationConfig.FrozenAccounts))
+		}
+		validationConfig.ApprovalThreshold = *approvalThresholdFlag
+		if *approvalsFlag != "" {
+			validationConfig.Approvals, err = ingestion.LoadApprovals(*approvalsFlag)
+			if err != nil {
+				logging.Fatalf("Failed to load approvals: %v", err)
+			}
+		}
+		validTransactions, invalidTransactions, awaitingApproval = ingestion.ValidateTransactionsWithConfig(transactions, accounts, validationConfig)
+		logging.Infof("Validated transactions: %d valid, %d invalid, %d awaiting approval",
+			len(validTransactions), len(invalidTransactions), len(awaitingApproval))
+		for _, transaction := range invalidTransactions {
+			logging.Debugf("Invalid transaction %s: %s", transaction.ID, transaction.ValidationMessage)
+		}
 	}
-	log.Printf("Loaded %d transactions", len(transactions))
 
-	// Step 3: Validate transactions
-	validTransactions, invalidTransactions := ingestion.ValidateTransactions(transactions, accounts)
-	log.Printf("Validated transactions: %d valid, %d invalid", len(validTransactions), len(invalidTransactions))
+	metrics.ValidTransactions = len(validTransactions)
+	metrics.InvalidTransactions = len(invalidTransactions)
+	metrics.AwaitingApproval = len(awaitingApproval)
+
+	// Output files are written together once all stages complete
+	var jobs []output.Job
+	if *formatFlag != output.FormatCSV && *formatFlag != output.FormatJSON {
+		logging.Fatalf("Invalid report format %q: must be csv or json", *formatFlag)
+	}
+	reportExt := *formatFlag
+
+	// Log records that could not be parsed
+	if len(parseErrors) > 0 {
+		jobs = append(jobs, output.Job{Name: "ingestion errors", Path: outputPath("ingestion_errors", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, parseErrors, output.WriteIngestionErrors)})
+	}
 
 	// Log invalid transactions
 	if len(invalidTransactions) > 0 {
-		invalidPath := filepath.Join(*outputDirFlag, fmt.Sprintf("invalid_transactions_%s.csv", dateStr))
-		if err := output.WriteInvalidTransactions(invalidTransactions, invalidPath); err != nil {
-			log.Printf("Warning: Failed to write invalid transactions: %v", err)
-		}
+		invalidPath := outputPath("invalid_transactions", dateStr, reportExt)
+		invalidOutput := anonymizer.Transactions(invalidTransactions)
+		jobs = append(jobs, output.Job{Name: "invalid transactions", Path: invalidPath,
+			Write: reportWriter(*formatFlag, invalidOutput, output.WriteInvalidTransactions)})
+	}
+
+	// Route transactions awaiting manual approval to their own file, to be resubmitted once decided
+	if validationConfig.ApprovalThreshold > 0 {
+		awaitingOutput := anonymizer.Transactions(awaitingApproval)
+		jobs = append(jobs, output.Job{Name: "awaiting approval", Path: outputPath("awaiting_approval", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, awaitingOutput, output.WriteProcessedTransactions)})
+	}
+
+	// Dump the validated state for inter-stage handoff
+	if *dumpStateFlag {
+		validatedState := models.State{Stage: "validated", Accounts: accounts, Transactions: validTransactions}
+		jobs = append(jobs, output.Job{Name: "validated state", Path: outputPath("state_validated", dateStr, "gob"), Write: func(path string) error {
+			return output.WriteState(validatedState, path)
+		}})
 	}
 
 	// Step 4: Process valid transactions
-	processedAccounts, processedTransactions := processor.ProcessTransactions(validTransactions, accounts)
-	log.Printf("Processed %d transactions", len(processedTransactions))
+	processorConfig := processor.DefaultConfig()
+	processorConfig.CoolingOffPeriodMins = *coolingOffFlag
+	processorConfig.HoldCoolingOffViolations = *holdCoolingOffFlag
+	processorConfig.ExcludedDestinations = parseIDSet(*excludedDestinationsFlag, idNormalizer)
+	processorConfig.StrictInvariants = *strictFlag
+	processorConfig.ManualReviewThreshold = *reviewThresholdFlag
+	processorConfig.HoldInsufficientFunds = *holdInsufficientFlag
+	processorConfig.AutoCreateOnCredit = *autocreateFlag
+	processorConfig.Workers = *workersFlag
+	processorConfig.OverdraftLimit = *overdraftLimitFlag
+	processorConfig.MaxDailyWithdrawalLimit = *dailyWithdrawalLimitFlag
+	processorConfig.LargeTransactionThreshold = *largeTransactionFlag
+	processorConfig.ApprovedOverdraftLimit = *approvedOverdraftFlag
+	processorConfig.NoOverdraftAccountTypes = parseIDSet(*noOverdraftTypesFlag, models.IDNormalizer{})
+	if *ratesFlag != "" {
+		processorConfig.ExchangeRates, err = processor.LoadExchangeRates(*ratesFlag)
+		if err != nil {
+			logging.Fatalf("Failed to load exchange rates: %v", err)
+		}
+	}
+	if *limitsFlag != "" {
+		processorConfig.AccountLimits, err = processor.LoadAccountLimits(*limitsFlag, idNormalizer)
+		if err != nil {
+			logging.Fatalf("Failed to load account limits: %v", err)
+		}
+	}
+	processorConfig.AllowedConversionPairs = make(map[string]bool)
+	for _, pair := range strings.Split(*conversionPairsFlag, ",") {
+		if from, to, found := strings.Cut(strings.TrimSpace(pair), "/"); found {
+			processorConfig.AllowedConversionPairs[processor.RateKey(from, to)] = true
+		}
+	}
+	processorConfig.TransactionFees, err = processor.ParseFeeConfig(*feesFlag)
+	if err != nil {
+		logging.Fatalf("Invalid transaction fees: %v", err)
+	}
+	processorConfig.DailyOverdraftFeeCap = *overdraftFeeCapFlag
+	processorConfig.OverdraftFeeGraceDays = *overdraftFeeGraceFlag
+	processorConfig.OverdraftFeeSchedule, err = parseAmountList(*overdraftFeesFlag)
+	if err != nil {
+		logging.Fatalf("Invalid overdraft fee schedule: %v", err)
+	}
+	// Replay the decisions a prior run for the date recorded, so a re-run reproduces its outputs.
+	// Aggregated ledgers only list small transactions individually in the detail file. The prior
+	// run may have written either report format.
+	if *reprocessFlag == "" {
+		processorConfig.PriorDecisions = make(map[string]models.Transaction)
+		for _, artifact := range []string{"processed_transactions", "processed_transactions_detail"} {
+			ledgerPath := outputPath(artifact, dateStr, output.FormatCSV)
+			ledger, found, err := output.ReadReport(ledgerPath, ingestion.ReadReplayLedger)
+			if err == nil && !found {
+				ledgerPath = outputPath(artifact, dateStr, output.FormatJSON)
+				ledger, _, err = output.ReadReport(ledgerPath, ingestion.ReadProcessedTransactionsJSON)
+			}
+			if err != nil {
+				logging.Fatalf("Failed to load processed transactions ledger %s: %v", ledgerPath, err)
+			}
+			for id, decision := range processor.PriorDecisions(ledger) {
+				processorConfig.PriorDecisions[id] = decision
+			}
+		}
+		if len(processorConfig.PriorDecisions) > 0 {
+			logging.Infof("Replaying %d transactions decided by a prior run", len(processorConfig.PriorDecisions))
+		}
+	}
+	processedAccounts, processedTransactions, err := processor.ProcessTransactionsChecked(validTransactions, accounts, processorConfig)
+	if err != nil {
+		logging.Fatalf("Processing invariant check failed: %v", err)
+	}
+	if err := processor.VerifyPriorDecisions(processorConfig.PriorDecisions, processedTransactions); err != nil {
+		logging.Fatalf("Re-run does not match the prior run: %v", err)
+	}
+	logging.Infof("Processed %d transactions", len(processedTransactions))
+	output.CountTransactionMetrics(&metrics, processedTransactions)
+	for _, id := range processor.CreatedAccounts(accounts, processedAccounts) {
+		logging.Infof("Created account %s for a credit to an unknown account", id)
+	}
+	for _, transaction := range processedTransactions {
+		if transaction.Status == "rejected" {
+			logging.Debugf("Rejected transaction %s: %s", transaction.ID, transaction.ProcessingMessage)
+		}
+	}
+
+	// Route transactions held for manual review to their own file
+	if processorConfig.ManualReviewThreshold > 0 {
+		reviewTransactions := []models.Transaction{}
+		for _, transaction := range processedTransactions {
+			if transaction.Status == "held" && processor.NeedsManualReview(transaction, processorConfig) {
+				reviewTransactions = append(reviewTransactions, transaction)
+			}
+		}
+		logging.Infof("Held %d transactions for manual review", len(reviewTransactions))
+		reviewOutput := anonymizer.Transactions(reviewTransactions)
+		jobs = append(jobs, output.Job{Name: "manual review", Path: outputPath("manual_review", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, reviewOutput, output.WriteProcessedTransactions)})
+	}
+
+	// Carry transactions held for lack of funds over to the next batch
+	if processorConfig.HoldInsufficientFunds {
+		heldTransactions := []models.Transaction{}
+		for _, transaction := range processedTransactions {
+			if processor.HeldForInsufficientFunds(transaction) {
+				heldTransactions = append(heldTransactions, transaction)
+			}
+		}
+		logging.Infof("Held %d transactions for insufficient funds", len(heldTransactions))
+		heldOutput := anonymizer.Transactions(heldTransactions)
+		jobs = append(jobs, output.Job{Name: "held transactions", Path: outputPath("held_transactions", dateStr, "csv"), Write: func(path string) error {
+			return output.WriteProcessedTransactions(heldOutput, path)
+		}})
+	}
+
+	// Report how the corrected rates changed each account's closing balance
+	if *reprocessFlag != "" {
+		originalAccounts := processor.ApplyPostedTransactions(accounts, priorProcessed)
+		deltas := anonymizer.BalanceDeltas(processor.BalanceDeltas(originalAccounts, processedAccounts))
+		logging.Infof("Reprocessing changed %d account balances", len(deltas))
+		jobs = append(jobs, output.Job{Name: "balance deltas", Path: outputPath("balance_deltas", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, deltas, output.WriteBalanceDeltas)})
+	}
+	if *dumpStateFlag {
+		processedState := models.State{Stage: "processed", Accounts: processedAccounts, Transactions: processedTransactions}
+		jobs = append(jobs, output.Job{Name: "processed state", Path: outputPath("state_processed", dateStr, "gob"), Write: func(path string) error {
+			return output.WriteState(processedState, path)
+		}})
+	}
+
+	// Reconcile balances per currency
+	reconciliation := processor.Reconcile(accounts, processedAccounts, processedTransactions)
+	for _, r := range reconciliation {
+		if !r.Balanced {
+			logging.Warnf("%s does not reconcile: difference of %s", r.Currency, r.Difference)
+		}
+	}
+	reconciliationPath := outputPath("reconciliation", dateStr, reportExt)
+	jobs = append(jobs, output.Job{Name: "reconciliation report", Path: reconciliationPath,
+		Write: reportWriter(*formatFlag, reconciliation, output.WriteReconciliation)})
+
+	// Generate account summaries, and load the prior business day's summary when available
+	summary := output.GenerateAccountSummary(accounts, processedAccounts, processedTransactions, dateStr)
+	priorSummaryPath := outputPath("account_summary", businessCalendar.PreviousBusinessDay(processDate).Format("2006-01-02"), "csv")
+	priorSummaries, _, err := output.ReadReport(priorSummaryPath, ingestion.ReadAccountSummaries)
+	if err != nil {
+		logging.Warnf("Failed to load prior account summary: %v", err)
+	}
 
 	// Step 5: Detect anomalies
-	anomalies := detector.DetectAnomalies(processedTransactions, processedAccounts)
-	log.Printf("Detected %d anomalies", len(anomalies))
+	detectorConfig := detector.DefaultConfig()
+	detectorConfig.CoolingOffPeriodMins = *coolingOffFlag
+	detectorConfig.NetPositionLongLimit = *positionLongFlag
+	detectorConfig.NetPositionShortLimit = *positionShortFlag
+	detectorConfig.FalsePositiveWindowDays = *falsePositiveWindowFlag
+	detectorConfig.MaxAnomaliesPerAccount = *maxAnomaliesPerAccountFlag
+	detectorConfig.Limits = processorConfig.Limits
+	detectorConfig.LargeCreditThreshold = *largeCreditFlag
+	detectorConfig.LargeDebitThreshold = *largeDebitFlag
+	detectorConfig.BalanceDrainFraction = *balanceDrainFlag
+	detectorConfig.StructuringBand = *structuringBandFlag
+	detectorConfig.StructuringMinCount = *structuringMinCountFlag
+	detectorConfig.RepeatOverdraftThreshold = *repeatOverdraftFlag
+	detectorConfig.VerboseOverdrafts = *verboseOverdraftsFlag
+	detectorConfig.Workers = *workersFlag
+	detectorConfig.ProcessDate = processDate
+	detectorConfig.OpeningAccounts = accounts
+	detectorConfig.PriorSummaries = priorSummaries
+	detectorConfig.InvalidTransactions = invalidTransactions
+	anomalies := detector.DetectAnomaliesWithConfig(processedTransactions, processedAccounts, detectorConfig)
+	logging.Infof("Detected %d anomalies", len(anomalies))
+
+	// Suppress alerts confirmed as false positives
+	if *falsePositivesFlag != "" {
+		registry, err := detector.LoadFalsePositives(*falsePositivesFlag)
+		if err != nil {
+			logging.Fatalf("Failed to load false positive registry: %v", err)
+		}
+		var suppressed []models.Anomaly
+		anomalies, suppressed = detector.SuppressFalsePositives(anomalies, registry, processDate, detectorConfig.FalsePositiveWindowDays)
+		for _, anomaly := range suppressed {
+			logging.Debugf("Suppressed %s anomaly for account %s (transaction %s) as a registered false positive",
+				anomaly.Type, anomaly.AccountID, anomaly.TransactionID)
+		}
+	}
+
+	// Keep a single noisy account from flooding the alerts file
+	anomalies = detector.CapAnomaliesPerAccount(anomalies, detectorConfig.MaxAnomaliesPerAccount)
+	metrics.Anomalies = len(anomalies)
 
 	// Write anomalies to output
 	if len(anomalies) > 0 {
-		anomalyPath := filepath.Join(*outputDirFlag, fmt.Sprintf("fraud_alerts_%s.csv", dateStr))
-		if err := output.WriteAnomalies(anomalies, anomalyPath); err != nil {
-			log.Printf("Warning: Failed to write anomalies: %v", err)
+		anomalyPath := outputPath("fraud_alerts", dateStr, reportExt)
+		anomalyOutput := anonymizer.Anomalies(anomalies)
+		writeAnomalies := func(anomalies []models.Anomaly, path string) error {
+			return output.WriteAnomaliesPaged(anomalies, path, *anomalyPageSizeFlag)
+		}
+		anomalyJob := output.Job{Name: "anomalies", Path: anomalyPath,
+			Write: reportWriter(*formatFlag, anomalyOutput, writeAnomalies)}
+		if *formatFlag == output.FormatCSV {
+			anomalyJob.Paths = output.PagedPaths(anomalyPath, len(anomalyOutput), *anomalyPageSizeFlag)
 		}
+		jobs = append(jobs, anomalyJob)
+
+		anomalySummaryPath := outputPath("anomaly_summary", dateStr, reportExt)
+		anomalySummary := output.GenerateAnomalySummary(anomalies)
+		jobs = append(jobs, output.Job{Name: "anomaly summary", Path: anomalySummaryPath,
+			Write: reportWriter(*formatFlag, anomalySummary, output.WriteAnomalySummary)})
 	}
 
-	// Step 6: Generate account summaries
-	summary := output.GenerateAccountSummary(processedAccounts, processedTransactions, dateStr)
+	// Detect informational events
+	events := detector.DetectEvents(processedTransactions, accounts, priorSummaries)
+	if *balanceThresholdsFlag != "" {
+		thresholds, err := detector.LoadBalanceThresholds(*balanceThresholdsFlag, idNormalizer)
+		if err != nil {
+			logging.Fatalf("Failed to load balance alert thresholds: %v", err)
+		}
+		events = append(events, detector.DetectCustomerLowBalance(processedTransactions, processedAccounts, thresholds)...)
+	}
+	logging.Infof("Detected %d events", len(events))
 
-	// Write updated accounts
-	accountsOutputPath := filepath.Join(*outputDirFlag, fmt.Sprintf("accounts_%s.csv", time.Now().Format("2006-01-02")))
-	if err := output.WriteAccounts(processedAccounts, accountsOutputPath); err != nil {
-		log.Fatalf("Failed to write updated accounts: %v", err)
+	if len(events) > 0 {
+		eventsPath := outputPath("events", dateStr, reportExt)
+		eventsOutput := anonymizer.Events(events)
+		jobs = append(jobs, output.Job{Name: "events", Path: eventsPath,
+			Write: reportWriter(*formatFlag, eventsOutput, output.WriteEvents)})
 	}
 
+	// Summarize the high-value accounts analysts review
+	var highValueSummary []models.AccountSummary
+	if *highValueFlag > 0 {
+		highValueSummary = output.GenerateAccountSummaryFiltered(
+			accounts, processedAccounts, processedTransactions, anomalies, dateStr, *highValueFlag)
+		logging.Infof("%d high-value accounts", len(highValueSummary))
+	}
+
+	// Report holds expiring soon after the close of the processing day
+	if *holdsFlag != "" {
+		holds, err := ingestion.LoadHolds(*holdsFlag, time.Duration(*holdLifetimeFlag*24)*time.Hour)
+		if err != nil {
+			logging.Fatalf("Failed to load holds: %v", err)
+		}
+		for i := range holds {
+			holds[i].AccountID = idNormalizer.Normalize(holds[i].AccountID)
+		}
+		dayEnd := processDate.AddDate(0, 0, 1)
+		output.ApplyExpiringHolds(summary, holds, dayEnd, time.Duration(*holdHorizonFlag*24)*time.Hour)
+		output.ApplyExpiringHolds(highValueSummary, holds, dayEnd, time.Duration(*holdHorizonFlag*24)*time.Hour)
+	}
+
+	// Write updated accounts
+	accountsOutput := anonymizer.Accounts(processedAccounts)
+	jobs = append(jobs, output.Job{Name: "updated accounts", Path: accountsOutputPath, Fatal: true, Write: func(string) error {
+		return accountStore.SaveAccounts(accountsOutput)
+	}})
+
 	// Write transaction log
-	transactionsOutputPath := filepath.Join(*outputDirFlag, fmt.Sprintf("processed_transactions_%s.csv", dateStr))
-	if err := output.WriteProcessedTransactions(processedTransactions, transactionsOutputPath); err != nil {
-		log.Printf("Warning: Failed to write processed transactions: %v", err)
+	transactionsOutputPath := outputPath("processed_transactions", dateStr, reportExt)
+	sortedTransactions, err := output.SortTransactions(processedTransactions, *sortOutputFlag)
+	if err != nil {
+		logging.Fatalf("Invalid output sort order: %v", err)
+	}
+	var txColumns []string
+	if *txColumnsFlag != "" {
+		for _, column := range strings.Split(*txColumnsFlag, ",") {
+			txColumns = append(txColumns, strings.TrimSpace(column))
+		}
+		if err := output.CheckTransactionColumns(txColumns); err != nil {
+			logging.Fatalf("Invalid -tx-columns: %v", err)
+		}
+	}
+	writeTransactions := func(transactions []models.Transaction, path string) error {
+		return output.WriteProcessedTransactionsColumns(transactions, path, txColumns)
+	}
+	transactionsOutput := anonymizer.Transactions(
+		output.AggregateMicroTransactions(sortedTransactions, *aggregateBelowFlag, *aggregateByCounterpartyFlag))
+	jobs = append(jobs, output.Job{Name: "processed transactions", Path: transactionsOutputPath,
+		Write: reportWriter(*formatFlag, transactionsOutput, writeTransactions)})
+	if *aggregateBelowFlag > 0 && *aggregateKeepDetailFlag {
+		detailPath := outputPath("processed_transactions_detail", dateStr, reportExt)
+		detailOutput := anonymizer.Transactions(sortedTransactions)
+		jobs = append(jobs, output.Job{Name: "processed transaction detail", Path: detailPath,
+			Write: reportWriter(*formatFlag, detailOutput, writeTransactions)})
+	}
+
+	// Write the hourly activity histogram
+	if *hourlyHistogramFlag {
+		histogramPath := outputPath("hourly_histogram", dateStr, reportExt)
+		histogramOutput := anonymizer.Histograms(output.GenerateHourlyHistogram(processedTransactions, location))
+		jobs = append(jobs, output.Job{Name: "hourly histogram", Path: histogramPath,
+			Write: reportWriter(*formatFlag, histogramOutput, output.WriteHourlyHistogram)})
+	}
+
+	// Write the accounts that changed overdraft status today
+	if *overdraftTransitionsFlag {
+		newlyOverdrawn, cured := output.GenerateOverdraftTransitions(accounts, processedAccounts)
+		newlyOverdrawnOutput := anonymizer.OverdraftTransitions(newlyOverdrawn)
+		curedOutput := anonymizer.OverdraftTransitions(cured)
+		logging.Infof("%d accounts entered overdraft and %d cured", len(newlyOverdrawn), len(cured))
+		jobs = append(jobs, output.Job{Name: "newly overdrawn accounts", Path: outputPath("overdraft_entered", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, newlyOverdrawnOutput, output.WriteOverdraftTransitions)})
+		jobs = append(jobs, output.Job{Name: "cured overdraft accounts", Path: outputPath("overdraft_cured", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, curedOutput, output.WriteOverdraftTransitions)})
+	}
+
+	// Write the day's completed transactions as ledger legs
+	if *ledgerFlag {
+		ledgerOutput := anonymizer.Ledger(output.GenerateLedger(processedTransactions))
+		jobs = append(jobs, output.Job{Name: "ledger", Path: outputPath("ledger", dateStr, reportExt),
+			Write: reportWriter(*formatFlag, ledgerOutput, output.WriteLedger)})
 	}
 
 	// Write account summary
-	summaryPath := filepath.Join(*outputDirFlag, fmt.Sprintf("account_summary_%s.csv", dateStr))
-	if err := output.WriteAccountSummary(summary, summaryPath); err != nil {
-		log.Fatalf("Failed to write account summary: %v", err)
+	summaryPath := outputPath("account_summary", dateStr, "csv")
+	summaryOutput := anonymizer.Summaries(summary)
+	jobs = append(jobs, output.Job{Name: "account summary", Path: summaryPath, Fatal: true, Write: func(path string) error {
+		return output.WriteAccountSummary(summaryOutput, path)
+	}})
+	if *highValueFlag > 0 {
+		highValuePath := outputPath("high_value_summary", dateStr, reportExt)
+		highValueOutput := anonymizer.Summaries(highValueSummary)
+		jobs = append(jobs, output.Job{Name: "high-value account summary", Path: highValuePath,
+			Write: reportWriter(*formatFlag, highValueOutput, output.WriteAccountSummary)})
+	}
+
+	// Write the rules in effect for this run
+	settings := output.CollectConfigSettings("validation", validationConfig, ingestion.DefaultValidationConfig())
+	settings = append(settings, output.CollectConfigSettings("processor", processorConfig, processor.DefaultConfig())...)
+	settings = append(settings, output.CollectConfigSettings("detector", detectorConfig, detector.DefaultConfig())...)
+	effectiveConfigPath := outputPath("effective_config", dateStr, reportExt)
+	jobs = append(jobs, output.Job{Name: "effective config", Path: effectiveConfigPath,
+		Write: reportWriter(*formatFlag, settings, output.WriteEffectiveConfig)})
+
+	// Write the mapping needed to reverse anonymized account IDs
+	if anonymizer != nil {
+		mappingPath := outputPath("account_id_mapping", dateStr, "csv")
+		jobs = append(jobs, output.Job{Name: "account ID mapping", Path: mappingPath, Fatal: true, Write: func(path string) error {
+			return anonymizer.WriteMapping(path)
+		}})
+	}
+
+	// Write the run metrics, timed up to the point outputs are written
+	metrics.DurationSeconds = time.Since(startTime).Seconds()
+	jobs = append(jobs, output.Job{Name: "run metrics", Path: outputPath("run_metrics", dateStr, "json"), Write: func(path string) error {
+		return output.WriteRunMetrics(metrics, path)
+	}})
+
+	// Report what would have been written and stop before touching the output directory
+	if *dryRunFlag {
+		for _, job := range jobs {
+			logging.Infof("Dry run: would write %s to %s", job.Name, job.Path)
+		}
+		if *kafkaTopicFlag != "" {
+			logging.Infof("Dry run: would publish %d anomalies to Kafka topic %s", len(anomalies), *kafkaTopicFlag)
+		}
+		logging.Infof("Dry run completed for date: %s (%d outputs not written)", dateStr, len(jobs))
+		return
+	}
+
+	// Protect output from a previous run for the same date, and clear away parts of its split
+	// reports this run won't rewrite so they aren't mistaken for this run's
+	backupTimestamp := now().Format("20060102T150405")
+	existingOutputs, staleOutputs := output.ExistingOutputs(jobs), output.StaleOutputs(jobs)
+	for _, path := range existingOutputs {
+		if !*backupFlag {
+			logging.Warnf("Overwriting existing output file %s (use -backup to keep it)", path)
+			continue
+		}
+		backupPath, err := output.BackupFile(path, backupTimestamp)
+		if err != nil {
+			logging.Fatalf("Failed to back up existing output: %v", err)
+		}
+		logging.Infof("Backed up existing output file %s to %s", path, backupPath)
+	}
+	for _, path := range staleOutputs {
+		if !*backupFlag {
+			if err := output.RemoveFile(path); err != nil {
+				logging.Fatalf("Failed to remove stale output: %v", err)
+			}
+			logging.Warnf("Removed stale output file %s (use -backup to keep it)", path)
+			continue
+		}
+		backupPath, err := output.BackupFile(path, backupTimestamp)
+		if err != nil {
+			logging.Fatalf("Failed to back up stale output: %v", err)
+		}
+		logging.Infof("Backed up stale output file %s to %s", path, backupPath)
+	}
+
+	// Write all outputs, aborting if a required one failed
+	failed := false
+	for i, err := range output.RunJobs(jobs, *workersFlag) {
+		if err == nil {
+			continue
+		}
+		if jobs[i].Fatal {
+			logging.Errorf("Failed to write %s: %v", jobs[i].Name, err)
+			failed = true
+		} else {
+			logging.Warnf("Failed to write %s: %v", jobs[i].Name, err)
+		}
+	}
+	if failed {
+		logging.Fatalf("Batch processing failed for date: %s", dateStr)
 	}
 
-	log.Printf("Batch processing completed successfully for date: %s", dateStr)
+	// Publish the anomalies for the SIEM, flushing and closing the producer before exit
+	if *kafkaTopicFlag != "" {
+		kafkaConfig := alerting.KafkaConfig{Brokers: kafkaBrokers, Timeout: alerting.DefaultKafkaTimeout}
+		if *kafkaTLSFlag {
+			kafkaConfig.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		sink := alerting.NewKafkaSink(alerting.NewWriterProducer(kafkaConfig), *kafkaTopicFlag)
+		pacer := alertPacer(*alertIntervalFlag, *alertJitterFlag, *alertSeedFlag, nil)
+		publishErr := alerting.Publish(sink, anonymizer.Anomalies(anomalies), pacer)
+		if err := sink.Close(); err != nil && publishErr == nil {
+			publishErr = fmt.Errorf("error closing Kafka producer: %w", err)
+		}
+		if publishErr != nil {
+			logging.Warnf("Failed to publish anomalies to Kafka topic %s: %v", *kafkaTopicFlag, publishErr)
+		} else {
+			logging.Infof("Published %d anomalies to Kafka topic %s", len(anomalies), *kafkaTopicFlag)
+		}
+	}
+
+	// Trigger downstream jobs now that the batch output is complete
+	if *postHookFlag != "" {
+		if err := runPostHook(*postHookFlag, dateStr, *outputDirFlag); err != nil {
+			if *postHookStrictFlag {
+				logging.Fatalf("Post-processing hook failed: %v", err)
+			}
+			logging.Warnf("Post-processing hook failed: %v", err)
+		}
+	}
+
+	logging.Infof("Batch processing completed successfully for date: %s", dateStr)
+}
+
+// runPostHook runs the hook command with the run date and output directory appended as
+// arguments and exposed as BATCH_DATE and BATCH_OUTPUT_DIR in its environment
+func runPostHook(command string, dateStr string, outputDir string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty hook command")
+	}
+
+	args := append(fields[1:], dateStr, outputDir)
+	cmd := exec.Command(fields[0], args...)
+	cmd.Env = append(os.Environ(), "BATCH_DATE="+dateStr, "BATCH_OUTPUT_DIR="+outputDir)
+	cmd.Stdout = log.Writer()
+	cmd.Stderr = log.Writer()
+
+	logging.Infof("Running post-processing hook: %s", command)
+	return cmd.Run()
+}
+
+// compressedFallback returns the gzip-compressed twin of an input file path when only that exists
+func compressedFallback(filePath string) string {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if _, err := os.Stat(filePath + ".gz"); err == nil {
+			return filePath + ".gz"
+		}
+	}
+	return filePath
+}
+
+// parseIDSet parses a comma-separated list of account IDs into a set of normalized IDs
+func parseIDSet(list string, normalizer models.IDNormalizer) map[string]bool {
+	set := make(map[string]bool)
+	for _, id := range strings.Split(list, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			set[normalizer.Normalize(id)] = true
+		}
+	}
+	return set
+}
+
+// alertPacer returns the pacer spacing alert sends by intervalMs plus up to jitterMs, or nil when
+// neither is set
+func alertPacer(intervalMs float64, jitterMs float64, seed int64, clock alerting.Clock) *alerting.Pacer {
+	if intervalMs <= 0 && jitterMs <= 0 {
+		return nil
+	}
+	return alerting.NewPacer(time.Duration(intervalMs*float64(time.Millisecond)),
+		time.Duration(jitterMs*float64(time.Millisecond)), seed, clock)
+}
+
+// reportWriter returns a job writer for items in the selected report format
+func reportWriter[T any](format string, items []T, writeCSV func([]T, string) error) func(string) error {
+	if format == output.FormatJSON {
+		return func(path string) error {
+			return output.WriteJSON(items, path)
+		}
+	}
+	return func(path string) error {
+		return writeCSV(items, path)
+	}
+}
+
+// parseTemplateOverrides parses a comma-separated list of type=template pairs
+func parseTemplateOverrides(list string) map[string]string {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		artifact, text, found := strings.Cut(pair, "=")
+		if artifact = strings.TrimSpace(artifact); found && artifact != "" {
+			overrides[artifact] = strings.TrimSpace(text)
+		}
+	}
+	return overrides
+}
+
+// parseAmountList parses a comma-separated list of amounts
+func parseAmountList(list string) ([]float64, error) {
+	var amounts []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		amount, err := models.ParseAmount(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q: %w", field, err)
+		}
+		amounts = append(amounts, amount)
+	}
+	return amounts, nil
 }
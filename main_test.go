@@ -0,0 +1,966 @@
+// main_test.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/ingestion"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// runBatch runs the batch in-process with the given command-line arguments
+func runBatch(t *testing.T, args ...string) {
+	t.Helper()
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	savedArgs := os.Args
+	defer func() { os.Args = savedArgs }()
+	os.Args = append([]string{savedArgs[0]}, args...)
+	main()
+}
+
+// durationPattern matches the run duration, the one metric that varies between identical runs
+var durationPattern = regexp.MustCompile(`"duration_seconds": [0-9.e-]+`)
+
+// readOutputs returns the contents of every file in a directory by name
+func readOutputs(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading outputs: %v", err)
+	}
+	outputs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("reading %s: %v", entry.Name(), err)
+		}
+		outputs[entry.Name()] = durationPattern.ReplaceAllString(string(data), `"duration_seconds": 0`)
+	}
+	return outputs
+}
+
+func TestRerunReproducesOutputs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "serial", args: []string{"-workers", "1"}},
+		{name: "sharded", args: []string{"-workers", "2"}},
+		{name: "held and fees", args: []string{"-workers", "2", "-hold-insufficient", "-fees", "debit=1.50"}},
+		{name: "aggregated", args: []string{"-workers", "2", "-aggregate-below", "100", "-aggregate-keep-detail"}},
+		{name: "json", args: []string{"-workers", "2", "-hold-insufficient", "-format", "json"}},
+		{name: "anonymized", args: []string{"-workers", "2", "-hold-insufficient", "-anonymize", "-anonymize-salt", "pepper"}},
+		{
+			name: "reduced columns",
+			args: []string{"-workers", "2", "-hold-insufficient", "-tx-columns",
+				"transaction_id,account_id,timestamp,amount,type,status,processing_message,currency,original_amount," +
+					"original_currency,exchange_rate,destination_amount,balance_after,reversed_type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			args := append([]string{"-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+				"-output", outputDir}, tt.args...)
+
+			runBatch(t, args...)
+			first := readOutputs(t, outputDir)
+			runBatch(t, args...)
+			second := readOutputs(t, outputDir)
+
+			if len(first) != len(second) {
+				t.Fatalf("first run wrote %d files, second wrote %d", len(first), len(second))
+			}
+			for name, content := range first {
+				if second[name] != content {
+					t.Errorf("%s differs between runs:\nfirst:\n%s\nsecond:\n%s", name, content, second[name])
+				}
+			}
+
+			// Tokens are never tokenized again, so the mapping still leads back to account IDs
+			for _, line := range strings.Split(second["account_id_mapping_2025-04-15.csv"], "\n")[1:] {
+				if _, accountID, _ := strings.Cut(line, ","); strings.HasPrefix(accountID, "ANON") {
+					t.Errorf("mapping row %q maps a token to a token", line)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkerCountDoesNotChangeOutputs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "defaults"},
+		{name: "held and fees", args: []string{"-hold-insufficient", "-fees", "debit=1.50"}},
+		{name: "paged", args: []string{"-anomaly-page-size", "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := make(map[string]map[string]string)
+			for _, workers := range []string{"1", "4"} {
+				outputDir := t.TempDir()
+				runBatch(t, append([]string{"-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+					"-output", outputDir, "-workers", workers}, tt.args...)...)
+				outputs[workers] = readOutputs(t, outputDir)
+			}
+
+			serial, parallel := outputs["1"], outputs["4"]
+			if len(serial) != len(parallel) {
+				t.Fatalf("1 worker wrote %d files, 4 wrote %d", len(serial), len(parallel))
+			}
+			for name, content := range serial {
+				// The effective config records the worker count itself
+				if strings.HasPrefix(name, "effective_config_") {
+					continue
+				}
+				if parallel[name] != content {
+					t.Errorf("%s differs between 1 and 4 workers:\n1:\n%s\n4:\n%s", name, content, parallel[name])
+				}
+			}
+		})
+	}
+}
+
+func TestRerunKeepsPriorOutcomes(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		read   func(io.Reader) ([]models.Transaction, error)
+	}{
+		{name: "csv", format: "csv", read: ingestion.ReadProcessedTransactions},
+		{name: "json", format: "json", read: ingestion.ReadProcessedTransactionsJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			args := []string{"-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+				"-output", outputDir, "-format", tt.format}
+			runBatch(t, args...)
+
+			// A looser limit on the re-run would let TX1000027 through were its rejection not replayed
+			runBatch(t, append(args, "-overdraft-limit", "-100000")...)
+			file, err := os.Open(filepath.Join(outputDir, "processed_transactions_2025-04-15."+tt.format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			processed, err := tt.read(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, transaction := range processed {
+				if transaction.ID == "TX1000027" && transaction.Status != "rejected" {
+					t.Errorf("TX1000027 is %s on the re-run, want its prior rejection replayed", transaction.Status)
+				}
+			}
+		})
+	}
+}
+
+// TestRerunRequiresReplayColumns re-runs a batch whose processed transactions file lacks the
+// balances a replay checks. The batch exits, so the re-run happens in a child test process.
+func TestRerunRequiresReplayColumns(t *testing.T) {
+	outputDir := os.Getenv("RERUN_OUTPUT_DIR")
+	args := []string{"-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+		"-tx-columns", "transaction_id,account_id,timestamp,amount,type,status"}
+	if outputDir != "" {
+		runBatch(t, append(args, "-output", outputDir)...)
+		return
+	}
+
+	outputDir = t.TempDir()
+	runBatch(t, append(args, "-output", outputDir)...)
+	cmd := exec.Command(os.Args[0], "-test.run", "^TestRerunRequiresReplayColumns$")
+	cmd.Env = append(os.Environ(), "RERUN_OUTPUT_DIR="+outputDir)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("re-run succeeded, want it to abort:\n%s", out)
+	}
+	if !strings.Contains(string(out), "lacks the processing_message, currency") || strings.Contains(string(out), "does not match the prior run") {
+		t.Errorf("re-run output does not name the missing columns:\n%s", out)
+	}
+}
+
+// memoryStorage is an in-memory TransactionSource, AccountStore and ReportSink. Reports are kept
+// by path, and held transactions are read back from the reports the previous batch wrote.
+type memoryStorage struct {
+	mu           sync.Mutex
+	files        map[string][]byte
+	transactions map[string][]models.Transaction
+	accounts     map[string]map[string]models.Account
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		files:        make(map[string][]byte),
+		transactions: make(map[string][]models.Transaction),
+		accounts:     make(map[string]map[string]models.Account),
+	}
+}
+
+// use makes the batch read and write through the storage for the rest of the test
+func (m *memoryStorage) use(t *testing.T) {
+	savedSource, savedStore, savedSink := newTransactionSource, newAccountStore, reportSink
+	t.Cleanup(func() { newTransactionSource, newAccountStore, reportSink = savedSource, savedStore, savedSink })
+
+	newTransactionSource = func(path string, heldPath string, _ int, _ ingestion.LoaderConfig) models.TransactionSource {
+		return memorySource{storage: m, path: path, heldPath: heldPath}
+	}
+	newAccountStore = func(path string, fallbackPath string, savePath string) models.AccountStore {
+		return memoryAccountStore{storage: m, paths: []string{path, fallbackPath}, savePath: savePath}
+	}
+	reportSink = m
+}
+
+// memoryFile is a report being written, stored when it is closed
+type memoryFile struct {
+	bytes.Buffer
+	storage *memoryStorage
+	path    string
+}
+
+func (f *memoryFile) Close() error {
+	f.storage.mu.Lock()
+	defer f.storage.mu.Unlock()
+	f.storage.files[f.path] = f.Bytes()
+	return nil
+}
+
+func (m *memoryStorage) Create(path string) (io.WriteCloser, error) {
+	return &memoryFile{storage: m, path: path}, nil
+}
+
+func (m *memoryStorage) Open(path string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.files[path]
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Exists(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, exists := m.files[path]
+	return exists
+}
+
+func (m *memoryStorage) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, path)
+	return nil
+}
+
+func (m *memoryStorage) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[newPath] = m.files[oldPath]
+	delete(m.files, oldPath)
+	return nil
+}
+
+// outputs returns the stored reports by file name
+func (m *memoryStorage) outputs() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	outputs := make(map[string]string, len(m.files))
+	for path, data := range m.files {
+		outputs[filepath.Base(path)] = durationPattern.ReplaceAllString(string(data), `"duration_seconds": 0`)
+	}
+	return outputs
+}
+
+type memorySource struct {
+	storage  *memoryStorage
+	path     string
+	heldPath string
+}
+
+func (s memorySource) LoadTransactions() ([]models.Transaction, error) {
+	transactions, exists := s.storage.transactions[s.path]
+	if !exists {
+		return nil, fmt.Errorf("no transactions at %s", s.path)
+	}
+	return append([]models.Transaction(nil), transactions...), nil
+}
+
+func (s memorySource) LoadHeldTransactions() ([]models.Transaction, error) {
+	if s.heldPath == "" || !s.storage.Exists(s.heldPath) {
+		return nil, nil
+	}
+	file, err := s.storage.Open(s.heldPath)
+	if err != nil {
+		return nil, err
+	}
+	return ingestion.ReadProcessedTransactions(file)
+}
+
+type memoryAccountStore struct {
+	storage  *memoryStorage
+	paths    []string
+	savePath string
+}
+
+func (s memoryAccountStore) LoadAccounts() (map[string]models.Account, error) {
+	s.storage.mu.Lock()
+	defer s.storage.mu.Unlock()
+	for _, path := range s.paths {
+		if accounts, exists := s.storage.accounts[path]; exists {
+			result := make(map[string]models.Account, len(accounts))
+			for id, account := range accounts {
+				result[id] = account
+			}
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("no accounts at %v", s.paths)
+}
+
+func (s memoryAccountStore) SaveAccounts(accounts map[string]models.Account) error {
+	s.storage.mu.Lock()
+	defer s.storage.mu.Unlock()
+	s.storage.accounts[s.savePath] = accounts
+	return nil
+}
+
+func TestInMemoryStorage(t *testing.T) {
+	day := func(date string, clock string) time.Time {
+		timestamp, err := time.Parse(time.RFC3339, date+"T"+clock+"Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return timestamp
+	}
+	transaction := func(id string, account string, timestamp time.Time, amount float64, kind string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: account, Timestamp: timestamp, Amount: models.Cents(amount), Type: kind, Status: "pending"}
+	}
+	firstDay := []models.Transaction{
+		transaction("TX1", "ACC1", day("2025-04-15", "09:00:00"), 250, "credit"),
+		transaction("TX2", "ACC2", day("2025-04-15", "10:00:00"), 4000, "debit"), // Held: beyond the overdraft limit
+		transaction("TX3", "ACC1", day("2025-04-15", "11:00:00"), 20.5, "debit"),
+	}
+	secondDay := []models.Transaction{
+		transaction("TX4", "ACC2", day("2025-04-16", "09:00:00"), 5000, "credit"),
+		transaction("TX5", "ACC1", day("2025-04-16", "10:00:00"), 10, "debit"),
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "serial", args: []string{"-workers", "1"}},
+		{name: "sharded", args: []string{"-workers", "2"}},
+		{name: "expiring stale pending", args: []string{"-workers", "1", "-stale-pending", "expire"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			outputDir := filepath.Join(t.TempDir(), "output")
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{
+				"ACC1": {ID: "ACC1", Balance: models.Cents(100)},
+				"ACC2": {ID: "ACC2", Balance: models.Cents(50)},
+			}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = firstDay
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-16.csv")] = secondDay
+			run := func(date string, clock string) {
+				args := append([]string{"-input", "mem", "-output", outputDir, "-date", date, "-now", clock, "-hold-insufficient"}, tt.args...)
+				runBatch(t, args...)
+			}
+
+			// Day one holds TX2 and closes the accounts for day two to open from
+			run("2025-04-15", "2025-04-16T08:00:00Z")
+			if !strings.Contains(storage.outputs()["held_transactions_2025-04-15.csv"], "TX2") {
+				t.Fatal("day one did not hold TX2")
+			}
+			storage.accounts[filepath.Join("mem", "accounts_2025-04-16.csv")] = storage.accounts[filepath.Join(outputDir, "accounts_2025-04-16.csv")]
+
+			// Day two retries TX2 after the credit covers it, reading day one's summary back
+			run("2025-04-16", "2025-04-17T08:00:00Z")
+			first := storage.outputs()
+			processed, err := ingestion.ReadProcessedTransactions(strings.NewReader(first["processed_transactions_2025-04-16.csv"]))
+			if err != nil {
+				t.Fatalf("reading day two's processed transactions: %v", err)
+			}
+			retried := false
+			for _, transaction := range processed {
+				retried = retried || transaction.ID == "TX2" && transaction.Status == "completed"
+			}
+			if !retried {
+				t.Errorf("day two did not complete the held TX2: %+v", processed)
+			}
+			if strings.Contains(first["fraud_alerts_2025-04-16.csv"], "carry_forward_mismatch") {
+				t.Error("day two opening balances do not carry forward day one's closing balances")
+			}
+
+			// A re-run of day two replays its prior decisions from the stored ledger
+			run("2025-04-16", "2025-04-17T08:00:00Z")
+			second := storage.outputs()
+			for name, content := range first {
+				if second[name] != content {
+					t.Errorf("%s differs between runs:\nfirst:\n%s\nsecond:\n%s", name, content, second[name])
+				}
+			}
+
+			// Nothing touched the file system
+			if entries, err := os.ReadDir(outputDir); err != nil || len(entries) > 0 {
+				t.Errorf("output directory holds %d files (%v), want none", len(entries), err)
+			}
+		})
+	}
+}
+
+func TestPriorSummaryFromPreviousBusinessDay(t *testing.T) {
+	const header = "account_id,date,opening_balance,closing_balance,total_debits,total_credits,transaction_count,overdraft_count\n"
+	tests := []struct {
+		name         string
+		summaryDate  string
+		wantMismatch bool
+	}{
+		{name: "friday before a monday", summaryDate: "2025-04-11", wantMismatch: true},
+		{name: "calendar day before a monday", summaryDate: "2025-04-13", wantMismatch: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			outputDir := filepath.Join(t.TempDir(), "output")
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-14.csv")] = []models.Transaction{{
+				ID: "TX1", AccountID: "ACC1", Timestamp: time.Date(2025, 4, 14, 9, 0, 0, 0, time.UTC),
+				Amount: models.Cents(5), Type: "debit", Status: "pending",
+			}}
+			// The prior summary closed ACC1 at 999.00, not the 100.00 it opens at
+			storage.files[filepath.Join(outputDir, "account_summary_"+tt.summaryDate+".csv")] =
+				[]byte(header + "ACC1," + tt.summaryDate + ",999.00,999.00,0.00,0.00,0,0\n")
+
+			runBatch(t, "-input", "mem", "-output", outputDir, "-date", "2025-04-14", "-now", "2025-04-15T08:00:00Z")
+			alerts := storage.outputs()["fraud_alerts_2025-04-14.csv"]
+			if got := strings.Contains(alerts, "carry_forward_mismatch"); got != tt.wantMismatch {
+				t.Errorf("carry-forward mismatch flagged = %v, want %v\n%s", got, tt.wantMismatch, alerts)
+			}
+		})
+	}
+}
+
+func TestPinnedClock(t *testing.T) {
+	savedNow := now
+	defer func() { now = savedNow }()
+
+	tests := []struct {
+		name        string
+		now         string
+		wantDate    string // Default processing date
+		wantAccount string // Date the accounts file is written for
+	}{
+		{name: "weekday", now: "2025-04-16T08:00:00Z", wantDate: "2025-04-15", wantAccount: "2025-04-16"},
+		{name: "monday", now: "2025-04-14T08:00:00Z", wantDate: "2025-04-11", wantAccount: "2025-04-14"},
+		{name: "offset clock", now: "2025-04-15T23:30:00-04:00", wantDate: "2025-04-15", wantAccount: "2025-04-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			outputDir := filepath.Join(t.TempDir(), "output")
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+			storage.transactions[filepath.Join("mem", "transactions_"+tt.wantDate+".csv")] = []models.Transaction{{
+				ID: "TX1", AccountID: "ACC1", Timestamp: time.Date(2025, 4, 11, 9, 0, 0, 0, time.UTC),
+				Amount: models.Cents(5), Type: "debit", Status: "pending",
+			}}
+
+			// Run twice to show the pinned clock gives the same result each time
+			for run := 0; run < 2; run++ {
+				runBatch(t, "-input", "mem", "-output", outputDir, "-now", tt.now)
+				outputs := storage.outputs()
+				if _, exists := outputs["processed_transactions_"+tt.wantDate+".csv"]; !exists {
+					t.Fatalf("run %d wrote %v, want processed transactions for %s", run+1, slices.Sorted(maps.Keys(outputs)), tt.wantDate)
+				}
+				if _, exists := storage.accounts[filepath.Join(outputDir, "accounts_"+tt.wantAccount+".csv")]; !exists {
+					t.Errorf("run %d saved accounts %v, want them dated %s", run+1, slices.Sorted(maps.Keys(storage.accounts)), tt.wantAccount)
+				}
+			}
+		})
+	}
+}
+
+// TestInputDateCheck pairs the 2025-04-15 transactions file with a 2025-04-16 run. An aborting
+// batch exits, so each run happens in a child test process.
+func TestInputDateCheck(t *testing.T) {
+	if mode := os.Getenv("DATE_CHECK_MODE"); mode != "" {
+		runBatch(t, "-input", "data", "-date", "2025-04-16", "-now", "2026-04-17T08:00:00Z", "-output", t.TempDir(),
+			"-transactions", filepath.Join("data", "transactions_2025-04-15.csv"), "-date-check", mode)
+		return
+	}
+
+	tests := []struct {
+		mode     string
+		wantExit bool
+		wantWarn bool
+	}{
+		{mode: "off"},
+		{mode: "warn", wantWarn: true},
+		{mode: "abort", wantExit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run", "^TestInputDateCheck$")
+			cmd.Env = append(os.Environ(), "DATE_CHECK_MODE="+tt.mode)
+			out, err := cmd.CombinedOutput()
+			if got := err != nil; got != tt.wantExit {
+				t.Errorf("batch failed = %v, want %v:\n%s", got, tt.wantExit, out)
+			}
+			mismatch := strings.Contains(string(out), "Input file date mismatch: transactions_2025-04-15.csv is dated 2025-04-15, expected 2025-04-16")
+			if mismatch != (tt.wantWarn || tt.wantExit) {
+				t.Errorf("mismatch reported = %v, want %v:\n%s", mismatch, tt.wantWarn || tt.wantExit, out)
+			}
+		})
+	}
+}
+
+func TestFrozenAccountSources(t *testing.T) {
+	frozenFile := filepath.Join(t.TempDir(), "frozen_accounts.csv")
+	if err := os.WriteFile(frozenFile, []byte("account_id,reason\nACC3,investigation\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transfer := func(id, to string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: "ACC1", DestinationAccountID: to, Timestamp: timestamp,
+			Amount: models.Cents(10), Type: "transfer", Status: "pending"}
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantFrozen  []string
+		wantBalance models.Money // ACC1's closing balance
+	}{
+		{name: "none", wantBalance: models.Cents(70)},
+		{name: "flag", args: []string{"-frozen-accounts", "ACC2"}, wantFrozen: []string{"TX1"}, wantBalance: models.Cents(80)},
+		{name: "file", args: []string{"-frozen", frozenFile}, wantFrozen: []string{"TX2"}, wantBalance: models.Cents(80)},
+		{name: "both", args: []string{"-frozen-accounts", "ACC2", "-frozen", frozenFile}, wantFrozen: []string{"TX1", "TX2"},
+			wantBalance: models.Cents(90)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			outputDir := filepath.Join(t.TempDir(), "output")
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{
+				"ACC1": {ID: "ACC1", Balance: models.Cents(100)},
+				"ACC2": {ID: "ACC2"},
+				"ACC3": {ID: "ACC3"},
+				"ACC4": {ID: "ACC4"},
+			}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = []models.Transaction{
+				transfer("TX1", "ACC2"), transfer("TX2", "ACC3"), transfer("TX3", "ACC4"),
+			}
+
+			args := append([]string{"-input", "mem", "-output", outputDir, "-date", "2025-04-15", "-now", "2025-04-16T08:00:00Z"}, tt.args...)
+			runBatch(t, args...)
+			outputs := storage.outputs()
+			var frozen []string
+			for _, line := range strings.Split(outputs["invalid_transactions_2025-04-15.csv"], "\n") {
+				if strings.Contains(line, "Account frozen") {
+					frozen = append(frozen, strings.Split(line, ",")[0])
+				}
+			}
+			if !reflect.DeepEqual(frozen, tt.wantFrozen) {
+				t.Errorf("transactions rejected as frozen = %v, want %v", frozen, tt.wantFrozen)
+			}
+			if got := storage.accounts[filepath.Join(outputDir, "accounts_2025-04-16.csv")]["ACC1"].Balance; got != tt.wantBalance {
+				t.Errorf("ACC1 closing balance = %s, want %s", got, tt.wantBalance)
+			}
+		})
+	}
+}
+
+func TestConfiguredLimitsReachDetection(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(120), Type: "debit", Status: "pending"},
+		{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(100), Type: "debit", Status: "pending"},
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		wantTX2       string
+		wantNearLimit bool // TX1 leaves ACC1 at -70.00
+	}{
+		{name: "default limit", wantTX2: "completed"},
+		{name: "tighter limit", args: []string{"-overdraft-limit", "-100"}, wantTX2: "rejected", wantNearLimit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(50)}}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = transactions
+
+			args := append([]string{"-input", "mem", "-output", filepath.Join(t.TempDir(), "output"), "-date", "2025-04-15",
+				"-now", "2025-04-16T08:00:00Z"}, tt.args...)
+			runBatch(t, args...)
+			outputs := storage.outputs()
+			processed, err := ingestion.ReadProcessedTransactions(strings.NewReader(outputs["processed_transactions_2025-04-15.csv"]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, transaction := range processed {
+				if transaction.ID == "TX2" && transaction.Status != tt.wantTX2 {
+					t.Errorf("TX2 status = %s (%s), want %s", transaction.Status, transaction.ProcessingMessage, tt.wantTX2)
+				}
+			}
+			alerts := outputs["fraud_alerts_2025-04-15.csv"]
+			if got := strings.Contains(alerts, "TX1,ACC1,2025-04-15T09:00:00Z,near_limit"); got != tt.wantNearLimit {
+				t.Errorf("near_limit flagged = %v, want %v:\n%s", got, tt.wantNearLimit, alerts)
+			}
+		})
+	}
+}
+
+// steppedClock advances only when slept on
+type steppedClock struct {
+	now time.Time
+}
+
+func (c *steppedClock) Now() time.Time        { return c.now }
+func (c *steppedClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestAlertPacer(t *testing.T) {
+	start := time.Date(2025, 4, 16, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		intervalMs float64
+		jitterMs   float64
+		wantNil    bool
+		minGap     time.Duration
+		maxGap     time.Duration
+	}{
+		{name: "off", wantNil: true},
+		{name: "interval", intervalMs: 250, minGap: 250 * time.Millisecond, maxGap: 250 * time.Millisecond},
+		{name: "interval and jitter", intervalMs: 100, jitterMs: 50, minGap: 100 * time.Millisecond, maxGap: 150 * time.Millisecond},
+		{name: "jitter only", jitterMs: 20, minGap: 0, maxGap: 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &steppedClock{now: start}
+			pacer := alertPacer(tt.intervalMs, tt.jitterMs, 7, clock)
+			if (pacer == nil) != tt.wantNil {
+				t.Fatalf("alertPacer() = %v, want nil %v", pacer, tt.wantNil)
+			}
+			if pacer == nil {
+				return
+			}
+
+			pacer.Wait()
+			last := clock.now
+			for i := 0; i < 5; i++ {
+				pacer.Wait()
+				if gap := clock.now.Sub(last); gap < tt.minGap || gap > tt.maxGap {
+					t.Errorf("send %d came %v after the last, want %v to %v", i+2, gap, tt.minGap, tt.maxGap)
+				}
+				last = clock.now
+			}
+		})
+	}
+}
+
+// buildPlugin compiles the detector plugin fixture, skipping the test where plugins are unsupported
+func buildPlugin(t *testing.T) string {
+	t.Helper()
+	switch runtime.GOOS {
+	case "linux", "darwin", "freebsd":
+	default:
+		t.Skipf("plugins are not supported on %s", runtime.GOOS)
+	}
+	if testing.CoverMode() != "" {
+		t.Skip("plugins cannot match a coverage-instrumented test binary")
+	}
+	args := []string{"build", "-buildmode=plugin"}
+	if raceEnabled {
+		args = append(args, "-race")
+	}
+	path := filepath.Join(t.TempDir(), "rule.so")
+	cmd := exec.Command("go", append(args, "-o", path, "./detector/testdata/plugin")...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build plugins here: %v\n%s", err, out)
+	}
+	return path
+}
+
+func TestPluginRules(t *testing.T) {
+	pluginPath := buildPlugin(t)
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending", Tags: map[string]string{"review": "yes"}},
+		{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(10), Type: "debit", Status: "pending"},
+	}
+
+	tests := []struct {
+		name        string
+		plugins     string
+		wantFlagged bool
+	}{
+		{name: "no plugins"},
+		{name: "plugin", plugins: pluginPath, wantFlagged: true},
+		{name: "unloadable plugin skipped", plugins: filepath.Join(t.TempDir(), "missing.so") + "," + pluginPath, wantFlagged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = transactions
+
+			runBatch(t, "-input", "mem", "-output", filepath.Join(t.TempDir(), "output"), "-date", "2025-04-15",
+				"-now", "2025-04-16T08:00:00Z", "-plugins", tt.plugins)
+			alerts := storage.outputs()["fraud_alerts_2025-04-15.csv"]
+			var flagged []string
+			for _, line := range strings.Split(alerts, "\n") {
+				if strings.Contains(line, "tagged_for_review") {
+					flagged = append(flagged, strings.Split(line, ",")[1])
+				}
+			}
+			var want []string
+			if tt.wantFlagged {
+				want = []string{"TX1"}
+			}
+			if !reflect.DeepEqual(flagged, want) {
+				t.Errorf("plugin rule flagged %v, want %v:\n%s", flagged, want, alerts)
+			}
+		})
+	}
+}
+
+// TestPostHook runs the batch with a hook script that records its arguments and environment.
+// A strict batch exits when its hook fails, so that case runs in a child test process.
+func TestPostHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook is a shell script")
+	}
+	if hook := os.Getenv("POST_HOOK_COMMAND"); hook != "" {
+		runBatch(t, "-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+			"-output", os.Getenv("POST_HOOK_OUTPUT_DIR"), "-post-hook", hook, "-post-hook-strict")
+		return
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	record := filepath.Join(dir, "hook.out")
+	content := "#!/bin/sh\necho \"$@\" > " + record + "\necho \"$BATCH_DATE $BATCH_OUTPUT_DIR\" >> " + record + "\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(dir, "output")
+	runBatch(t, "-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z", "-output", outputDir,
+		"-post-hook", script+" --mode nightly")
+
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	want := "--mode nightly 2025-04-15 " + outputDir + "\n2025-04-15 " + outputDir + "\n"
+	if string(got) != want {
+		t.Errorf("hook recorded %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		hook     string
+		wantExit bool
+	}{
+		{name: "strict hook succeeds", hook: script},
+		{name: "strict hook fails", hook: "false", wantExit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run", "^TestPostHook$")
+			cmd.Env = append(os.Environ(), "POST_HOOK_COMMAND="+tt.hook, "POST_HOOK_OUTPUT_DIR="+t.TempDir())
+			out, err := cmd.CombinedOutput()
+			if got := err != nil; got != tt.wantExit {
+				t.Errorf("batch failed = %v, want %v:\n%s", got, tt.wantExit, out)
+			}
+			if tt.wantExit && !strings.Contains(string(out), "Post-processing hook failed") {
+				t.Errorf("batch output does not report the hook failure:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestManualReviewThreshold(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.use(t)
+	outputDir := filepath.Join(t.TempDir(), "output")
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100000)}}
+	storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(60000), Type: "debit", Status: "pending"},
+		{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(40000), Type: "credit", Status: "pending"},
+	}
+
+	runBatch(t, "-input", "mem", "-output", outputDir, "-date", "2025-04-15", "-now", "2025-04-16T08:00:00Z",
+		"-review-threshold", "50000")
+	outputs := storage.outputs()
+
+	tests := []struct {
+		name       string
+		file       string
+		wantStatus map[string]string
+	}{
+		{name: "processed", file: "processed_transactions_2025-04-15.csv", wantStatus: map[string]string{"TX1": "held", "TX2": "completed"}},
+		{name: "review file", file: "manual_review_2025-04-15.csv", wantStatus: map[string]string{"TX1": "held"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, err := ingestion.ReadProcessedTransactions(strings.NewReader(outputs[tt.file]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := make(map[string]string, len(transactions))
+			for _, transaction := range transactions {
+				status[transaction.ID] = transaction.Status
+				if transaction.Status == "held" && !strings.Contains(transaction.ProcessingMessage, "manual review required") {
+					t.Errorf("%s held with message %q", transaction.ID, transaction.ProcessingMessage)
+				}
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("statuses = %v, want %v", status, tt.wantStatus)
+			}
+		})
+	}
+	if got := storage.accounts[filepath.Join(outputDir, "accounts_2025-04-16.csv")]["ACC1"].Balance; got != models.Cents(140000) {
+		t.Errorf("ACC1 closing balance = %s, want %s", got, models.Cents(140000))
+	}
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	inputs := readOutputs(t, "data")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "defaults"},
+		{name: "every optional output", args: []string{"-hold-insufficient", "-review-threshold", "1000", "-dump-state", "-ledger",
+			"-hourly-histogram", "-overdraft-transitions", "-high-value", "1000", "-anonymize", "-anonymize-salt", "pepper"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			missingDir := filepath.Join(outputDir, "missing")
+			for _, dir := range []string{outputDir, missingDir} {
+				runBatch(t, append([]string{"-input", "data", "-date", "2025-04-15", "-now", "2026-04-16T08:00:00Z",
+					"-output", dir, "-dry-run"}, tt.args...)...)
+			}
+			if _, err := os.Stat(missingDir); !os.IsNotExist(err) {
+				t.Fatalf("dry run created the output directory (stat error %v)", err)
+			}
+			if outputs := readOutputs(t, outputDir); len(outputs) != 0 {
+				t.Errorf("dry run wrote %v", slices.Sorted(maps.Keys(outputs)))
+			}
+			if !reflect.DeepEqual(readOutputs(t, "data"), inputs) {
+				t.Error("dry run changed the input files")
+			}
+		})
+	}
+}
+
+func TestAutoCreateOnCredit(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		args         []string
+		wantAccounts []string
+	}{
+		{name: "disabled", wantAccounts: []string{"ACC1"}},
+		{name: "enabled", args: []string{"-autocreate-on-credit"}, wantAccounts: []string{"ACC1", "NEW1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.use(t)
+			outputDir := filepath.Join(t.TempDir(), "output")
+			storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+			storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = []models.Transaction{
+				{ID: "TX1", AccountID: "NEW1", Timestamp: timestamp, Amount: models.Cents(75), Type: "credit", Status: "pending"},
+				{ID: "TX2", AccountID: "NEW2", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending"},
+				{ID: "TX3", AccountID: "NEW3", DestinationAccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(10),
+					Type: "transfer", Status: "pending"},
+				{ID: "TX4", AccountID: "ACC1", DestinationAccountID: "NEW4", Timestamp: timestamp, Amount: models.Cents(10),
+					Type: "transfer", Status: "pending"},
+			}
+
+			runBatch(t, append([]string{"-input", "mem", "-output", outputDir, "-date", "2025-04-15", "-now", "2025-04-16T08:00:00Z"},
+				tt.args...)...)
+			closing := storage.accounts[filepath.Join(outputDir, "accounts_2025-04-16.csv")]
+			if got := slices.Sorted(maps.Keys(closing)); !reflect.DeepEqual(got, tt.wantAccounts) {
+				t.Fatalf("closing accounts = %v, want %v", got, tt.wantAccounts)
+			}
+			if balance := closing["ACC1"].Balance; balance != models.Cents(100) {
+				t.Errorf("ACC1 closing balance = %s, want 100.00", balance)
+			}
+			if account, exists := closing["NEW1"]; exists && account.Balance != models.Cents(75) {
+				t.Errorf("NEW1 closing balance = %s, want the credited 75.00", account.Balance)
+			}
+		})
+	}
+}
+
+func TestRunMetrics(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.use(t)
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	storage.accounts[filepath.Join("mem", "accounts.csv")] = map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(100)}}
+	storage.transactions[filepath.Join("mem", "transactions_2025-04-15.csv")] = []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(15000), Type: "credit", Status: "pending"},
+		{ID: "TX2", AccountID: "ACC1", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(30), Type: "debit", Status: "pending"},
+		{ID: "TX3", AccountID: "ACC1", Timestamp: timestamp.Add(2 * time.Hour), Amount: models.Cents(20000), Type: "debit", Status: "pending"},
+		{ID: "TX4", AccountID: "ACC9", Timestamp: timestamp, Amount: models.Cents(10), Type: "credit", Status: "pending"},
+	}
+
+	runBatch(t, "-input", "mem", "-output", filepath.Join(t.TempDir(), "output"), "-date", "2025-04-15", "-now", "2025-04-16T08:00:00Z")
+	outputs := storage.outputs()
+	var metrics models.Metrics
+	if err := json.Unmarshal([]byte(outputs["run_metrics_2025-04-15.json"]), &metrics); err != nil {
+		t.Fatalf("run metrics do not parse: %v\n%s", err, outputs["run_metrics_2025-04-15.json"])
+	}
+	if metrics.DurationSeconds < 0 {
+		t.Errorf("duration = %g, want a non-negative wall-clock time", metrics.DurationSeconds)
+	}
+	metrics.DurationSeconds = 0
+	want := models.Metrics{
+		Date:                  "2025-04-15",
+		LoadedTransactions:    4,
+		ValidTransactions:     3,
+		InvalidTransactions:   1,
+		ProcessedTransactions: 3,
+		CompletedTransactions: 2,
+		RejectedTransactions:  1,
+		Anomalies:             1, // TX1 is a large credit
+		TotalCredited:         models.Cents(15000),
+		TotalDebited:          models.Cents(30),
+	}
+	if metrics != want {
+		t.Errorf("run metrics = %+v, want %+v", metrics, want)
+	}
+}
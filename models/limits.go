@@ -0,0 +1,49 @@
+// models/limits.go
+package models
+
+// Default business-rule limits
+const (
+	DefaultOverdraftLimit            = -1000.0 // Maximum allowed overdraft
+	DefaultApprovedOverdraftLimit    = -5000.0 // Maximum overdraft for accounts with an arranged overdraft
+	DefaultMaxDailyWithdrawalLimit   = 5000.0  // Maximum daily withdrawal limit
+	DefaultLargeTransactionThreshold = 10000.0 // Transactions above this amount are considered large
+)
+
+// Limits holds the business-rule limits that processing enforces and detection measures against,
+// so both stages judge a transaction by the same policy
+type Limits struct {
+	OverdraftLimit            float64 `json:"overdraft_limit"`
+	ApprovedOverdraftLimit    float64 `json:"approved_overdraft_limit"` // Applies to accounts with ApprovedOverdraft set
+	MaxDailyWithdrawalLimit   float64 `json:"max_daily_withdrawal_limit"`
+	LargeTransactionThreshold float64 `json:"large_transaction_threshold"`
+
+	// Account types that may never go below zero, whatever their overdraft limit
+	NoOverdraftAccountTypes map[string]bool `json:"no_overdraft_account_types"`
+}
+
+// DefaultLimits returns the limits used when no config is supplied
+func DefaultLimits() Limits {
+	return Limits{
+		OverdraftLimit:            DefaultOverdraftLimit,
+		ApprovedOverdraftLimit:    DefaultApprovedOverdraftLimit,
+		MaxDailyWithdrawalLimit:   DefaultMaxDailyWithdrawalLimit,
+		LargeTransactionThreshold: DefaultLargeTransactionThreshold,
+		NoOverdraftAccountTypes:   map[string]bool{"savings": true},
+	}
+}
+
+// OverdraftLimitFor returns the lowest balance an account may reach: zero for account types
+// with no overdraft, its own limit when set, otherwise the standard limit, allowing accounts
+// with an arranged overdraft to go below it up to the approved ceiling
+func (l Limits) OverdraftLimitFor(account Account) Money {
+	if l.NoOverdraftAccountTypes[account.AccountType] {
+		return 0
+	}
+	if account.OverdraftLimit != 0 {
+		return account.OverdraftLimit
+	}
+	if account.ApprovedOverdraft && l.ApprovedOverdraftLimit < l.OverdraftLimit {
+		return Cents(l.ApprovedOverdraftLimit)
+	}
+	return Cents(l.OverdraftLimit)
+}
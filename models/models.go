@@ -1,35 +1,332 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Account represents a bank account
 type Account struct {
 	ID                  string    `json:"id"`
-	Balance             float64   `json:"balance"`
-	DailyDebits         float64   `json:"daily_debits"`
-	DailyCredits        float64   `json:"daily_credits"`
+	Balance             Money     `json:"balance"`
+	DailyDebits         Money     `json:"daily_debits"`
+	DailyCredits        Money     `json:"daily_credits"`
+	DailyTransfers      Money     `json:"daily_transfers"`
+	DailyTotalsDate     time.Time `json:"daily_totals_date,omitempty"` // Processing date the daily totals accumulate for
 	LastTransactionTime time.Time `json:"last_transaction_time"`
 	OverdraftCount      int       `json:"overdraft_count"`
+	AccountType         string    `json:"account_type,omitempty"`
+	HeldAmount          Money     `json:"held_amount,omitempty"`
+	Currency            string    `json:"currency,omitempty"`
+	ApprovedOverdraft   bool      `json:"approved_overdraft,omitempty"` // Arranged overdraft beyond the standard limit
+	ReservedBalance     Money     `json:"reserved_balance,omitempty"`   // Regulatory minimum reserve that cannot be spent
+	AccountOpenDate     time.Time `json:"account_open_date,omitempty"`
+	OverdraftLimit      Money     `json:"overdraft_limit,omitempty"` // Lowest balance this account may reach; 0 uses the configured limit
+}
+
+// AvailableBalance returns the balance not tied up in holds
+func (a Account) AvailableBalance() Money {
+	return a.Balance - a.HeldAmount
 }
 
 // Transaction represents a bank transaction
 type Transaction struct {
-	ID                   string    `json:"id"`
-	AccountID            string    `json:"account_id"`
-	DestinationAccountID string    `json:"destination_account_id,omitempty"`
-	Timestamp            time.Time `json:"timestamp"`
-	Amount               float64   `json:"amount"`
-	Type                 string    `json:"type"` // credit, debit, transfer
-	Status               string    `json:"status"`
-	Description          string    `json:"description,omitempty"`
-	ValidationMessage    string    `json:"validation_message,omitempty"`
-	ProcessingMessage    string    `json:"processing_message,omitempty"`
+	ID                    string            `json:"id"`
+	AccountID             string            `json:"account_id"`
+	DestinationAccountID  string            `json:"destination_account_id,omitempty"`
+	Timestamp             time.Time         `json:"timestamp"`
+	Amount                Money             `json:"amount"`
+	Type                  string            `json:"type"` // credit, debit, transfer, reversal
+	Status                string            `json:"status"`
+	Description           string            `json:"description,omitempty"`
+	ValidationMessage     string            `json:"validation_message,omitempty"`
+	ProcessingMessage     string            `json:"processing_message,omitempty"`
+	Currency              string            `json:"currency,omitempty"`
+	Tags                  map[string]string `json:"tags,omitempty"`
+	OriginalAmount        Money             `json:"original_amount,omitempty"`         // Amount before currency conversion
+	OriginalCurrency      string            `json:"original_currency,omitempty"`       // Currency before conversion
+	ExchangeRate          float64           `json:"exchange_rate,omitempty"`           // Rate applied to convert to the account currency
+	DestinationAmount     Money             `json:"destination_amount,omitempty"`      // Amount credited to a transfer destination in its currency, when converted
+	BalanceAfter          Money             `json:"balance_after"`                     // Source account balance once the transaction posted
+	OriginalTransactionID string            `json:"original_transaction_id,omitempty"` // Transaction a reversal undoes
+	ReversedType          string            `json:"reversed_type,omitempty"`           // Type of the transaction a reversal undid, set once it posts
+}
+
+// CreditedAmount returns the amount a transfer credits to its destination account
+func (t Transaction) CreditedAmount() Money {
+	if t.DestinationAmount != 0 {
+		return t.DestinationAmount
+	}
+	return t.Amount
+}
+
+// Reversed returns a completed reversal as the transaction it undid, whose legs the reversal
+// applies with the opposite sign
+func (t Transaction) Reversed() Transaction {
+	t.Type = t.ReversedType
+	return t
+}
+
+// Generated reports whether processing added the row itself, as a fee or the refund of a fee
+// when the transaction that incurred it was reversed, rather than posting a loaded transaction
+func (t Transaction) Generated() bool {
+	return t.Type == "fee" || t.Type == "reversal" && t.ReversedType == "fee"
+}
+
+// IDNormalizer normalizes account IDs so that the same account matches across input files
+type IDNormalizer struct {
+	Trim bool   `json:"trim"` // Remove leading and trailing whitespace
+	Case string `json:"case"` // "upper", "lower", or "" to keep the original case
+}
+
+// Normalize applies the configured normalization to an account ID
+func (n IDNormalizer) Normalize(id string) string {
+	if n.Trim {
+		id = strings.TrimSpace(id)
+	}
+	switch n.Case {
+	case "upper":
+		id = strings.ToUpper(id)
+	case "lower":
+		id = strings.ToLower(id)
+	}
+	return id
+}
+
+// ErrNonFiniteAmount is returned when an amount parses as NaN or infinity
+var ErrNonFiniteAmount = errors.New("amount must be a finite number")
+
+// ParseAmount parses a monetary amount, rejecting the NaN and Inf values strconv accepts
+func ParseAmount(s string) (float64, error) {
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("%q: %w", s, ErrNonFiniteAmount)
+	}
+	return amount, nil
+}
+
+// ColumnMap gives, for each column a loader expects, its position in an input file, or -1 when
+// the file does not have the column
+type ColumnMap []int
+
+// MapColumns matches a CSV header to the expected columns by name, ignoring case and surrounding
+// whitespace. The first required expected columns must be present; unknown columns are ignored.
+func MapColumns(header []string, expected []string, required int) (ColumnMap, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, exists := positions[name]; exists {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		positions[name] = i
+	}
+
+	columns := make(ColumnMap, len(expected))
+	for i, name := range expected {
+		position, exists := positions[name]
+		if !exists {
+			if i < required {
+				return nil, fmt.Errorf("missing required column %q", name)
+			}
+			position = -1
+		}
+		columns[i] = position
+	}
+	return columns, nil
+}
+
+// Reorder returns a record's values in expected column order, ending after the last expected
+// column the record has a value for. Columns missing in between are empty.
+func (c ColumnMap) Reorder(record []string) []string {
+	length := 0
+	for i, position := range c {
+		if position >= 0 && position < len(record) {
+			length = i + 1
+		}
+	}
+
+	reordered := make([]string, length)
+	for i := range reordered {
+		if position := c[i]; position >= 0 && position < len(record) {
+			reordered[i] = record[position]
+		}
+	}
+	return reordered
+}
+
+// Money is an amount of money in integer cents, so that repeated postings do not accumulate
+// floating-point rounding error
+type Money int64
+
+// ErrAmountOutOfRange is returned when an amount is too large to hold in Money
+var ErrAmountOutOfRange = errors.New("amount is out of range")
+
+// maxMoneyUnits bounds the currency units Money can hold: math.MaxInt64 cents
+const maxMoneyUnits = math.MaxInt64 / 100
+
+// Cents converts an amount in currency units to Money, rounding to the nearest cent. The amount
+// must be finite and within Money's range; amounts read from input go through ParseMoney, which
+// checks this.
+func Cents(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+// checkMoney rejects amounts Cents cannot convert
+func checkMoney(amount float64) error {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return ErrNonFiniteAmount
+	}
+	if math.Abs(amount) >= maxMoneyUnits {
+		return ErrAmountOutOfRange
+	}
+	return nil
+}
+
+// ParseMoney parses a monetary amount into Money, rounding to the nearest cent, and rejects
+// non-finite amounts and those beyond Money's range
+func ParseMoney(s string) (Money, error) {
+	amount, err := ParseAmount(s)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkMoney(amount); err != nil {
+		return 0, fmt.Errorf("%q: %w", s, err)
+	}
+	return Cents(amount), nil
+}
+
+// Float returns the amount in currency units
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// Mul returns the amount multiplied by a factor, rounded to the nearest cent
+func (m Money) Mul(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Abs returns the absolute amount
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// String formats the amount with two decimal places, e.g. -12.05
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+	}
+	abs := uint64(m.Abs())
+	return fmt.Sprintf("%s%d.%02d", sign, abs/100, abs%100)
+}
+
+// MarshalJSON writes the amount as a JSON number in currency units
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number in currency units
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount float64
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+	if err := checkMoney(amount); err != nil {
+		return fmt.Errorf("%s: %w", data, err)
+	}
+	*m = Cents(amount)
+	return nil
+}
+
+// ParseError describes an input record that could not be parsed
+type ParseError struct {
+	LineNumber int      `json:"line_number"`
+	Field      string   `json:"field"`  // Field that failed, or "" when the record as a whole is malformed
+	Record     []string `json:"record"` // Fields as they appear in the file
+	Err        error    `json:"-"`
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid record at line %d: %v", e.LineNumber, e.Err)
+	}
+	return fmt.Sprintf("invalid %s at line %d: %v", e.Field, e.LineNumber, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON includes the underlying error message, which the error value itself cannot carry
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	type parseError ParseError
+	return json.Marshal(struct {
+		*parseError
+		Error string `json:"error"`
+	}{(*parseError)(e), fmt.Sprint(e.Err)})
+}
+
+// ParseErrors collects the records a loader skipped; loaders return it alongside the records
+// that did parse
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d records could not be parsed, first: %v", len(e), e[0])
+}
+
+// ParseTags parses transaction tags serialized as "k1=v1;k2=v2"
+func ParseTags(serialized string) (map[string]string, error) {
+	if strings.TrimSpace(serialized) == "" {
+		return nil, nil
+	}
+
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(serialized, ";") {
+		if pair == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid tag %q: expected key=value", pair)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// FormatTags serializes transaction tags as "k1=v1;k2=v2" in key order
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + tags[key]
+	}
+	return strings.Join(pairs, ";")
 }
 
 // Anomaly represents a detected anomaly in transaction processing
 type Anomaly struct {
+	ID            string    `json:"id"`
 	TransactionID string    `json:"transaction_id"`
 	AccountID     string    `json:"account_id"`
 	Timestamp     time.Time `json:"timestamp"`
@@ -38,14 +335,121 @@ type Anomaly struct {
 	Severity      string    `json:"severity"` // low, medium, high
 }
 
+// AnomalySummary represents the rollup of detected anomalies of one type
+type AnomalySummary struct {
+	Type                  string         `json:"type"`
+	Count                 int            `json:"count"`
+	SeverityCounts        map[string]int `json:"severity_counts"`
+	ExampleTransactionIDs []string       `json:"example_transaction_ids"`
+}
+
+// Event represents an informational record emitted during processing, distinct from anomalies
+type Event struct {
+	TransactionID string    `json:"transaction_id"`
+	AccountID     string    `json:"account_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description"`
+}
+
+// CurrencyReconciliation represents the money conservation check for one currency
+type CurrencyReconciliation struct {
+	Currency        string `json:"currency"`
+	OpeningTotal    Money  `json:"opening_total"`
+	ClosingTotal    Money  `json:"closing_total"`
+	NetTransactions Money  `json:"net_transactions"`
+	Difference      Money  `json:"difference"`
+	Balanced        bool   `json:"balanced"`
+}
+
 // AccountSummary represents a daily summary for an account
 type AccountSummary struct {
-	AccountID        string  `json:"account_id"`
-	Date             string  `json:"date"`
-	OpeningBalance   float64 `json:"opening_balance"`
-	ClosingBalance   float64 `json:"closing_balance"`
-	TotalDebits      float64 `json:"total_debits"`
-	TotalCredits     float64 `json:"total_credits"`
-	TransactionCount int     `json:"transaction_count"`
-	OverdraftCount   int     `json:"overdraft_count"`
+	AccountID        string `json:"account_id"`
+	Date             string `json:"date"`
+	OpeningBalance   Money  `json:"opening_balance"`
+	ClosingBalance   Money  `json:"closing_balance"`
+	TotalDebits      Money  `json:"total_debits"`
+	TotalCredits     Money  `json:"total_credits"`
+	TransactionCount int    `json:"transaction_count"` // Transactions posted from the account, each counted once; fee rows are not counted
+	OverdraftCount   int    `json:"overdraft_count"`
+	TransfersIn      int    `json:"transfers_in"` // Transfer destination legs posted to the account, counted apart from TransactionCount
+
+	// Holds expiring within the configured horizon
+	ExpiringHoldsCount  int   `json:"expiring_holds_count"`
+	ExpiringHoldsAmount Money `json:"expiring_holds_amount"`
+}
+
+// BalanceDelta represents the change in an account's closing balance after reprocessing
+type BalanceDelta struct {
+	AccountID        string `json:"account_id"`
+	OriginalBalance  Money  `json:"original_balance"`
+	CorrectedBalance Money  `json:"corrected_balance"`
+	Difference       Money  `json:"difference"`
+}
+
+// OverdraftTransition represents an account that entered or left overdraft during the day
+type OverdraftTransition struct {
+	AccountID      string `json:"account_id"`
+	OpeningBalance Money  `json:"opening_balance"`
+	ClosingBalance Money  `json:"closing_balance"`
+}
+
+// LedgerEntry represents one account-impacting leg of a completed transaction; the legs of a
+// transfer share its GroupID and net to zero when no currency conversion applies
+type LedgerEntry struct {
+	GroupID   string    `json:"group_id"` // ID of the transaction the leg belongs to
+	Leg       int       `json:"leg"`      // 1 for the source leg, 2 for a transfer's destination leg
+	AccountID string    `json:"account_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Amount    Money     `json:"amount"` // Signed: negative debits the account
+}
+
+// Metrics summarizes a batch run for monitoring
+type Metrics struct {
+	Date                  string  `json:"date"`
+	LoadedTransactions    int     `json:"loaded_transactions"`
+	UnparseableRecords    int     `json:"unparseable_records"`
+	ValidTransactions     int     `json:"valid_transactions"`
+	InvalidTransactions   int     `json:"invalid_transactions"`
+	AwaitingApproval      int     `json:"awaiting_approval"`      // Valid transactions held for a manual approval decision
+	ProcessedTransactions int     `json:"processed_transactions"` // Includes generated fee rows
+	CompletedTransactions int     `json:"completed_transactions"`
+	RejectedTransactions  int     `json:"rejected_transactions"`
+	HeldTransactions      int     `json:"held_transactions"`
+	Anomalies             int     `json:"anomalies"`
+	TotalCredited         Money   `json:"total_credited"` // Completed credits, net of their reversals
+	TotalDebited          Money   `json:"total_debited"`  // Completed debits and fees, net of their reversals
+	DurationSeconds       float64 `json:"duration_seconds"`
+}
+
+// Hold represents funds held on an account, placed at a point in time
+type Hold struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	Amount    Money     `json:"amount"`
+	PlacedAt  time.Time `json:"placed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HourlyHistogram represents an account's completed transaction counts by hour of day
+type HourlyHistogram struct {
+	AccountID string  `json:"account_id"`
+	Counts    [24]int `json:"counts"`
+}
+
+// ConfigSetting represents a single rule value in effect for a run
+type ConfigSetting struct {
+	Component    string `json:"component"`
+	Name         string `json:"name"`
+	Value        string `json:"value"`
+	DefaultValue string `json:"default_value"`
+	Overridden   bool   `json:"overridden"`
+}
+
+// State is a snapshot of the pipeline's accounts and transactions at one stage of a run
+type State struct {
+	Stage        string             `json:"stage"`
+	Accounts     map[string]Account `json:"accounts"`
+	Transactions []Transaction      `json:"transactions"`
 }
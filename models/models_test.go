@@ -0,0 +1,155 @@
+// models/models_test.go
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "0", want: 0},
+		{input: "12.05", want: 1205},
+		{input: "-12.05", want: -1205},
+		{input: "0.1", want: 10},
+		{input: "1234567.89", want: 123456789},
+		{input: "0.005", want: 1},
+		{input: "-0.005", want: -1},
+		{input: "19.999", want: 2000},
+		{input: "1e3", want: 100000},
+		{input: "abc", wantErr: true},
+		{input: "NaN", wantErr: true},
+		{input: "Inf", wantErr: true},
+		{input: "-Inf", wantErr: true},
+		{input: "92233720368547", want: 9223372036854700},
+		{input: "92233720368547758.08", wantErr: true},
+		{input: "-92233720368547758.08", wantErr: true},
+		{input: "1e300", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d cents, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: 0, want: "0.00"},
+		{money: 5, want: "0.05"},
+		{money: -5, want: "-0.05"},
+		{money: 1205, want: "12.05"},
+		{money: -100000, want: "-1000.00"},
+		{money: 123456789, want: "1234567.89"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			parsed, err := ParseMoney(tt.money.String())
+			if err != nil || parsed != tt.money {
+				t.Errorf("ParseMoney(String()) = %d, %v, want %d", parsed, err, tt.money)
+			}
+		})
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name   string
+		money  Money
+		factor float64
+		want   Money
+	}{
+		{name: "exact", money: 10000, factor: 0.015, want: 150},
+		{name: "rounds down", money: 1234, factor: 0.001, want: 1},
+		{name: "rounds half away from zero", money: 5, factor: 0.5, want: 3},
+		{name: "negative half away from zero", money: -5, factor: 0.5, want: -3},
+		{name: "exchange rate", money: 10000, factor: 1.0857, want: 10857},
+		{name: "zero factor", money: 999, factor: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Mul(tt.factor); got != tt.want {
+				t.Errorf("%d.Mul(%g) = %d, want %d", tt.money, tt.factor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		money Money
+		json  string
+	}{
+		{money: 0, json: "0.00"},
+		{money: 1, json: "0.01"},
+		{money: -1205, json: "-12.05"},
+		{money: 123456789, json: "1234567.89"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			data, err := json.Marshal(tt.money)
+			if err != nil || string(data) != tt.json {
+				t.Fatalf("Marshal = %s, %v, want %s", data, err, tt.json)
+			}
+			var decoded Money
+			if err := json.Unmarshal(data, &decoded); err != nil || decoded != tt.money {
+				t.Errorf("Unmarshal(%s) = %d, %v, want %d", data, decoded, err, tt.money)
+			}
+		})
+	}
+
+	// Amounts Money cannot hold are rejected
+	for _, data := range []string{"1e300", "-92233720368547758.08"} {
+		var decoded Money
+		if err := json.Unmarshal([]byte(data), &decoded); !errors.Is(err, ErrAmountOutOfRange) {
+			t.Errorf("Unmarshal(%s) error = %v, want ErrAmountOutOfRange", data, err)
+		}
+	}
+
+	// Fields survive a struct round trip
+	transaction := Transaction{ID: "T1", Amount: 1999, DestinationAmount: 1843, BalanceAfter: -105}
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Transaction
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Amount != transaction.Amount || decoded.DestinationAmount != transaction.DestinationAmount ||
+		decoded.BalanceAfter != transaction.BalanceAfter {
+		t.Errorf("round trip gave %+v, want %+v", decoded, transaction)
+	}
+}
+
+func TestMoneyAccumulatesExactly(t *testing.T) {
+	var balance Money
+	var floatBalance float64
+	for i := 0; i < 1000; i++ {
+		balance += Cents(0.1)
+		floatBalance += 0.1
+	}
+	if balance != 10000 {
+		t.Errorf("balance after 1000 postings of 0.10 = %s, want 100.00", balance)
+	}
+	if floatBalance == 100 {
+		t.Error("float64 accumulation did not drift; the comparison no longer shows the problem")
+	}
+}
@@ -0,0 +1,27 @@
+// models/storage.go
+package models
+
+import "io"
+
+// TransactionSource supplies the day's raw transactions and those the previous batch held over
+// for retry, so they can come from somewhere other than local files
+type TransactionSource interface {
+	LoadTransactions() ([]Transaction, error)
+	LoadHeldTransactions() ([]Transaction, error) // Empty when the previous batch held none
+}
+
+// AccountStore loads the opening account state and saves the closing state
+type AccountStore interface {
+	LoadAccounts() (map[string]Account, error)
+	SaveAccounts(accounts map[string]Account) error
+}
+
+// ReportSink stores the files the output Write functions produce and reads back those a prior
+// run left, so reports can go somewhere other than the local file system
+type ReportSink interface {
+	Create(path string) (io.WriteCloser, error) // Open a report for writing, replacing any existing one
+	Open(path string) (io.ReadCloser, error)
+	Exists(path string) bool
+	Rename(oldPath, newPath string) error
+	Remove(path string) error
+}
@@ -0,0 +1,75 @@
+// output/aggregate.go
+package output
+
+import (
+	"fmt"
+	"strconv"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// AggregateMicroTransactions rolls completed transactions below threshold into one record per
+// account and type (and destination when byCounterparty is set). Each rolled-up record takes the
+// place of the group's first transaction and carries the group's count and total; groups with a
+// single transaction are left as is, as are reversals so they keep their reference to the
+// original. A threshold of 0 disables aggregation.
+func AggregateMicroTransactions(transactions []models.Transaction, threshold float64, byCounterparty bool) []models.Transaction {
+	if threshold <= 0 {
+		return transactions
+	}
+
+	groupKey := func(transaction models.Transaction) string {
+		key := transaction.AccountID + "|" + transaction.Type
+		if byCounterparty {
+			key += "|" + transaction.DestinationAccountID
+		}
+		return key
+	}
+	isMicro := func(transaction models.Transaction) bool {
+		return transaction.Status == "completed" && transaction.Type != "reversal" && transaction.Amount < models.Cents(threshold)
+	}
+
+	// Count group members so singletons pass through untouched
+	counts := make(map[string]int)
+	for _, transaction := range transactions {
+		if isMicro(transaction) {
+			counts[groupKey(transaction)]++
+		}
+	}
+
+	aggregated := make([]models.Transaction, 0, len(transactions))
+	position := make(map[string]int)
+	for _, transaction := range transactions {
+		key := groupKey(transaction)
+		if !isMicro(transaction) || counts[key] < 2 {
+			aggregated = append(aggregated, transaction)
+			continue
+		}
+
+		i, exists := position[key]
+		if !exists {
+			rollup := models.Transaction{
+				ID:        "AGG-" + transaction.ID,
+				AccountID: transaction.AccountID,
+				Type:      transaction.Type,
+				Status:    "completed",
+				Currency:  transaction.Currency,
+				Tags:      map[string]string{"aggregated_count": strconv.Itoa(counts[key])},
+			}
+			if byCounterparty {
+				rollup.DestinationAccountID = transaction.DestinationAccountID
+			}
+			i = len(aggregated)
+			position[key] = i
+			aggregated = append(aggregated, rollup)
+		}
+
+		aggregated[i].Amount += transaction.Amount
+		aggregated[i].Timestamp = transaction.Timestamp
+		aggregated[i].BalanceAfter = transaction.BalanceAfter
+		aggregated[i].Description = fmt.Sprintf("Aggregated %d transactions below $%.2f totaling $%s",
+			counts[key], threshold, aggregated[i].Amount)
+	}
+
+	return aggregated
+}
@@ -0,0 +1,61 @@
+// output/aggregate_test.go
+package output
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestAggregateMicroTransactions(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	var transactions []models.Transaction
+	for i := 0; i < 150; i++ {
+		destination := "ACC2"
+		if i%3 == 0 {
+			destination = "ACC3"
+		}
+		transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("TX%03d", i), AccountID: "ACC1", DestinationAccountID: destination,
+			Timestamp: start.Add(time.Duration(i) * time.Minute), Amount: models.Cents(0.99), Type: "debit", Status: "completed"})
+	}
+	transactions = append(transactions,
+		models.Transaction{ID: "BIG", AccountID: "ACC1", Timestamp: start, Amount: models.Cents(250), Type: "debit", Status: "completed"},
+		models.Transaction{ID: "REJ", AccountID: "ACC1", Timestamp: start, Amount: models.Cents(0.5), Type: "debit", Status: "rejected"},
+		models.Transaction{ID: "ONE", AccountID: "ACC1", Timestamp: start, Amount: models.Cents(0.5), Type: "credit", Status: "completed"},
+	)
+
+	tests := []struct {
+		name           string
+		byCounterparty bool
+		want           map[string][2]string // Rollup ID to its count and total
+	}{
+		{name: "by account and type", want: map[string][2]string{"AGG-TX000": {"150", "148.50"}}},
+		{name: "by counterparty", byCounterparty: true, want: map[string][2]string{"AGG-TX000": {"50", "49.50"}, "AGG-TX001": {"100", "99.00"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregated := AggregateMicroTransactions(transactions, 1, tt.byCounterparty)
+			if len(aggregated) != len(tt.want)+3 {
+				t.Fatalf("%d records, want %d rollups and the 3 left as is", len(aggregated), len(tt.want))
+			}
+			for _, transaction := range aggregated {
+				want, isRollup := tt.want[transaction.ID]
+				if !isRollup {
+					if transaction.ID != "BIG" && transaction.ID != "REJ" && transaction.ID != "ONE" {
+						t.Errorf("%s was not aggregated", transaction.ID)
+					}
+					continue
+				}
+				if got := [2]string{transaction.Tags["aggregated_count"], transaction.Amount.String()}; got != want {
+					t.Errorf("%s count and total = %v, want %v", transaction.ID, got, want)
+				}
+			}
+		})
+	}
+
+	if got := AggregateMicroTransactions(transactions, 0, false); len(got) != len(transactions) {
+		t.Errorf("threshold 0 aggregated to %d records", len(got))
+	}
+}
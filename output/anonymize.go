@@ -0,0 +1,231 @@
+// output/anonymize.go
+package output
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// Anonymizer replaces account IDs with consistent salted tokens.
+// A nil Anonymizer leaves all data unchanged.
+type Anonymizer struct {
+	mu     sync.Mutex
+	salt   []byte
+	tokens map[string]string
+}
+
+// NewAnonymizer creates an anonymizer using the given salt
+func NewAnonymizer(salt []byte) *Anonymizer {
+	return &Anonymizer{
+		salt:   salt,
+		tokens: make(map[string]string),
+	}
+}
+
+// Token returns the pseudonym for an account ID, the same ID always yielding the same token
+func (a *Anonymizer) Token(accountID string) string {
+	if a == nil || accountID == "" {
+		return accountID
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if token, exists := a.tokens[accountID]; exists {
+		return token
+	}
+
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(accountID))
+	token := "ANON" + hex.EncodeToString(mac.Sum(nil))[:16]
+	a.tokens[accountID] = token
+	return token
+}
+
+// Accounts returns a copy of the accounts keyed and labeled by token
+func (a *Anonymizer) Accounts(accounts map[string]models.Account) map[string]models.Account {
+	if a == nil {
+		return accounts
+	}
+	result := make(map[string]models.Account, len(accounts))
+	for id, account := range accounts {
+		account.ID = a.Token(account.ID)
+		result[a.Token(id)] = account
+	}
+	return result
+}
+
+// Transactions returns a copy of the transactions with source and destination tokenized
+func (a *Anonymizer) Transactions(transactions []models.Transaction) []models.Transaction {
+	if a == nil {
+		return transactions
+	}
+	result := make([]models.Transaction, len(transactions))
+	for i, transaction := range transactions {
+		transaction.AccountID = a.Token(transaction.AccountID)
+		transaction.DestinationAccountID = a.Token(transaction.DestinationAccountID)
+		result[i] = transaction
+	}
+	return result
+}
+
+// Anomalies returns a copy of the anomalies with account IDs tokenized
+func (a *Anonymizer) Anomalies(anomalies []models.Anomaly) []models.Anomaly {
+	if a == nil {
+		return anomalies
+	}
+	result := make([]models.Anomaly, len(anomalies))
+	for i, anomaly := range anomalies {
+		anomaly.AccountID = a.Token(anomaly.AccountID)
+		result[i] = anomaly
+	}
+	return result
+}
+
+// Events returns a copy of the events with account IDs tokenized
+func (a *Anonymizer) Events(events []models.Event) []models.Event {
+	if a == nil {
+		return events
+	}
+	result := make([]models.Event, len(events))
+	for i, event := range events {
+		event.AccountID = a.Token(event.AccountID)
+		result[i] = event
+	}
+	return result
+}
+
+// Summaries returns a copy of the account summaries with account IDs tokenized
+func (a *Anonymizer) Summaries(summaries []models.AccountSummary) []models.AccountSummary {
+	if a == nil {
+		return summaries
+	}
+	result := make([]models.AccountSummary, len(summaries))
+	for i, summary := range summaries {
+		summary.AccountID = a.Token(summary.AccountID)
+		result[i] = summary
+	}
+	return result
+}
+
+// Histograms returns copies of the hourly histograms with account IDs replaced by tokens
+func (a *Anonymizer) Histograms(histograms []models.HourlyHistogram) []models.HourlyHistogram {
+	if a == nil {
+		return histograms
+	}
+	result := make([]models.HourlyHistogram, len(histograms))
+	for i, histogram := range histograms {
+		histogram.AccountID = a.Token(histogram.AccountID)
+		result[i] = histogram
+	}
+	return result
+}
+
+// BalanceDeltas returns copies of the balance deltas with account IDs replaced by tokens
+func (a *Anonymizer) BalanceDeltas(deltas []models.BalanceDelta) []models.BalanceDelta {
+	if a == nil {
+		return deltas
+	}
+	result := make([]models.BalanceDelta, len(deltas))
+	for i, delta := range deltas {
+		delta.AccountID = a.Token(delta.AccountID)
+		result[i] = delta
+	}
+	return result
+}
+
+// OverdraftTransitions returns copies of the overdraft transitions with account IDs replaced by tokens
+func (a *Anonymizer) OverdraftTransitions(transitions []models.OverdraftTransition) []models.OverdraftTransition {
+	if a == nil {
+		return transitions
+	}
+	result := make([]models.OverdraftTransition, len(transitions))
+	for i, transition := range transitions {
+		transition.AccountID = a.Token(transition.AccountID)
+		result[i] = transition
+	}
+	return result
+}
+
+// Ledger returns copies of the ledger entries with account IDs replaced by tokens
+func (a *Anonymizer) Ledger(entries []models.LedgerEntry) []models.LedgerEntry {
+	if a == nil {
+		return entries
+	}
+	result := make([]models.LedgerEntry, len(entries))
+	for i, entry := range entries {
+		entry.AccountID = a.Token(entry.AccountID)
+		result[i] = entry
+	}
+	return result
+}
+
+// WriteMapping writes the token to account ID mapping to a CSV file readable only by the owner
+func (a *Anonymizer) WriteMapping(filePath string) (err error) {
+	file, err := withRetry(filePath, func() (*os.File, error) {
+		return os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	})
+	if err != nil {
+		return fmt.Errorf("error creating mapping file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	if err := writer.Write([]string{"token", "account_id"}); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write mapping data in a stable order
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	accountIDs := make([]string, 0, len(a.tokens))
+	for accountID := range a.tokens {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	for _, accountID := range accountIDs {
+		if err := writer.Write([]string{a.tokens[accountID], accountID}); err != nil {
+			return fmt.Errorf("error writing mapping record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LoadMapping loads a token to account ID mapping written by WriteMapping
+func LoadMapping(filePath string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening mapping file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	mapping := make(map[string]string)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+		mapping[record[0]] = record[1]
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,92 @@
+// output/anonymize_test.go
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestAnonymizerTokens(t *testing.T) {
+	anonymizer := NewAnonymizer([]byte("pepper"))
+	transactions := anonymizer.Transactions([]models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", DestinationAccountID: "ACC2", Type: "transfer"},
+		{ID: "TX2", AccountID: "ACC2", DestinationAccountID: "ACC1", Type: "transfer"},
+		{ID: "TX3", AccountID: "ACC1", Type: "debit"},
+	})
+	accounts := anonymizer.Accounts(map[string]models.Account{"ACC1": {ID: "ACC1"}, "ACC2": {ID: "ACC2"}})
+	anomalies := anonymizer.Anomalies([]models.Anomaly{{TransactionID: "TX3", AccountID: "ACC1"}})
+
+	acc1, acc2 := anonymizer.Token("ACC1"), anonymizer.Token("ACC2")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "transfer source", got: transactions[0].AccountID, want: acc1},
+		{name: "transfer destination", got: transactions[0].DestinationAccountID, want: acc2},
+		{name: "reverse transfer source", got: transactions[1].AccountID, want: acc2},
+		{name: "reverse transfer destination", got: transactions[1].DestinationAccountID, want: acc1},
+		{name: "debit", got: transactions[2].AccountID, want: acc1},
+		{name: "no destination", got: transactions[2].DestinationAccountID, want: ""},
+		{name: "account key", got: accounts[acc1].ID, want: acc1},
+		{name: "anomaly", got: anomalies[0].AccountID, want: acc1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("token = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if acc1 == acc2 || strings.Contains(acc1, "ACC1") {
+		t.Errorf("tokens %q and %q do not hide distinct account IDs", acc1, acc2)
+	}
+	if other := NewAnonymizer([]byte("salt")).Token("ACC1"); other == acc1 {
+		t.Errorf("different salts gave the same token %q", other)
+	}
+	if again := NewAnonymizer([]byte("pepper")).Token("ACC1"); again != acc1 {
+		t.Errorf("the same salt gave tokens %q and %q", again, acc1)
+	}
+
+	var none *Anonymizer
+	if got := none.Transactions(transactions[:1]); got[0].AccountID != acc1 {
+		t.Error("a nil anonymizer changed the transactions")
+	}
+}
+
+func TestAnonymizerMappingReverses(t *testing.T) {
+	anonymizer := NewAnonymizer([]byte("pepper"))
+	ids := []string{"ACC1", "ACC2", "ACC3"}
+	for _, id := range ids {
+		anonymizer.Token(id)
+	}
+
+	path := filepath.Join(t.TempDir(), "mapping.csv")
+	if err := anonymizer.WriteMapping(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mapping file mode = %v, want 0600", info.Mode().Perm())
+	}
+	mapping, err := LoadMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping) != len(ids) {
+		t.Errorf("mapping has %d entries, want %d", len(mapping), len(ids))
+	}
+	for _, id := range ids {
+		if got := mapping[anonymizer.Token(id)]; got != id {
+			t.Errorf("mapping reverses the token for %s to %q", id, got)
+		}
+	}
+}
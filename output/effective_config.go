@@ -0,0 +1,137 @@
+// output/effective_config.go
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// CollectConfigSettings lists every field of a config struct alongside its default value
+func CollectConfigSettings(component string, config interface{}, defaults interface{}) []models.ConfigSetting {
+	configValue := reflect.ValueOf(config)
+	defaultValue := reflect.ValueOf(defaults)
+	if configValue.Kind() != reflect.Struct || configValue.Type() != defaultValue.Type() {
+		return nil
+	}
+
+	settings := make([]models.ConfigSetting, 0, configValue.NumField())
+	for i := 0; i < configValue.NumField(); i++ {
+		field := configValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Prefer the JSON tag name so settings match the serialized config, leaving out fields
+		// the config does not serialize
+		name := field.Name
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag != "" {
+			name = tag
+		}
+
+		// Flatten embedded structs the way encoding/json does
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			settings = append(settings, CollectConfigSettings(component, configValue.Field(i).Interface(), defaultValue.Field(i).Interface())...)
+			continue
+		}
+
+		value := formatConfigValue(configValue.Field(i).Interface())
+		defaultVal := formatConfigValue(defaultValue.Field(i).Interface())
+
+		settings = append(settings, models.ConfigSetting{
+			Component:    component,
+			Name:         name,
+			Value:        value,
+			DefaultValue: defaultVal,
+			Overridden:   value != defaultVal,
+		})
+	}
+
+	return settings
+}
+
+// formatConfigValue renders a config value as a string
+func formatConfigValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts[i] = formatConfigValue(v.Index(i).Interface())
+		}
+		return strings.Join(parts, ";")
+	case reflect.Map:
+		// Render maps in sorted key order; boolean sets list only their members
+		parts := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			element := v.MapIndex(key)
+			if element.Kind() == reflect.Bool {
+				if element.Bool() {
+					parts = append(parts, fmt.Sprintf("%v", key.Interface()))
+				}
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%v=%s", key.Interface(), formatConfigValue(element.Interface())))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// WriteEffectiveConfig writes the rule values in effect for a run to a CSV file
+func WriteEffectiveConfig(settings []models.ConfigSetting, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating effective config file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"component",
+		"setting",
+		"value",
+		"default_value",
+		"overridden",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write setting data
+	for _, setting := range settings {
+		record := []string{
+			setting.Component,
+			setting.Name,
+			setting.Value,
+			setting.DefaultValue,
+			strconv.FormatBool(setting.Overridden),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing config record: %w", err)
+		}
+	}
+
+	return nil
+}
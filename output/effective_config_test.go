@@ -0,0 +1,86 @@
+// output/effective_config_test.go
+package output
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/processor"
+)
+
+func TestCollectConfigSettings(t *testing.T) {
+	config := processor.DefaultConfig()
+	config.OverdraftLimit = -500
+	config.CoolingOffPeriodMins = 30
+	config.ExcludedDestinations = map[string]bool{"ACC2": true, "ACC1": true, "ACC3": false}
+	config.OverdraftFeeSchedule = []float64{25, 35}
+	config.PriorDecisions = map[string]models.Transaction{"TX1": {ID: "TX1"}}
+
+	settings := CollectConfigSettings("processor", config, processor.DefaultConfig())
+	byName := make(map[string]models.ConfigSetting, len(settings))
+	for _, setting := range settings {
+		if setting.Component != "processor" {
+			t.Errorf("%s has component %q, want processor", setting.Name, setting.Component)
+		}
+		byName[setting.Name] = setting
+	}
+
+	tests := []struct {
+		name string
+		want models.ConfigSetting
+	}{
+		{name: "overridden embedded limit", want: models.ConfigSetting{Name: "overdraft_limit", Value: "-500", DefaultValue: "-1000", Overridden: true}},
+		{name: "default embedded limit", want: models.ConfigSetting{Name: "max_daily_withdrawal_limit", Value: "5000", DefaultValue: "5000"}},
+		{name: "default set", want: models.ConfigSetting{Name: "no_overdraft_account_types", Value: "savings", DefaultValue: "savings"}},
+		{name: "overridden number", want: models.ConfigSetting{Name: "cooling_off_period_mins", Value: "30", DefaultValue: "0", Overridden: true}},
+		{name: "overridden set", want: models.ConfigSetting{Name: "excluded_destinations", Value: "ACC1;ACC2", Overridden: true}},
+		{name: "overridden list", want: models.ConfigSetting{Name: "overdraft_fee_schedule", Value: "25;35", Overridden: true}},
+		{name: "default flag", want: models.ConfigSetting{Name: "strict_invariants", Value: "false", DefaultValue: "false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Component = "processor"
+			if got := byName[tt.want.Name]; got != tt.want {
+				t.Errorf("setting = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	for _, name := range []string{"Limits", "PriorDecisions"} {
+		if _, exists := byName[name]; exists {
+			t.Errorf("settings include %s", name)
+		}
+	}
+}
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	settings := []models.ConfigSetting{
+		{Component: "processor", Name: "overdraft_limit", Value: "-500", DefaultValue: "-1000", Overridden: true},
+		{Component: "detector", Name: "near_limit_margin", Value: "50", DefaultValue: "50"},
+	}
+	path := filepath.Join(t.TempDir(), "effective_config.csv")
+	if err := WriteEffectiveConfig(settings, path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	got, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"component", "setting", "value", "default_value", "overridden"},
+		{"processor", "overdraft_limit", "-500", "-1000", "true"},
+		{"detector", "near_limit_margin", "50", "50", "false"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("effective config file = %q, want %q", got, want)
+	}
+}
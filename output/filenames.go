@@ -0,0 +1,73 @@
+// output/filenames.go
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultFileNameTemplate produces the standard <type>_<date>.<ext> output names
+const DefaultFileNameTemplate = "{{.Type}}_{{.Date}}.{{.Ext}}"
+
+// FileNameData holds the variables available to output filename templates
+type FileNameData struct {
+	Type string // Artifact type, e.g. fraud_alerts
+	Date string // Date in YYYY-MM-DD format
+	Ext  string // File extension without the dot, e.g. csv
+}
+
+// FileNamer renders output filenames from per-artifact templates
+type FileNamer struct {
+	fallback  *template.Template
+	templates map[string]*template.Template
+}
+
+// NewFileNamer parses the default template and any per-artifact overrides keyed by artifact type
+func NewFileNamer(defaultTemplate string, overrides map[string]string) (*FileNamer, error) {
+	namer := &FileNamer{templates: make(map[string]*template.Template)}
+
+	var err error
+	namer.fallback, err = parseFileNameTemplate("default", defaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+	for artifact, text := range overrides {
+		namer.templates[artifact], err = parseFileNameTemplate(artifact, text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return namer, nil
+}
+
+// parseFileNameTemplate parses a filename template and checks it renders
+func parseFileNameTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s filename template: %w", name, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, FileNameData{Type: name, Date: "2006-01-02", Ext: "csv"}); err != nil {
+		return nil, fmt.Errorf("invalid %s filename template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Name renders the filename for an artifact type, date, and extension
+func (n *FileNamer) Name(artifact string, date string, ext string) (string, error) {
+	tmpl, exists := n.templates[artifact]
+	if !exists {
+		tmpl = n.fallback
+	}
+
+	var name strings.Builder
+	if err := tmpl.Execute(&name, FileNameData{Type: artifact, Date: date, Ext: ext}); err != nil {
+		return "", fmt.Errorf("error rendering %s filename: %w", artifact, err)
+	}
+	if name.Len() == 0 || strings.ContainsRune(name.String(), filepath.Separator) {
+		return "", fmt.Errorf("invalid %s filename: %q", artifact, name.String())
+	}
+	return name.String(), nil
+}
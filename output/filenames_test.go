@@ -0,0 +1,42 @@
+// output/filenames_test.go
+package output
+
+import "testing"
+
+func TestFileNamer(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallback  string
+		overrides map[string]string
+		artifact  string
+		want      string
+		wantErr   bool
+	}{
+		{name: "default", fallback: DefaultFileNameTemplate, artifact: "fraud_alerts", want: "fraud_alerts_2025-04-15.csv"},
+		{name: "custom default", fallback: "{{.Date}}-{{.Type}}.{{.Ext}}", artifact: "accounts", want: "2025-04-15-accounts.csv"},
+		{name: "override", fallback: DefaultFileNameTemplate, overrides: map[string]string{"fraud_alerts": "alerts.{{.Date}}.{{.Ext}}"},
+			artifact: "fraud_alerts", want: "alerts.2025-04-15.csv"},
+		{name: "other artifact keeps the default", fallback: DefaultFileNameTemplate, overrides: map[string]string{"fraud_alerts": "alerts.{{.Ext}}"},
+			artifact: "accounts", want: "accounts_2025-04-15.csv"},
+		{name: "separator rejected", fallback: "{{.Date}}/{{.Type}}.{{.Ext}}", artifact: "accounts", wantErr: true},
+		{name: "empty name rejected", fallback: "{{if false}}x{{end}}", artifact: "accounts", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namer, err := NewFileNamer(tt.fallback, tt.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := namer.Name(tt.artifact, "2025-04-15", "csv")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Name() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	for _, text := range []string{"{{.Date", "{{.Missing}}"} {
+		if _, err := NewFileNamer(text, nil); err == nil {
+			t.Errorf("NewFileNamer(%q) accepted an invalid template", text)
+		}
+	}
+}
@@ -4,47 +4,87 @@ package output
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"DailyTransactionBatchProcessing/models"
 )
 
 // WriteAccounts writes account data to a CSV file
-func WriteAccounts(accounts map[string]models.Account, filePath string) error {
-	file, err := os.Create(filePath)
+func WriteAccounts(accounts map[string]models.Account, filePath string) (err error) {
+	file, err := createFile(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating accounts file: %w", err)
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-
-		}
-	}(file)
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer closeCSV(writer, file, &err)
 
 	// Write header
-	header := []string{"account_id", "balance", "overdraft_count", "last_transaction_time"}
+	header := []string{
+		"account_id",
+		"balance",
+		"overdraft_count",
+		"last_transaction_time",
+		"account_type",
+		"held_amount",
+		"available_balance",
+		"currency",
+		"approved_overdraft",
+		"reserved_balance",
+		"account_open_date",
+		"overdraft_limit",
+		"daily_debits",
+		"daily_credits",
+		"daily_transfers",
+		"daily_totals_date",
+	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("error writing header: %w", err)
 	}
 
-	// Write account data
-	for _, account := range accounts {
+	// Write account data in account ID order so output is deterministic
+	accountIDs := make([]string, 0, len(accounts))
+	for accountID := range accounts {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	for _, accountID := range accountIDs {
+		account := accounts[accountID]
 		lastTxTime := ""
 		if !account.LastTransactionTime.IsZero() {
 			lastTxTime = account.LastTransactionTime.Format(time.RFC3339)
 		}
+		openDate := ""
+		if !account.AccountOpenDate.IsZero() {
+			openDate = account.AccountOpenDate.Format("2006-01-02")
+		}
+		totalsDate := ""
+		if !account.DailyTotalsDate.IsZero() {
+			totalsDate = account.DailyTotalsDate.Format("2006-01-02")
+		}
 
 		record := []string{
 			account.ID,
-			fmt.Sprintf("%.2f", account.Balance),
+			account.Balance.String(),
 			strconv.Itoa(account.OverdraftCount),
 			lastTxTime,
+			account.AccountType,
+			account.HeldAmount.String(),
+			account.AvailableBalance().String(),
+			account.Currency,
+			strconv.FormatBool(account.ApprovedOverdraft),
+			account.ReservedBalance.String(),
+			openDate,
+			formatOptionalMoney(account.OverdraftLimit),
+			account.DailyDebits.String(),
+			account.DailyCredits.String(),
+			account.DailyTransfers.String(),
+			totalsDate,
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -55,50 +95,93 @@ func WriteAccounts(accounts map[string]models.Account, filePath string) error {
 	return nil
 }
 
-// WriteProcessedTransactions writes processed transactions to a CSV file
+// processedColumns are the columns a processed transactions file can contain, in their default order
+var processedColumns = []struct {
+	name   string
+	format func(models.Transaction) string
+}{
+	{"transaction_id", func(t models.Transaction) string { return t.ID }},
+	{"account_id", func(t models.Transaction) string { return t.AccountID }},
+	{"timestamp", func(t models.Transaction) string { return t.Timestamp.Format(time.RFC3339) }},
+	{"amount", func(t models.Transaction) string { return t.Amount.String() }},
+	{"type", func(t models.Transaction) string { return t.Type }},
+	{"status", func(t models.Transaction) string { return t.Status }},
+	{"description", func(t models.Transaction) string { return t.Description }},
+	{"destination_account_id", func(t models.Transaction) string { return t.DestinationAccountID }},
+	{"processing_message", func(t models.Transaction) string { return t.ProcessingMessage }},
+	{"currency", func(t models.Transaction) string { return t.Currency }},
+	{"tags", func(t models.Transaction) string { return models.FormatTags(t.Tags) }},
+	{"original_amount", func(t models.Transaction) string { return formatOptionalMoney(t.OriginalAmount) }},
+	{"original_currency", func(t models.Transaction) string { return t.OriginalCurrency }},
+	{"exchange_rate", func(t models.Transaction) string { return formatOptionalAmount(t.ExchangeRate, "%.6f") }},
+	{"destination_amount", func(t models.Transaction) string { return formatOptionalMoney(t.DestinationAmount) }},
+	{"balance_after", formatBalanceAfter},
+	{"original_transaction_id", func(t models.Transaction) string { return t.OriginalTransactionID }},
+	{"reversed_type", func(t models.Transaction) string { return t.ReversedType }},
+}
+
+// CheckTransactionColumns returns an error naming the first column a processed transactions
+// file cannot contain
+func CheckTransactionColumns(columns []string) error {
+	_, _, err := processedColumnFormats(columns)
+	return err
+}
+
+// processedColumnFormats returns the names and formatters of the named columns, or of every
+// column when none are named
+func processedColumnFormats(columns []string) ([]string, []func(models.Transaction) string, error) {
+	if len(columns) == 0 {
+		for _, column := range processedColumns {
+			columns = append(columns, column.name)
+		}
+	}
+
+	formats := make([]func(models.Transaction) string, len(columns))
+	for i, name := range columns {
+		for _, column := range processedColumns {
+			if column.name == name {
+				formats[i] = column.format
+				break
+			}
+		}
+		if formats[i] == nil {
+			return nil, nil, fmt.Errorf("unknown transaction column %q", name)
+		}
+	}
+	return columns, formats, nil
+}
+
+// WriteProcessedTransactions writes processed transactions to a CSV file with every column
 func WriteProcessedTransactions(transactions []models.Transaction, filePath string) error {
-	file, err := os.Create(filePath)
+	return WriteProcessedTransactionsColumns(transactions, filePath, nil)
+}
+
+// WriteProcessedTransactionsColumns writes processed transactions to a CSV file with the named
+// columns in the order given, or with every column when none are named
+func WriteProcessedTransactionsColumns(transactions []models.Transaction, filePath string, columns []string) (err error) {
+	header, formats, err := processedColumnFormats(columns)
 	if err != nil {
-		return fmt.Errorf("error creating transactions file: %w", err)
+		return err
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
 
-		}
-	}(file)
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating transactions file: %w", err)
+	}
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer closeCSV(writer, file, &err)
 
 	// Write header
-	header := []string{
-		"transaction_id",
-		"account_id",
-		"timestamp",
-		"amount",
-		"type",
-		"status",
-		"description",
-		"destination_account_id",
-		"processing_message",
-	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("error writing header: %w", err)
 	}
 
 	// Write transaction data
 	for _, transaction := range transactions {
-		record := []string{
-			transaction.ID,
-			transaction.AccountID,
-			transaction.Timestamp.Format(time.RFC3339),
-			fmt.Sprintf("%.2f", transaction.Amount),
-			transaction.Type,
-			transaction.Status,
-			transaction.Description,
-			transaction.DestinationAccountID,
-			transaction.ProcessingMessage,
+		record := make([]string, len(formats))
+		for i, format := range formats {
+			record[i] = format(transaction)
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -109,21 +192,39 @@ func WriteProcessedTransactions(transactions []models.Transaction, filePath stri
 	return nil
 }
 
+// formatOptionalMoney formats an amount that is zero when not applicable as an empty field
+func formatOptionalMoney(value models.Money) string {
+	if value == 0 {
+		return ""
+	}
+	return value.String()
+}
+
+// formatOptionalAmount formats a value that is zero when not applicable as an empty field
+func formatOptionalAmount(value float64, format string) string {
+	if value == 0 {
+		return ""
+	}
+	return fmt.Sprintf(format, value)
+}
+
+// formatBalanceAfter formats the post-transaction balance, empty for transactions that did not post
+func formatBalanceAfter(transaction models.Transaction) string {
+	if transaction.Status != "completed" {
+		return ""
+	}
+	return transaction.BalanceAfter.String()
+}
+
 // WriteInvalidTransactions writes invalid transactions to a CSV file
-func WriteInvalidTransactions(transactions []models.Transaction, filePath string) error {
-	file, err := os.Create(filePath)
+func WriteInvalidTransactions(transactions []models.Transaction, filePath string) (err error) {
+	file, err := createFile(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating invalid transactions file: %w", err)
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-
-		}
-	}(file)
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer closeCSV(writer, file, &err)
 
 	// Write header
 	header := []string{
@@ -145,7 +246,7 @@ func WriteInvalidTransactions(transactions []models.Transaction, filePath string
 			transaction.ID,
 			transaction.AccountID,
 			transaction.Timestamp.Format(time.RFC3339),
-			fmt.Sprintf("%.2f", transaction.Amount),
+			transaction.Amount.String(),
 			transaction.Type,
 			transaction.Status,
 			transaction.ValidationMessage,
@@ -160,23 +261,18 @@ func WriteInvalidTransactions(transactions []models.Transaction, filePath string
 }
 
 // WriteAnomalies writes detected anomalies to a CSV file
-func WriteAnomalies(anomalies []models.Anomaly, filePath string) error {
-	file, err := os.Create(filePath)
+func WriteAnomalies(anomalies []models.Anomaly, filePath string) (err error) {
+	file, err := createFile(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating anomalies file: %w", err)
 	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-
-		}
-	}(file)
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer closeCSV(writer, file, &err)
 
 	// Write header
 	header := []string{
+		"anomaly_id",
 		"transaction_id",
 		"account_id",
 		"timestamp",
@@ -191,6 +287,7 @@ func WriteAnomalies(anomalies []models.Anomaly, filePath string) error {
 	// Write anomaly data
 	for _, anomaly := range anomalies {
 		record := []string{
+			anomaly.ID,
 			anomaly.TransactionID,
 			anomaly.AccountID,
 			anomaly.Timestamp.Format(time.RFC3339),
@@ -207,22 +304,65 @@ func WriteAnomalies(anomalies []models.Anomaly, filePath string) error {
 	return nil
 }
 
-// GenerateAccountSummary generates account summaries for the day
+// WriteAnomaliesPaged writes anomalies like WriteAnomalies, but splits them across numbered part
+// files of at most maxRowsPerFile rows each, every part repeating the header. A single page, or a
+// maxRowsPerFile of 0, writes filePath itself.
+func WriteAnomaliesPaged(anomalies []models.Anomaly, filePath string, maxRowsPerFile int) error {
+	paths := PagedPaths(filePath, len(anomalies), maxRowsPerFile)
+	if len(paths) == 1 {
+		return WriteAnomalies(anomalies, filePath)
+	}
+
+	for part, path := range paths {
+		start := part * maxRowsPerFile
+		end := min(start+maxRowsPerFile, len(anomalies))
+		if err := WriteAnomalies(anomalies[start:end], path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PagedPaths returns the files a report of rows records split into parts of at most
+// maxRowsPerFile rows is written to: filePath itself when it fits on one page
+func PagedPaths(filePath string, rows int, maxRowsPerFile int) []string {
+	if maxRowsPerFile <= 0 || rows <= maxRowsPerFile {
+		return []string{filePath}
+	}
+	paths := make([]string, 0, (rows+maxRowsPerFile-1)/maxRowsPerFile)
+	for part := 1; len(paths)*maxRowsPerFile < rows; part++ {
+		paths = append(paths, PartPath(filePath, part))
+	}
+	return paths
+}
+
+// PartPath returns the path of a numbered part file, e.g. fraud_alerts_2025-04-15_part001.csv
+func PartPath(filePath string, part int) string {
+	ext := filepath.Ext(filePath)
+	return fmt.Sprintf("%s_part%03d%s", strings.TrimSuffix(filePath, ext), part, ext)
+}
+
+// GenerateAccountSummary generates account summaries for the day, taking opening balances from
+// the accounts as loaded before processing and closing balances from the processed accounts.
+// A transaction counts once, in the TransactionCount of the account it was posted from; the
+// destination leg of a transfer, or of a transfer's reversal, counts in the destination's TransfersIn.
+// Fee rows the processor generated, and their reversals, add to TotalDebits but are not counted.
 func GenerateAccountSummary(
+	openingAccounts map[string]models.Account,
 	accounts map[string]models.Account,
 	transactions []models.Transaction,
 	dateStr string,
 ) []models.AccountSummary {
-	// Track opening balances and create summaries
 	summaries := make(map[string]*models.AccountSummary)
 
-	// Create initial summaries with closing balances (current account balances)
+	// Create initial summaries with opening and closing balances
 	for accountID, account := range accounts {
 		summaries[accountID] = &models.AccountSummary{
 			AccountID:        accountID,
 			Date:             dateStr,
 			ClosingBalance:   account.Balance,
-			OpeningBalance:   account.Balance, // Will be adjusted below
+			OpeningBalance:   openingAccounts[accountID].Balance,
 			TotalDebits:      0,
 			TotalCredits:     0,
 			TransactionCount: 0,
@@ -230,60 +370,109 @@ func GenerateAccountSummary(
 		}
 	}
 
-	// Process transactions to calculate opening balances and transaction totals
+	// Process transactions to calculate transaction totals
 	for _, transaction := range transactions {
 		// Skip non-completed transactions
 		if transaction.Status != "completed" {
 			continue
 		}
 
+		// A reversal nets against the totals of the transaction it undid
+		sign := models.Money(1)
+		if transaction.Type == "reversal" {
+			transaction, sign = transaction.Reversed(), -1
+		}
+
 		// Update source account summary
 		if summary, exists := summaries[transaction.AccountID]; exists {
-			summary.TransactionCount++
+			if transaction.Type != "fee" {
+				summary.TransactionCount++
+			}
 
-			// Update opening balance and transaction totals based on transaction type
+			// Update transaction totals based on transaction type
 			switch transaction.Type {
 			case "credit":
-				summary.OpeningBalance -= transaction.Amount
-				summary.TotalCredits += transaction.Amount
+				summary.TotalCredits += sign * transaction.Amount
 
-			case "debit":
-				summary.OpeningBalance += transaction.Amount
-				summary.TotalDebits += transaction.Amount
+			case "debit", "fee":
+				summary.TotalDebits += sign * transaction.Amount
 
 			case "transfer":
-				summary.OpeningBalance += transaction.Amount
-				summary.TotalDebits += transaction.Amount
+				summary.TotalDebits += sign * transaction.Amount
 
 				// Update destination account for transfers
 				if destSummary, exists := summaries[transaction.DestinationAccountID]; exists {
-					destSummary.TransactionCount++
-					destSummary.OpeningBalance -= transaction.Amount
-					destSummary.TotalCredits += transaction.Amount
+					destSummary.TransfersIn++
+					destSummary.TotalCredits += sign * transaction.CreditedAmount()
 				}
 			}
 		}
 	}
 
-	// Convert map to slice for return
+	// Convert map to slice for return, ordered by account ID
 	result := make([]models.AccountSummary, 0, len(summaries))
 	for _, summary := range summaries {
 		result = append(result, *summary)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AccountID < result[j].AccountID
+	})
 
 	return result
 }
 
+// GenerateAccountSummaryFiltered generates the account summaries for high-value accounts: those
+// closing above minBalance or with at least one anomaly
+func GenerateAccountSummaryFiltered(
+	openingAccounts map[string]models.Account,
+	accounts map[string]models.Account,
+	transactions []models.Transaction,
+	anomalies []models.Anomaly,
+	dateStr string,
+	minBalance float64,
+) []models.AccountSummary {
+	flagged := make(map[string]bool)
+	for _, anomaly := range anomalies {
+		flagged[anomaly.AccountID] = true
+	}
+
+	result := []models.AccountSummary{}
+	for _, summary := range GenerateAccountSummary(openingAccounts, accounts, transactions, dateStr) {
+		if summary.ClosingBalance > models.Cents(minBalance) || flagged[summary.AccountID] {
+			result = append(result, summary)
+		}
+	}
+	return result
+}
+
+// ApplyExpiringHolds counts, per account summary, the holds that expire after asOf and
+// no later than asOf plus the horizon
+func ApplyExpiringHolds(summaries []models.AccountSummary, holds []models.Hold, asOf time.Time, horizon time.Duration) {
+	index := make(map[string]int, len(summaries))
+	for i, summary := range summaries {
+		index[summary.AccountID] = i
+	}
+
+	cutoff := asOf.Add(horizon)
+	for _, hold := range holds {
+		i, exists := index[hold.AccountID]
+		if !exists || !hold.ExpiresAt.After(asOf) || hold.ExpiresAt.After(cutoff) {
+			continue
+		}
+		summaries[i].ExpiringHoldsCount++
+		summaries[i].ExpiringHoldsAmount += hold.Amount
+	}
+}
+
 // WriteAccountSummary writes account summaries to a CSV file
-func WriteAccountSummary(summaries []models.AccountSummary, filePath string) error {
-	file, err := os.Create(filePath)
+func WriteAccountSummary(summaries []models.AccountSummary, filePath string) (err error) {
+	file, err := createFile(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating account summary file: %w", err)
 	}
-	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer closeCSV(writer, file, &err)
 
 	// Write header
 	header := []string{
@@ -295,6 +484,9 @@ func WriteAccountSummary(summaries []models.AccountSummary, filePath string) err
 		"total_credits",
 		"transaction_count",
 		"overdraft_count",
+		"expiring_holds_count",
+		"expiring_holds_amount",
+		"transfers_in",
 	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("error writing header: %w", err)
@@ -305,12 +497,15 @@ func WriteAccountSummary(summaries []models.AccountSummary, filePath string) err
 		record := []string{
 			summary.AccountID,
 			summary.Date,
-			fmt.Sprintf("%.2f", summary.OpeningBalance),
-			fmt.Sprintf("%.2f", summary.ClosingBalance),
-			fmt.Sprintf("%.2f", summary.TotalDebits),
-			fmt.Sprintf("%.2f", summary.TotalCredits),
+			summary.OpeningBalance.String(),
+			summary.ClosingBalance.String(),
+			summary.TotalDebits.String(),
+			summary.TotalCredits.String(),
 			strconv.Itoa(summary.TransactionCount),
 			strconv.Itoa(summary.OverdraftCount),
+			strconv.Itoa(summary.ExpiringHoldsCount),
+			summary.ExpiringHoldsAmount.String(),
+			strconv.Itoa(summary.TransfersIn),
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -320,3 +515,484 @@ func WriteAccountSummary(summaries []models.AccountSummary, filePath string) err
 
 	return nil
 }
+
+// WriteEvents writes informational events to a CSV file
+func WriteEvents(events []models.Event, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating events file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"transaction_id",
+		"account_id",
+		"timestamp",
+		"type",
+		"description",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write event data
+	for _, event := range events {
+		record := []string{
+			event.TransactionID,
+			event.AccountID,
+			event.Timestamp.Format(time.RFC3339),
+			event.Type,
+			event.Description,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing event record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteReconciliation writes the per-currency reconciliation report to a CSV file
+func WriteReconciliation(reconciliations []models.CurrencyReconciliation, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating reconciliation file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"currency",
+		"opening_total",
+		"closing_total",
+		"net_transactions",
+		"difference",
+		"balanced",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write reconciliation data
+	for _, reconciliation := range reconciliations {
+		record := []string{
+			reconciliation.Currency,
+			reconciliation.OpeningTotal.String(),
+			reconciliation.ClosingTotal.String(),
+			reconciliation.NetTransactions.String(),
+			reconciliation.Difference.String(),
+			strconv.FormatBool(reconciliation.Balanced),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing reconciliation record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// maxAnomalyExamples is the number of example transaction IDs kept per anomaly type
+const maxAnomalyExamples = 3
+
+// GenerateAnomalySummary rolls anomalies up by type with a severity breakdown and
+// up to three example transaction IDs, ordered by type
+func GenerateAnomalySummary(anomalies []models.Anomaly) []models.AnomalySummary {
+	summaries := make(map[string]*models.AnomalySummary)
+	for anomalyType, severityCounts := range SummarizeAnomalies(anomalies) {
+		summary := &models.AnomalySummary{Type: anomalyType, SeverityCounts: severityCounts}
+		for _, count := range severityCounts {
+			summary.Count += count
+		}
+		summaries[anomalyType] = summary
+	}
+
+	for _, anomaly := range anomalies {
+		summary := summaries[anomaly.Type]
+		if anomaly.TransactionID != "" && len(summary.ExampleTransactionIDs) < maxAnomalyExamples {
+			summary.ExampleTransactionIDs = append(summary.ExampleTransactionIDs, anomaly.TransactionID)
+		}
+	}
+
+	result := make([]models.AnomalySummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+
+	return result
+}
+
+// SummarizeAnomalies counts anomalies by type and then by severity
+func SummarizeAnomalies(anomalies []models.Anomaly) map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	for _, anomaly := range anomalies {
+		if counts[anomaly.Type] == nil {
+			counts[anomaly.Type] = make(map[string]int)
+		}
+		counts[anomaly.Type][anomaly.Severity]++
+	}
+	return counts
+}
+
+// WriteAnomalySummary writes the anomaly rollup to a CSV file
+func WriteAnomalySummary(summaries []models.AnomalySummary, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating anomaly summary file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"type",
+		"count",
+		"low",
+		"medium",
+		"high",
+		"example_transaction_ids",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write summary data
+	for _, summary := range summaries {
+		record := []string{
+			summary.Type,
+			strconv.Itoa(summary.Count),
+			strconv.Itoa(summary.SeverityCounts["low"]),
+			strconv.Itoa(summary.SeverityCounts["medium"]),
+			strconv.Itoa(summary.SeverityCounts["high"]),
+			strings.Join(summary.ExampleTransactionIDs, ";"),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing anomaly summary record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateHourlyHistogram bins each account's completed transactions into 24 hourly buckets
+// in the given location, ordered by account ID
+func GenerateHourlyHistogram(transactions []models.Transaction, location *time.Location) []models.HourlyHistogram {
+	histograms := make(map[string]*models.HourlyHistogram)
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+
+		histogram, exists := histograms[transaction.AccountID]
+		if !exists {
+			histogram = &models.HourlyHistogram{AccountID: transaction.AccountID}
+			histograms[transaction.AccountID] = histogram
+		}
+		histogram.Counts[transaction.Timestamp.In(location).Hour()]++
+	}
+
+	result := make([]models.HourlyHistogram, 0, len(histograms))
+	for _, histogram := range histograms {
+		result = append(result, *histogram)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AccountID < result[j].AccountID
+	})
+
+	return result
+}
+
+// WriteHourlyHistogram writes hourly transaction counts to a CSV file
+func WriteHourlyHistogram(histograms []models.HourlyHistogram, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating hourly histogram file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{"account_id"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("hour%d", hour))
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write histogram data
+	for _, histogram := range histograms {
+		record := []string{histogram.AccountID}
+		for _, count := range histogram.Counts {
+			record = append(record, strconv.Itoa(count))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing hourly histogram record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteBalanceDeltas writes the closing balance changes from a reprocessing run to a CSV file
+func WriteBalanceDeltas(deltas []models.BalanceDelta, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating balance delta file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"account_id",
+		"original_balance",
+		"corrected_balance",
+		"difference",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write delta data
+	for _, delta := range deltas {
+		record := []string{
+			delta.AccountID,
+			delta.OriginalBalance.String(),
+			delta.CorrectedBalance.String(),
+			delta.Difference.String(),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing balance delta record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateOverdraftTransitions compares opening and closing balances, returning the accounts
+// that entered overdraft (non-negative at open, negative at close) and those that cured it
+// (negative at open, non-negative at close), each ordered by account ID
+func GenerateOverdraftTransitions(
+	openingAccounts map[string]models.Account,
+	closingAccounts map[string]models.Account,
+) (newlyOverdrawn []models.OverdraftTransition, cured []models.OverdraftTransition) {
+	newlyOverdrawn = []models.OverdraftTransition{}
+	cured = []models.OverdraftTransition{}
+	for id, closing := range closingAccounts {
+		opening, exists := openingAccounts[id]
+		if !exists {
+			continue
+		}
+
+		transition := models.OverdraftTransition{
+			AccountID:      id,
+			OpeningBalance: opening.Balance,
+			ClosingBalance: closing.Balance,
+		}
+		switch {
+		case opening.Balance >= 0 && closing.Balance < 0:
+			newlyOverdrawn = append(newlyOverdrawn, transition)
+		case opening.Balance < 0 && closing.Balance >= 0:
+			cured = append(cured, transition)
+		}
+	}
+
+	sort.Slice(newlyOverdrawn, func(i, j int) bool {
+		return newlyOverdrawn[i].AccountID < newlyOverdrawn[j].AccountID
+	})
+	sort.Slice(cured, func(i, j int) bool {
+		return cured[i].AccountID < cured[j].AccountID
+	})
+	return newlyOverdrawn, cured
+}
+
+// WriteOverdraftTransitions writes accounts that changed overdraft status to a CSV file
+func WriteOverdraftTransitions(transitions []models.OverdraftTransition, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating overdraft transition file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"account_id",
+		"opening_balance",
+		"closing_balance",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write transition data
+	for _, transition := range transitions {
+		record := []string{
+			transition.AccountID,
+			transition.OpeningBalance.String(),
+			transition.ClosingBalance.String(),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing overdraft transition record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateLedger splits completed transactions into one entry per account-impacting leg: a
+// transfer debits its source and credits its destination under the same group ID, and a
+// reversal posts the legs of the transaction it undid with the opposite sign
+func GenerateLedger(transactions []models.Transaction) []models.LedgerEntry {
+	entries := make([]models.LedgerEntry, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		legs, sign := transaction, models.Money(1)
+		if transaction.Type == "reversal" {
+			legs, sign = transaction.Reversed(), -1
+		}
+
+		entry := models.LedgerEntry{
+			GroupID:   transaction.ID,
+			Leg:       1,
+			AccountID: transaction.AccountID,
+			Timestamp: transaction.Timestamp,
+			Type:      transaction.Type,
+		}
+		switch legs.Type {
+		case "credit":
+			entry.Amount = sign * legs.Amount
+			entries = append(entries, entry)
+		case "debit", "fee":
+			entry.Amount = -sign * legs.Amount
+			entries = append(entries, entry)
+		case "transfer":
+			entry.Amount = -sign * legs.Amount
+			entries = append(entries, entry)
+			entry.Leg = 2
+			entry.AccountID = legs.DestinationAccountID
+			entry.Amount = sign * legs.CreditedAmount()
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// WriteLedger writes ledger entries to a CSV file
+func WriteLedger(entries []models.LedgerEntry, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating ledger file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"group_id",
+		"leg",
+		"account_id",
+		"timestamp",
+		"type",
+		"amount",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write ledger data
+	for _, entry := range entries {
+		record := []string{
+			entry.GroupID,
+			strconv.Itoa(entry.Leg),
+			entry.AccountID,
+			entry.Timestamp.Format(time.RFC3339),
+			entry.Type,
+			entry.Amount.String(),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing ledger record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// encodeRawRecord renders a record as the line it was read from: CSV fields are re-encoded with
+// their quoting, and a record of one field, such as a JSON line, is written as is
+func encodeRawRecord(record []string) (string, error) {
+	if len(record) == 1 {
+		return record[0], nil
+	}
+	var line strings.Builder
+	writer := csv.NewWriter(&line)
+	if err := writer.Write(record); err != nil {
+		return "", err
+	}
+	writer.Flush()
+	return strings.TrimSuffix(line.String(), "\n"), writer.Error()
+}
+
+// WriteIngestionErrors writes the input records that could not be parsed to a CSV file
+func WriteIngestionErrors(parseErrors []*models.ParseError, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating ingestion error file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	defer closeCSV(writer, file, &err)
+
+	// Write header
+	header := []string{
+		"line_number",
+		"field",
+		"error",
+		"record",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	// Write error data
+	for _, parseErr := range parseErrors {
+		raw, err := encodeRawRecord(parseErr.Record)
+		if err != nil {
+			return fmt.Errorf("error encoding record at line %d: %w", parseErr.LineNumber, err)
+		}
+		record := []string{
+			strconv.Itoa(parseErr.LineNumber),
+			parseErr.Field,
+			fmt.Sprint(parseErr.Err),
+			raw,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing ingestion error record: %w", err)
+		}
+	}
+
+	return nil
+}
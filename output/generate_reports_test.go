@@ -0,0 +1,609 @@
+// output/generate_reports_test.go
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/processor"
+)
+
+var errFailingSink = errors.New("disk full")
+
+// failingSink creates reports whose writes or close fail, as a full disk or a gzip trailer that
+// cannot be written would
+type failingSink struct {
+	FileSink
+	failWrite bool
+	failClose bool
+}
+
+type failingFile struct {
+	sink failingSink
+}
+
+func (f failingFile) Write(p []byte) (int, error) {
+	if f.sink.failWrite {
+		return 0, errFailingSink
+	}
+	return len(p), nil
+}
+
+func (f failingFile) Close() error {
+	if f.sink.failClose {
+		return errFailingSink
+	}
+	return nil
+}
+
+func (s failingSink) Create(string) (io.WriteCloser, error) {
+	return failingFile{sink: s}, nil
+}
+
+func TestWritersReportFlushAndCloseErrors(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "completed"}}
+	writers := map[string]func(path string) error{
+		"accounts": func(path string) error {
+			return WriteAccounts(map[string]models.Account{"A1": {ID: "A1"}}, path)
+		},
+		"processed transactions": func(path string) error { return WriteProcessedTransactions(transactions, path) },
+		"invalid transactions":   func(path string) error { return WriteInvalidTransactions(transactions, path) },
+		"anomalies": func(path string) error {
+			return WriteAnomalies([]models.Anomaly{{AccountID: "A1", Timestamp: timestamp, Type: "overdraft"}}, path)
+		},
+		"account summary": func(path string) error {
+			return WriteAccountSummary([]models.AccountSummary{{AccountID: "A1"}}, path)
+		},
+		"ledger":      func(path string) error { return WriteLedger(GenerateLedger(transactions), path) },
+		"json":        func(path string) error { return WriteJSON(transactions, path) },
+		"run metrics": func(path string) error { return WriteRunMetrics(models.Metrics{Date: "2025-04-15"}, path) },
+		"state":       func(path string) error { return WriteState(models.State{Stage: "processed"}, path) },
+		"effective config": func(path string) error {
+			return WriteEffectiveConfig([]models.ConfigSetting{{Component: "processor", Name: "limit", Value: "1"}}, path)
+		},
+	}
+
+	tests := []struct {
+		name string
+		sink failingSink
+	}{
+		{name: "write fails", sink: failingSink{failWrite: true}},
+		{name: "close fails", sink: failingSink{failClose: true}},
+	}
+	for _, tt := range tests {
+		for name, write := range writers {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				SetReportSink(tt.sink)
+				t.Cleanup(func() { SetReportSink(FileSink{}) })
+
+				errs := RunJobs([]Job{{Name: name, Path: "report.csv", Write: write}}, 1)
+				if !errors.Is(errs[0], errFailingSink) {
+					t.Errorf("RunJobs() error = %v, want the sink failure", errs[0])
+				}
+			})
+		}
+	}
+
+	// A healthy sink reports no error
+	SetReportSink(failingSink{})
+	t.Cleanup(func() { SetReportSink(FileSink{}) })
+	for name, write := range writers {
+		if err := write("report.csv"); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestGenerateAccountSummaryCounts(t *testing.T) {
+	opening := map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(100)}, "A2": {ID: "A2"}}
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+		wantCount    int
+		wantDebits   models.Money
+		wantCredits  models.Money
+	}{
+		{
+			name:         "debit",
+			transactions: []models.Transaction{{ID: "T1", AccountID: "A1", Amount: models.Cents(10), Type: "debit", Status: "completed"}},
+			wantCount:    1, wantDebits: models.Cents(10),
+		},
+		{
+			name: "debit with its fee",
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", Amount: models.Cents(10), Type: "debit", Status: "completed"},
+				{ID: "T1-FEE", AccountID: "A1", Amount: models.Cents(1.5), Type: "fee", Status: "completed"},
+			},
+			wantCount: 1, wantDebits: models.Cents(11.5),
+		},
+		{
+			name: "fee reversed",
+			transactions: []models.Transaction{
+				{ID: "T1-FEE", AccountID: "A1", Amount: models.Cents(1.5), Type: "fee", Status: "completed"},
+				{ID: "T1-FEE-REV", AccountID: "A1", Amount: models.Cents(1.5), Type: "reversal", ReversedType: "fee", Status: "completed"},
+			},
+			wantCount: 0, wantDebits: 0,
+		},
+		{
+			name: "transfer",
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", DestinationAccountID: "A2", Amount: models.Cents(20), Type: "transfer", Status: "completed"},
+			},
+			wantCount: 1, wantDebits: models.Cents(20),
+		},
+		{
+			name: "rejected rows",
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", Amount: models.Cents(500), Type: "debit", Status: "rejected"},
+				{ID: "T1-FEE", AccountID: "A1", Amount: models.Cents(25), Type: "fee", Status: "completed"},
+			},
+			wantCount: 0, wantDebits: models.Cents(25),
+		},
+		{
+			name:         "credit",
+			transactions: []models.Transaction{{ID: "T1", AccountID: "A1", Amount: models.Cents(5), Type: "credit", Status: "completed"}},
+			wantCount:    1, wantCredits: models.Cents(5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries := GenerateAccountSummary(opening, opening, tt.transactions, "2025-04-15")
+			summary := summaries[0]
+			if summary.AccountID != "A1" {
+				t.Fatalf("first summary is for %s, want A1", summary.AccountID)
+			}
+			if summary.TransactionCount != tt.wantCount {
+				t.Errorf("TransactionCount = %d, want %d", summary.TransactionCount, tt.wantCount)
+			}
+			if summary.TotalDebits != tt.wantDebits || summary.TotalCredits != tt.wantCredits {
+				t.Errorf("totals = %s debits, %s credits, want %s, %s",
+					summary.TotalDebits, summary.TotalCredits, tt.wantDebits, tt.wantCredits)
+			}
+		})
+	}
+}
+
+func TestGenerateAccountSummaryOpeningBalance(t *testing.T) {
+	opening := map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(100)}, "A2": {ID: "A2", Balance: models.Cents(10)}}
+	tests := []struct {
+		name         string
+		closing      map[string]models.Account
+		transactions []models.Transaction
+	}{
+		{
+			// Walking the completed rows back from closing balances would miss the stranded debit
+			name:    "transfer rejected after debiting its source",
+			closing: map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(60)}, "A2": {ID: "A2", Balance: models.Cents(10)}},
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", DestinationAccountID: "A2", Amount: models.Cents(40), Type: "transfer", Status: "rejected"},
+			},
+		},
+		{
+			name:    "debit rejected for the overdraft limit, then a credit",
+			closing: map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(105)}, "A2": {ID: "A2", Balance: models.Cents(10)}},
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", Amount: models.Cents(5000), Type: "debit", Status: "rejected"},
+				{ID: "T2", AccountID: "A1", Amount: models.Cents(5), Type: "credit", Status: "completed"},
+			},
+		},
+		{
+			name:    "reversed debit",
+			closing: map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(100)}, "A2": {ID: "A2", Balance: models.Cents(10)}},
+			transactions: []models.Transaction{
+				{ID: "T1", AccountID: "A1", Amount: models.Cents(30), Type: "debit", Status: "completed"},
+				{ID: "R1", AccountID: "A1", OriginalTransactionID: "T1", Amount: models.Cents(30), Type: "reversal", ReversedType: "debit", Status: "completed"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, summary := range GenerateAccountSummary(opening, tt.closing, tt.transactions, "2025-04-15") {
+				if want := opening[summary.AccountID].Balance; summary.OpeningBalance != want {
+					t.Errorf("%s opening balance = %s, want the loaded %s", summary.AccountID, summary.OpeningBalance, want)
+				}
+				if want := tt.closing[summary.AccountID].Balance; summary.ClosingBalance != want {
+					t.Errorf("%s closing balance = %s, want %s", summary.AccountID, summary.ClosingBalance, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateAccountSummaryFiltered(t *testing.T) {
+	accounts := map[string]models.Account{
+		"RICH":    {ID: "RICH", Balance: models.Cents(25000)},
+		"AT-LINE": {ID: "AT-LINE", Balance: models.Cents(10000)},
+		"FLAGGED": {ID: "FLAGGED", Balance: models.Cents(50)},
+		"PLAIN":   {ID: "PLAIN", Balance: models.Cents(900)},
+		"OD":      {ID: "OD", Balance: models.Cents(-200)},
+	}
+	anomalies := []models.Anomaly{{TransactionID: "TX1", AccountID: "FLAGGED", Type: "large_debit"}}
+
+	var got []string
+	for _, summary := range GenerateAccountSummaryFiltered(accounts, accounts, nil, anomalies, "2025-04-15", 10000) {
+		got = append(got, summary.AccountID)
+	}
+	slices.Sort(got)
+	if want := []string{"FLAGGED", "RICH"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("high-value accounts = %v, want %v", got, want)
+	}
+}
+
+func TestWriteProcessedTransactionsColumns(t *testing.T) {
+	transactions := []models.Transaction{
+		{ID: "TX1", AccountID: "ACC1", Amount: models.Cents(12.5), Type: "debit", Status: "completed"},
+		{ID: "TX2", AccountID: "ACC2", Amount: models.Cents(300), Type: "credit", Status: "rejected", ProcessingMessage: "Account frozen"},
+	}
+	tests := []struct {
+		name       string
+		columns    []string
+		wantHeader []string
+		wantRows   [][]string // Nil to check only the header
+		wantErr    bool
+	}{
+		{name: "subset in the order given", columns: []string{"status", "transaction_id", "amount"},
+			wantHeader: []string{"status", "transaction_id", "amount"},
+			wantRows:   [][]string{{"completed", "TX1", "12.50"}, {"rejected", "TX2", "300.00"}}},
+		{name: "default full set", wantHeader: []string{"transaction_id", "account_id", "timestamp", "amount", "type", "status",
+			"description", "destination_account_id", "processing_message", "currency", "tags", "original_amount", "original_currency",
+			"exchange_rate", "destination_amount", "balance_after", "original_transaction_id", "reversed_type"}},
+		{name: "unknown column", columns: []string{"transaction_id", "balance"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "processed_transactions.csv")
+			err := WriteProcessedTransactionsColumns(transactions, path, tt.columns)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("an unknown column was accepted")
+				}
+				if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+					t.Error("a file was created for an unknown column")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			records, err := csv.NewReader(file).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records[0], tt.wantHeader) {
+				t.Errorf("header = %v, want %v", records[0], tt.wantHeader)
+			}
+			if tt.wantRows != nil && !reflect.DeepEqual(records[1:], tt.wantRows) {
+				t.Errorf("rows = %v, want %v", records[1:], tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestWriteAccountsAvailableBalance(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "accounts.csv")
+	accounts := map[string]models.Account{
+		"ACC1": {ID: "ACC1", Balance: models.Cents(500), HeldAmount: models.Cents(120.5)},
+		"ACC2": {ID: "ACC2", Balance: models.Cents(80)},
+	}
+	if err := WriteAccounts(accounts, path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := slices.Index(records[0], "available_balance")
+	if column < 0 {
+		t.Fatalf("header %v has no available_balance column", records[0])
+	}
+	if got := []string{records[1][column], records[2][column]}; !reflect.DeepEqual(got, []string{"379.50", "80.00"}) {
+		t.Errorf("available_balance column = %v, want [379.50 80.00]", got)
+	}
+
+	loaded, err := processor.LoadAccounts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, account := range accounts {
+		if got := loaded[id]; got.HeldAmount != account.HeldAmount || got.AvailableBalance() != account.AvailableBalance() {
+			t.Errorf("%s loaded with held %s and available %s, want %s and %s",
+				id, got.HeldAmount, got.AvailableBalance(), account.HeldAmount, account.AvailableBalance())
+		}
+	}
+
+	// A file carrying only the available balance has its held amount derived from it
+	derivedPath := filepath.Join(dir, "derived.csv")
+	if err := os.WriteFile(derivedPath, []byte("account_id,balance,available_balance\nACC1,500.00,379.50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	derived, err := processor.LoadAccounts(derivedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := derived["ACC1"].HeldAmount; got != models.Cents(120.5) {
+		t.Errorf("derived held amount = %s, want 120.50", got)
+	}
+}
+
+func TestDailyTotalsPersistAcrossRuns(t *testing.T) {
+	firstRun := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	debit := func(id string, timestamp time.Time, amount float64) []models.Transaction {
+		return []models.Transaction{{ID: id, AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(amount), Type: "debit", Status: "pending"}}
+	}
+	opening := map[string]models.Account{"ACC1": {ID: "ACC1", Balance: models.Cents(10000)}}
+	processor.ResetDailyTotals(opening, firstRun)
+	closing, processed := processor.ProcessTransactionsWithConfig(debit("TX1", firstRun, 3000), opening, processor.DefaultConfig())
+	if processed[0].Status != "completed" {
+		t.Fatalf("first run status = %s (%s), want completed", processed[0].Status, processed[0].ProcessingMessage)
+	}
+	path := filepath.Join(t.TempDir(), "accounts.csv")
+	if err := WriteAccounts(closing, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The daily withdrawal limit is 5000, so a second 2500 debit only fits on a new day
+	tests := []struct {
+		name       string
+		runAt      time.Time
+		wantStatus string
+	}{
+		{name: "same day", runAt: firstRun.Add(6 * time.Hour), wantStatus: "rejected"},
+		{name: "next day", runAt: firstRun.Add(24 * time.Hour), wantStatus: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := processor.LoadAccounts(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := accounts["ACC1"].DailyDebits; got != models.Cents(3000) {
+				t.Fatalf("loaded daily debits = %s, want 3000.00", got)
+			}
+			processor.ResetDailyTotals(accounts, tt.runAt)
+			_, processed := processor.ProcessTransactionsWithConfig(debit("TX2", tt.runAt, 2500), accounts, processor.DefaultConfig())
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSummarizeAnomalies(t *testing.T) {
+	anomalies := []models.Anomaly{
+		{TransactionID: "TX1", Type: "large_debit", Severity: "medium"},
+		{TransactionID: "TX2", Type: "account_overdraft", Severity: "high"},
+		{TransactionID: "TX3", Type: "large_debit", Severity: "medium"},
+		{TransactionID: "TX4", Type: "account_overdraft", Severity: "low"},
+		{TransactionID: "TX5", Type: "large_credit", Severity: "high"},
+		{TransactionID: "TX6", Type: "account_overdraft", Severity: "high"},
+	}
+	want := map[string]map[string]int{
+		"account_overdraft": {"low": 1, "high": 2},
+		"large_credit":      {"high": 1},
+		"large_debit":       {"medium": 2},
+	}
+	if got := SummarizeAnomalies(anomalies); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeAnomalies() = %v, want %v", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "anomaly_summary.csv")
+	if err := WriteAnomalySummary(GenerateAnomalySummary(anomalies), path); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRecords := [][]string{
+		{"type", "count", "low", "medium", "high", "example_transaction_ids"},
+		{"account_overdraft", "3", "1", "0", "2", "TX2;TX4;TX6"},
+		{"large_credit", "1", "0", "0", "1", "TX5"},
+		{"large_debit", "2", "0", "2", "0", "TX1;TX3"},
+	}
+	if !reflect.DeepEqual(records, wantRecords) {
+		t.Errorf("anomaly summary file = %q, want %q", records, wantRecords)
+	}
+}
+
+func TestGenerateAnomalySummary(t *testing.T) {
+	anomaly := func(transactionID string, anomalyType string, severity string) models.Anomaly {
+		return models.Anomaly{TransactionID: transactionID, AccountID: "ACC1", Type: anomalyType, Severity: severity}
+	}
+	anomalies := []models.Anomaly{
+		anomaly("TX1", "overdraft", "medium"),
+		anomaly("TX2", "large_transaction", "low"),
+		anomaly("TX3", "overdraft", "high"),
+		anomaly("TX4", "overdraft", "medium"),
+		anomaly("", "rejection_spike", "high"),
+		anomaly("TX5", "overdraft", "low"),
+		anomaly("TX6", "large_transaction", "low"),
+	}
+
+	want := []models.AnomalySummary{
+		{Type: "large_transaction", Count: 2, SeverityCounts: map[string]int{"low": 2}, ExampleTransactionIDs: []string{"TX2", "TX6"}},
+		{Type: "overdraft", Count: 4, SeverityCounts: map[string]int{"low": 1, "medium": 2, "high": 1},
+			ExampleTransactionIDs: []string{"TX1", "TX3", "TX4"}},
+		{Type: "rejection_spike", Count: 1, SeverityCounts: map[string]int{"high": 1}},
+	}
+	if got := GenerateAnomalySummary(anomalies); !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateAnomalySummary() = %+v, want %+v", got, want)
+	}
+	if got := GenerateAnomalySummary(nil); len(got) != 0 {
+		t.Errorf("GenerateAnomalySummary(nil) = %+v, want none", got)
+	}
+}
+
+func TestGenerateHourlyHistogram(t *testing.T) {
+	day := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	at := func(id string, accountID string, hour int, minute int, status string) models.Transaction {
+		return models.Transaction{ID: id, AccountID: accountID, Timestamp: day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute),
+			Amount: models.Cents(10), Type: "debit", Status: status}
+	}
+	transactions := []models.Transaction{
+		at("TX1", "ACC2", 9, 0, "completed"),
+		at("TX2", "ACC1", 9, 59, "completed"),
+		at("TX3", "ACC1", 9, 15, "completed"),
+		at("TX4", "ACC1", 14, 30, "completed"),
+		at("TX5", "ACC1", 23, 59, "completed"),
+		at("TX6", "ACC1", 14, 0, "rejected"),
+		at("TX7", "ACC3", 2, 0, "held"),
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     map[string]map[int]int // Account to its non-zero hour counts
+	}{
+		{name: "utc", location: time.UTC, want: map[string]map[int]int{"ACC1": {9: 2, 14: 1, 23: 1}, "ACC2": {9: 1}}},
+		{name: "business zone", location: newYork, want: map[string]map[int]int{"ACC1": {5: 2, 10: 1, 19: 1}, "ACC2": {5: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histograms := GenerateHourlyHistogram(transactions, tt.location)
+			if len(histograms) != len(tt.want) {
+				t.Fatalf("histograms = %+v, want one for each of %d accounts", histograms, len(tt.want))
+			}
+			for i, histogram := range histograms {
+				if i > 0 && histograms[i-1].AccountID >= histogram.AccountID {
+					t.Errorf("histograms are not in account order: %+v", histograms)
+				}
+				var want [24]int
+				for hour, count := range tt.want[histogram.AccountID] {
+					want[hour] = count
+				}
+				if histogram.Counts != want {
+					t.Errorf("%s counts = %v, want %v", histogram.AccountID, histogram.Counts, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyExpiringHolds(t *testing.T) {
+	dayEnd := time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)
+	const lifetime = 7 * 24 * time.Hour
+	placed := func(id string, accountID string, amount float64, daysAgo float64) models.Hold {
+		placedAt := dayEnd.Add(-time.Duration(daysAgo * float64(24*time.Hour)))
+		return models.Hold{ID: id, AccountID: accountID, Amount: models.Cents(amount), PlacedAt: placedAt, ExpiresAt: placedAt.Add(lifetime)}
+	}
+	holds := []models.Hold{
+		placed("H1", "ACC1", 100, 6.5), // Expires in half a day
+		placed("H2", "ACC1", 40, 5),    // Expires in exactly two days
+		placed("H3", "ACC1", 75, 4),    // Expires in three days
+		placed("H4", "ACC1", 60, 8),    // Already expired
+		placed("H5", "ACC2", 25, 7),    // Expires at the end of the day itself
+		placed("H6", "ACC3", 10, 6),    // Account without a summary
+	}
+
+	summaries := []models.AccountSummary{{AccountID: "ACC1"}, {AccountID: "ACC2"}}
+	ApplyExpiringHolds(summaries, holds, dayEnd, 2*24*time.Hour)
+
+	want := map[string][2]string{"ACC1": {"2", "140.00"}, "ACC2": {"0", "0.00"}}
+	for _, summary := range summaries {
+		if got := [2]string{strconv.Itoa(summary.ExpiringHoldsCount), summary.ExpiringHoldsAmount.String()}; got != want[summary.AccountID] {
+			t.Errorf("%s expiring holds = %v, want %v", summary.AccountID, got, want[summary.AccountID])
+		}
+	}
+}
+
+func TestGenerateOverdraftTransitions(t *testing.T) {
+	balances := func(values map[string]float64) map[string]models.Account {
+		accounts := make(map[string]models.Account, len(values))
+		for id, balance := range values {
+			accounts[id] = models.Account{ID: id, Balance: models.Cents(balance)}
+		}
+		return accounts
+	}
+	opening := balances(map[string]float64{"ENTER": 50, "CURE": -20, "STAY-OD": -10, "STAY-OK": 30, "ZERO": -5, "TO-ZERO": 0})
+	closing := balances(map[string]float64{"ENTER": -25, "CURE": 15, "STAY-OD": -40, "STAY-OK": 5, "ZERO": 0, "TO-ZERO": -1, "NEW": -100})
+
+	newlyOverdrawn, cured := GenerateOverdraftTransitions(opening, closing)
+	wantOverdrawn := []models.OverdraftTransition{
+		{AccountID: "ENTER", OpeningBalance: models.Cents(50), ClosingBalance: models.Cents(-25)},
+		{AccountID: "TO-ZERO", OpeningBalance: 0, ClosingBalance: models.Cents(-1)},
+	}
+	wantCured := []models.OverdraftTransition{
+		{AccountID: "CURE", OpeningBalance: models.Cents(-20), ClosingBalance: models.Cents(15)},
+		{AccountID: "ZERO", OpeningBalance: models.Cents(-5), ClosingBalance: 0},
+	}
+	if !reflect.DeepEqual(newlyOverdrawn, wantOverdrawn) {
+		t.Errorf("newly overdrawn = %+v, want %+v", newlyOverdrawn, wantOverdrawn)
+	}
+	if !reflect.DeepEqual(cured, wantCured) {
+		t.Errorf("cured = %+v, want %+v", cured, wantCured)
+	}
+}
+
+func TestGenerateLedgerTransferLegs(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	transfer := models.Transaction{ID: "TX1", AccountID: "ACC1", DestinationAccountID: "ACC2", Timestamp: timestamp,
+		Amount: models.Cents(125.4), Type: "transfer", Status: "completed"}
+	rejected := transfer
+	rejected.ID, rejected.Status = "TX2", "rejected"
+
+	entries := GenerateLedger([]models.Transaction{transfer, rejected})
+	want := []models.LedgerEntry{
+		{GroupID: "TX1", Leg: 1, AccountID: "ACC1", Timestamp: timestamp, Type: "transfer", Amount: models.Cents(-125.4)},
+		{GroupID: "TX1", Leg: 2, AccountID: "ACC2", Timestamp: timestamp, Type: "transfer", Amount: models.Cents(125.4)},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("GenerateLedger() = %+v, want %+v", entries, want)
+	}
+	if sum := entries[0].Amount + entries[1].Amount; sum != 0 {
+		t.Errorf("transfer legs sum to %s, want 0.00", sum)
+	}
+
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	if err := WriteLedger(entries, path); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRecords := [][]string{
+		{"group_id", "leg", "account_id", "timestamp", "type", "amount"},
+		{"TX1", "1", "ACC1", "2025-04-15T09:00:00Z", "transfer", "-125.40"},
+		{"TX1", "2", "ACC2", "2025-04-15T09:00:00Z", "transfer", "125.40"},
+	}
+	if !reflect.DeepEqual(records, wantRecords) {
+		t.Errorf("ledger file = %q, want %q", records, wantRecords)
+	}
+}
@@ -0,0 +1,103 @@
+// output/jobs.go
+package output
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Job is a single report write scheduled by the pipeline
+type Job struct {
+	Name  string // Report name used in log messages
+	Path  string // Destination file
+	Fatal bool   // Whether a failure aborts the batch
+	Write func(path string) error
+	Paths []string // Files written when the report is split into parts; empty when only Path is
+}
+
+// Files returns the files the job writes
+func (j Job) Files() []string {
+	if len(j.Paths) > 0 {
+		return j.Paths
+	}
+	return []string{j.Path}
+}
+
+// RunJobs runs the jobs using up to workers goroutines and returns each job's error by index.
+// With one worker the jobs run serially in order.
+func RunJobs(jobs []Job, workers int) []error {
+	errs := make([]error, len(jobs))
+	if workers <= 1 {
+		for i, job := range jobs {
+			errs[i] = job.Write(job.Path)
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workers)
+	for i, job := range jobs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, job Job) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = job.Write(job.Path)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// ExistingOutputs returns the files the jobs will write that already exist in the report sink
+func ExistingOutputs(jobs []Job) []string {
+	var existing []string
+	for _, job := range jobs {
+		for _, path := range job.Files() {
+			if reportSink.Exists(path) {
+				existing = append(existing, path)
+			}
+		}
+	}
+	return existing
+}
+
+// StaleOutputs returns the files a prior run split differently left in the report sink that the
+// jobs will not rewrite: the unsplit report, or parts beyond those written now
+func StaleOutputs(jobs []Job) []string {
+	var stale []string
+	for _, job := range jobs {
+		written := make(map[string]bool)
+		for _, path := range job.Files() {
+			written[path] = true
+		}
+		if !written[job.Path] && reportSink.Exists(job.Path) {
+			stale = append(stale, job.Path)
+		}
+		for part := 1; reportSink.Exists(PartPath(job.Path, part)); part++ {
+			if path := PartPath(job.Path, part); !written[path] {
+				stale = append(stale, path)
+			}
+		}
+	}
+	return stale
+}
+
+// RemoveFile deletes a file from the report sink
+func RemoveFile(filePath string) error {
+	if err := reportSink.Remove(filePath); err != nil {
+		return fmt.Errorf("error removing %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// BackupFile renames an existing file by appending a timestamp suffix and a .bak
+// extension, returning the backup path
+func BackupFile(filePath string, timestamp string) (string, error) {
+	backupPath := fmt.Sprintf("%s.%s.bak", filePath, timestamp)
+	if err := reportSink.Rename(filePath, backupPath); err != nil {
+		return "", fmt.Errorf("error backing up %s: %w", filePath, err)
+	}
+	return backupPath, nil
+}
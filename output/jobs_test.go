@@ -0,0 +1,147 @@
+// output/jobs_test.go
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPagedPaths(t *testing.T) {
+	tests := []struct {
+		name           string
+		rows           int
+		maxRowsPerFile int
+		want           []string
+	}{
+		{name: "unpaged", rows: 10, maxRowsPerFile: 0, want: []string{"anomalies.csv"}},
+		{name: "fits one page", rows: 3, maxRowsPerFile: 3, want: []string{"anomalies.csv"}},
+		{name: "empty", rows: 0, maxRowsPerFile: 3, want: []string{"anomalies.csv"}},
+		{name: "exact pages", rows: 6, maxRowsPerFile: 3, want: []string{"anomalies_part001.csv", "anomalies_part002.csv"}},
+		{name: "partial last page", rows: 7, maxRowsPerFile: 3,
+			want: []string{"anomalies_part001.csv", "anomalies_part002.csv", "anomalies_part003.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PagedPaths("anomalies.csv", tt.rows, tt.maxRowsPerFile); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PagedPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistingAndStaleOutputs(t *testing.T) {
+	tests := []struct {
+		name         string
+		previous     []string // Files left by an earlier run
+		paths        []string // Files this run writes; empty for just the report path
+		wantExisting []string
+		wantStale    []string
+	}{
+		{name: "first run", paths: []string{"anomalies_part001.csv", "anomalies_part002.csv"}},
+		{
+			name:         "same split",
+			previous:     []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+			paths:        []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+			wantExisting: []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+		},
+		{
+			name:         "fewer parts",
+			previous:     []string{"anomalies_part001.csv", "anomalies_part002.csv", "anomalies_part003.csv"},
+			paths:        []string{"anomalies_part001.csv"},
+			wantExisting: []string{"anomalies_part001.csv"},
+			wantStale:    []string{"anomalies_part002.csv", "anomalies_part003.csv"},
+		},
+		{
+			name:      "now unsplit",
+			previous:  []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+			wantStale: []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+		},
+		{
+			name:         "now split",
+			previous:     []string{"anomalies.csv"},
+			paths:        []string{"anomalies_part001.csv", "anomalies_part002.csv"},
+			wantExisting: nil,
+			wantStale:    []string{"anomalies.csv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.previous {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			inDir := func(names []string) []string {
+				var paths []string
+				for _, name := range names {
+					paths = append(paths, filepath.Join(dir, name))
+				}
+				return paths
+			}
+			jobs := []Job{{Name: "anomalies", Path: filepath.Join(dir, "anomalies.csv"), Paths: inDir(tt.paths)}}
+
+			if got := ExistingOutputs(jobs); !reflect.DeepEqual(got, inDir(tt.wantExisting)) {
+				t.Errorf("ExistingOutputs() = %v, want %v", got, inDir(tt.wantExisting))
+			}
+			stale := StaleOutputs(jobs)
+			if !reflect.DeepEqual(stale, inDir(tt.wantStale)) {
+				t.Errorf("StaleOutputs() = %v, want %v", stale, inDir(tt.wantStale))
+			}
+			for _, path := range stale {
+				if err := RemoveFile(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if again := StaleOutputs(jobs); again != nil {
+				t.Errorf("StaleOutputs() after removal = %v, want none", again)
+			}
+		})
+	}
+}
+
+func TestBackupFileKeepsPriorOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "accounts_2025-04-15.csv")
+	if err := os.WriteFile(path, []byte("prior good run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jobs := []Job{{Name: "accounts", Path: path, Write: func(path string) error {
+		return os.WriteFile(path, []byte("this run"), 0644)
+	}}}
+
+	existing := ExistingOutputs(jobs)
+	if !reflect.DeepEqual(existing, []string{path}) {
+		t.Fatalf("ExistingOutputs() = %v, want %v", existing, []string{path})
+	}
+	backupPath, err := BackupFile(path, "20250416T080000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := path + ".20250416T080000.bak"; backupPath != want {
+		t.Errorf("backup path = %s, want %s", backupPath, want)
+	}
+	if again := ExistingOutputs(jobs); again != nil {
+		t.Errorf("ExistingOutputs() after backup = %v, want none", again)
+	}
+	for _, err := range RunJobs(jobs, 1) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for file, want := range map[string]string{backupPath: "prior good run", path: "this run"} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, want)
+		}
+	}
+	if _, err := BackupFile(filepath.Join(dir, "missing.csv"), "20250416T080000"); err == nil {
+		t.Error("backing up a missing file succeeded")
+	}
+}
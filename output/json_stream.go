@@ -0,0 +1,144 @@
+// output/json_stream.go
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// Report formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// JSONArrayWriter streams a pretty-printed JSON array one element at a time so large
+// result sets never have to be marshaled as a whole
+type JSONArrayWriter struct {
+	writer *bufio.Writer
+	count  int
+}
+
+// NewJSONArrayWriter returns a writer that streams array elements to w
+func NewJSONArrayWriter(w io.Writer) *JSONArrayWriter {
+	return &JSONArrayWriter{writer: bufio.NewWriter(w)}
+}
+
+// Write encodes a single array element
+func (w *JSONArrayWriter) Write(v any) error {
+	element, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	separator := ",\n  "
+	if w.count == 0 {
+		separator = "[\n  "
+	}
+	if _, err := w.writer.WriteString(separator); err != nil {
+		return err
+	}
+	w.count++
+	_, err = w.writer.Write(element)
+	return err
+}
+
+// Close terminates the array and flushes any buffered output
+func (w *JSONArrayWriter) Close() error {
+	closing := "\n]\n"
+	if w.count == 0 {
+		closing = "[]\n"
+	}
+	if _, err := w.writer.WriteString(closing); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
+
+// WriteProcessedTransactionsJSON streams transactions to a JSON file as an array
+func WriteProcessedTransactionsJSON(transactions []models.Transaction, filePath string) error {
+	return WriteJSON(transactions, filePath)
+}
+
+// WriteAnomaliesJSON streams anomalies to a JSON file as an array
+func WriteAnomaliesJSON(anomalies []models.Anomaly, filePath string) error {
+	return WriteJSON(anomalies, filePath)
+}
+
+// WriteJSON streams any report's records to a JSON file as an array
+func WriteJSON[T any](items []T, filePath string) error {
+	return writeJSONFile(filePath, len(items), func(i int) any { return items[i] })
+}
+
+// writeJSONFile creates filePath and streams count elements produced by element
+func writeJSONFile(filePath string, count int, element func(i int) any) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating JSON file: %w", err)
+	}
+	defer closeFile(file, &err)
+
+	writer := NewJSONArrayWriter(file)
+	for i := 0; i < count; i++ {
+		if err := writer.Write(element(i)); err != nil {
+			return fmt.Errorf("error writing JSON element %d: %w", i, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
+
+	return nil
+}
+
+// CountTransactionMetrics fills the processed transaction counts and money totals of a run's metrics
+func CountTransactionMetrics(metrics *models.Metrics, transactions []models.Transaction) {
+	metrics.ProcessedTransactions = len(transactions)
+	for _, transaction := range transactions {
+		switch transaction.Status {
+		case "completed":
+			metrics.CompletedTransactions++
+		case "rejected":
+			metrics.RejectedTransactions++
+			continue
+		case "held":
+			metrics.HeldTransactions++
+			continue
+		default:
+			continue
+		}
+
+		sign := models.Money(1)
+		if transaction.Type == "reversal" {
+			transaction, sign = transaction.Reversed(), -1
+		}
+		switch transaction.Type {
+		case "credit":
+			metrics.TotalCredited += sign * transaction.Amount
+		case "debit", "fee":
+			metrics.TotalDebited += sign * transaction.Amount
+		}
+	}
+}
+
+// WriteRunMetrics writes a run's metrics to a JSON file
+func WriteRunMetrics(metrics models.Metrics, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating metrics file: %w", err)
+	}
+	defer closeFile(file, &err)
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding metrics: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing metrics: %w", err)
+	}
+
+	return nil
+}
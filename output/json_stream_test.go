@@ -0,0 +1,111 @@
+// output/json_stream_test.go
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestWriteJSONRoundTrip(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		transactions []models.Transaction
+	}{
+		{name: "empty", transactions: []models.Transaction{}},
+		{name: "one", transactions: []models.Transaction{
+			{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(12.34), Type: "credit", Status: "completed", BalanceAfter: models.Cents(12.34)},
+		}},
+		{name: "several", transactions: []models.Transaction{
+			{ID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Amount: models.Cents(12.34), Type: "credit", Status: "completed"},
+			{ID: "TX2", AccountID: "ACC1", DestinationAccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(5), Type: "transfer",
+				Status: "completed", Tags: map[string]string{"channel": "mobile"}},
+			{ID: "TX3", AccountID: "ACC2", Timestamp: timestamp, Amount: models.Cents(1e6), Type: "debit", Status: "rejected",
+				ProcessingMessage: "Insufficient funds"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "processed_transactions.json")
+			if err := WriteProcessedTransactionsJSON(tt.transactions, path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []models.Transaction
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("streamed JSON does not parse: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(got, tt.transactions) {
+				t.Errorf("parsed back %+v, want %+v", got, tt.transactions)
+			}
+		})
+	}
+}
+
+func TestWriteAnomaliesJSONRoundTrip(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	anomalies := []models.Anomaly{
+		{ID: "A1", TransactionID: "TX1", AccountID: "ACC1", Timestamp: timestamp, Type: "large_transaction",
+			Description: "Amount exceeds threshold", Severity: "medium"},
+		{ID: "A2", TransactionID: "TX2", AccountID: "ACC2", Timestamp: timestamp.Add(time.Minute), Type: "overdraft",
+			Description: "Balance below zero", Severity: "high"},
+	}
+	path := filepath.Join(t.TempDir(), "anomalies.json")
+	if err := WriteAnomaliesJSON(anomalies, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []models.Anomaly
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("streamed JSON does not parse: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, anomalies) {
+		t.Errorf("parsed back %+v, want %+v", got, anomalies)
+	}
+}
+
+func TestJSONArrayWriterMemoryStaysFlat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("streams a large array")
+	}
+	timestamp := time.Date(2025, 4, 15, 9, 30, 0, 0, time.UTC)
+	heapAfter := func(count int) uint64 {
+		writer := NewJSONArrayWriter(io.Discard)
+		for i := 0; i < count; i++ {
+			anomaly := models.Anomaly{ID: fmt.Sprintf("A%d", i), TransactionID: fmt.Sprintf("TX%d", i), AccountID: "ACC1",
+				Timestamp: timestamp, Type: "large_transaction", Description: "Large transaction", Severity: "medium"}
+			if err := writer.Write(anomaly); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		runtime.KeepAlive(writer)
+		return stats.HeapAlloc
+	}
+
+	small, large := heapAfter(1000), heapAfter(200000)
+	// 200,000 anomalies encode to tens of megabytes; a streaming writer holds only its buffer
+	if large > small+4<<20 {
+		t.Errorf("heap grew from %d to %d bytes while streaming 200x more elements", small, large)
+	}
+}
@@ -0,0 +1,79 @@
+// output/retry.go
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"DailyTransactionBatchProcessing/logging"
+)
+
+// RetryConfig controls how often creating an output file is retried, since a file still
+// open in a viewer can fail to open transiently on some file systems
+type RetryConfig struct {
+	Retries int           `json:"retries"` // Attempts after the first failure; 0 disables retrying
+	Backoff time.Duration `json:"backoff"` // Wait before the first retry, doubling for each further one
+}
+
+// DefaultRetryConfig returns the retry settings used when none are configured
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		Retries: 3,
+		Backoff: 100 * time.Millisecond,
+	}
+}
+
+// retryConfig applies to every output file the Write functions create
+var retryConfig = DefaultRetryConfig()
+
+// SetRetryConfig sets how output file creation is retried
+func SetRetryConfig(config RetryConfig) {
+	retryConfig = config
+}
+
+// withRetry calls create until it succeeds or the configured retries are used up, returning
+// the last error
+func withRetry[T any](filePath string, create func() (T, error)) (T, error) {
+	backoff := retryConfig.Backoff
+	for attempt := 0; ; attempt++ {
+		file, err := create()
+		if err == nil {
+			return file, nil
+		}
+		if attempt >= retryConfig.Retries {
+			if attempt > 0 {
+				err = fmt.Errorf("after %d attempts: %w", attempt+1, err)
+			}
+			return file, err
+		}
+		logging.Warnf("Retrying creation of %s in %s: %v", filePath, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// createFile creates an output file in the report sink, retrying transient failures
+func createFile(filePath string) (io.WriteCloser, error) {
+	return withRetry(filePath, func() (io.WriteCloser, error) {
+		return reportSink.Create(filePath)
+	})
+}
+
+// closeCSV flushes a report's CSV writer and closes its file, setting *err to the first failure
+// unless the write already failed, so a lost buffer or compressed trailer fails the report
+func closeCSV(writer *csv.Writer, file io.Closer, err *error) {
+	writer.Flush()
+	if flushErr := writer.Error(); flushErr != nil && *err == nil {
+		*err = fmt.Errorf("error writing CSV: %w", flushErr)
+	}
+	closeFile(file, err)
+}
+
+// closeFile closes a report's file, setting *err to the failure unless the write already failed
+func closeFile(file io.Closer, err *error) {
+	if closeErr := file.Close(); closeErr != nil && *err == nil {
+		*err = fmt.Errorf("error closing file: %w", closeErr)
+	}
+}
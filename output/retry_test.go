@@ -0,0 +1,86 @@
+// output/retry_test.go
+package output
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+var errFileLocked = errors.New("file in use by another process")
+
+// flakySink fails its first Create calls, up to failures, as a file still open in a viewer would
+type flakySink struct {
+	FileSink
+	failures int
+	attempts *int
+}
+
+func (s flakySink) Create(path string) (io.WriteCloser, error) {
+	*s.attempts++
+	if *s.attempts <= s.failures {
+		return nil, errFileLocked
+	}
+	return s.FileSink.Create(path)
+}
+
+func TestCreateFileRetries(t *testing.T) {
+	t.Cleanup(func() {
+		SetRetryConfig(DefaultRetryConfig())
+		SetReportSink(FileSink{})
+	})
+	SetRetryConfig(RetryConfig{Retries: 2, Backoff: time.Millisecond})
+	anomalies := []models.Anomaly{{TransactionID: "TX1", AccountID: "ACC1", Type: "large_debit", Severity: "medium"}}
+
+	tests := []struct {
+		name         string
+		failures     int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "first attempt", failures: 0, wantAttempts: 1},
+		{name: "transient then succeed", failures: 2, wantAttempts: 3},
+		{name: "retries used up", failures: 3, wantAttempts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			SetReportSink(flakySink{failures: tt.failures, attempts: &attempts})
+			path := filepath.Join(t.TempDir(), "fraud_alerts.csv")
+
+			err := WriteAnomalies(anomalies, path)
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, errFileLocked) || !strings.Contains(err.Error(), "after 3 attempts") {
+					t.Errorf("error = %v, want the locked-file error after 3 attempts", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "TX1") {
+				t.Errorf("report = %q (%v), want the anomaly written", data, err)
+			}
+		})
+	}
+
+	// A path below a regular file can never be created, even by root, so every retry fails
+	SetReportSink(FileSink{})
+	blocker := filepath.Join(t.TempDir(), "readonly")
+	if err := os.WriteFile(blocker, nil, 0444); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteAnomalies(anomalies, filepath.Join(blocker, "fraud_alerts.csv"))
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("error = %v, want a failure after 3 attempts", err)
+	}
+}
@@ -0,0 +1,60 @@
+// output/sink.go
+package output
+
+import (
+	"io"
+	"os"
+
+	"DailyTransactionBatchProcessing/gzipio"
+	"DailyTransactionBatchProcessing/models"
+)
+
+// FileSink is the default ReportSink, writing to the local file system and compressing reports
+// whose path ends in .gz
+type FileSink struct{}
+
+func (FileSink) Create(path string) (io.WriteCloser, error) {
+	return gzipio.Create(path)
+}
+
+func (FileSink) Open(path string) (io.ReadCloser, error) {
+	return gzipio.Open(path)
+}
+
+func (FileSink) Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (FileSink) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+func (FileSink) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// reportSink receives every report the Write functions create
+var reportSink models.ReportSink = FileSink{}
+
+// SetReportSink sets where reports are written and prior reports are read from
+func SetReportSink(sink models.ReportSink) {
+	reportSink = sink
+}
+
+// ReadReport reads back a report a prior run left in the report sink, returning false when
+// there is none
+func ReadReport[T any](path string, read func(io.Reader) (T, error)) (T, bool, error) {
+	var result T
+	if !reportSink.Exists(path) {
+		return result, false, nil
+	}
+	file, err := reportSink.Open(path)
+	if err != nil {
+		return result, false, err
+	}
+	defer file.Close()
+
+	result, err = read(file)
+	return result, true, err
+}
@@ -0,0 +1,42 @@
+// output/sort.go
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// Output orderings for processed transactions
+const (
+	SortProcessing  = ""             // Keep processing order
+	SortTime        = "time"         // Chronological
+	SortAccountTime = "account-time" // Grouped by account, chronological within each account
+)
+
+// SortTransactions returns a copy of the transactions in the requested order.
+// Ties keep their processing order.
+func SortTransactions(transactions []models.Transaction, order string) ([]models.Transaction, error) {
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+
+	switch order {
+	case SortProcessing:
+	case SortTime:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		})
+	case SortAccountTime:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if sorted[i].AccountID != sorted[j].AccountID {
+				return sorted[i].AccountID < sorted[j].AccountID
+			}
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		})
+	default:
+		return nil, fmt.Errorf("unknown sort order: %s", order)
+	}
+
+	return sorted, nil
+}
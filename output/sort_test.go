@@ -0,0 +1,54 @@
+// output/sort_test.go
+package output
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestSortTransactions(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	at := func(id string, accountID string, minutes int) models.Transaction {
+		return models.Transaction{ID: id, AccountID: accountID, Timestamp: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+	// Processing order interleaves the accounts, and TX4 and TX6 share a timestamp
+	transactions := []models.Transaction{
+		at("TX1", "ACC2", 30), at("TX2", "ACC1", 20), at("TX3", "ACC2", 10),
+		at("TX4", "ACC1", 5), at("TX5", "ACC3", 0), at("TX6", "ACC1", 5),
+	}
+
+	tests := []struct {
+		name  string
+		order string
+		want  []string
+	}{
+		{name: "processing", order: SortProcessing, want: []string{"TX1", "TX2", "TX3", "TX4", "TX5", "TX6"}},
+		{name: "time", order: SortTime, want: []string{"TX5", "TX4", "TX6", "TX3", "TX2", "TX1"}},
+		{name: "account then time", order: SortAccountTime, want: []string{"TX4", "TX6", "TX2", "TX3", "TX1", "TX5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := SortTransactions(transactions, tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, transaction := range sorted {
+				got = append(got, transaction.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if transactions[0].ID != "TX1" {
+		t.Error("SortTransactions reordered its input")
+	}
+	if _, err := SortTransactions(transactions, "amount"); err == nil {
+		t.Error("SortTransactions accepted an unknown order")
+	}
+}
@@ -0,0 +1,29 @@
+// output/state.go
+package output
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// WriteState writes a gob-encoded snapshot of the pipeline state for checkpoints and stage handoff
+func WriteState(state models.State, filePath string) (err error) {
+	file, err := createFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating state file: %w", err)
+	}
+	defer closeFile(file, &err)
+
+	writer := bufio.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(state); err != nil {
+		return fmt.Errorf("error encoding state: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// output/store.go
+package output
+
+import (
+	"DailyTransactionBatchProcessing/models"
+	"DailyTransactionBatchProcessing/processor"
+)
+
+// FileAccountStore is the default AccountStore, reading accounts from a local CSV file and
+// writing the updated accounts through the report sink
+type FileAccountStore struct {
+	Path         string // Accounts file read at the start of the day
+	FallbackPath string // Accounts file read when Path does not exist
+	SavePath     string // Updated accounts file written at the end of the day
+}
+
+// OpeningPath returns the file LoadAccounts reads: Path or FallbackPath, or the gzip-compressed
+// twin of either when only that exists
+func (s FileAccountStore) OpeningPath() string {
+	for _, path := range []string{s.Path, s.FallbackPath} {
+		if path == "" {
+			continue
+		}
+		for _, candidate := range []string{path, path + ".gz"} {
+			if (FileSink{}).Exists(candidate) {
+				return candidate
+			}
+		}
+	}
+	return s.Path
+}
+
+// LoadAccounts reads the accounts file
+func (s FileAccountStore) LoadAccounts() (map[string]models.Account, error) {
+	return processor.LoadAccounts(s.OpeningPath())
+}
+
+// SaveAccounts writes the updated accounts file
+func (s FileAccountStore) SaveAccounts(accounts map[string]models.Account) error {
+	return WriteAccounts(accounts, s.SavePath)
+}
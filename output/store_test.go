@@ -0,0 +1,65 @@
+// output/store_test.go
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestFileAccountStoreRoundTrip(t *testing.T) {
+	accounts := map[string]models.Account{
+		"A1": {ID: "A1", Balance: models.Cents(0.01), AccountType: "checking"},
+		"A2": {ID: "A2", Balance: models.Cents(-1205.55), OverdraftCount: 2},
+		"A3": {ID: "A3", Balance: models.Cents(1234567.89), HeldAmount: models.Cents(10.1), Currency: "EUR"},
+	}
+	for _, name := range []string{"accounts.csv", "accounts.csv.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			store := FileAccountStore{Path: path, SavePath: path}
+			if err := store.SaveAccounts(accounts); err != nil {
+				t.Fatalf("saving: %v", err)
+			}
+			loaded, err := store.LoadAccounts()
+			if err != nil {
+				t.Fatalf("loading: %v", err)
+			}
+			for id, want := range accounts {
+				got := loaded[id]
+				if got.Balance != want.Balance || got.HeldAmount != want.HeldAmount {
+					t.Errorf("%s balance/held = %d/%d cents, want %d/%d", id, got.Balance, got.HeldAmount, want.Balance, want.HeldAmount)
+				}
+			}
+		})
+	}
+}
+
+func TestFileAccountStoreOpeningPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "dated file", files: []string{"accounts_2025-04-15.csv", "accounts.csv"}, want: "accounts_2025-04-15.csv"},
+		{name: "compressed dated file", files: []string{"accounts_2025-04-15.csv.gz", "accounts.csv"}, want: "accounts_2025-04-15.csv.gz"},
+		{name: "fallback", files: []string{"accounts.csv"}, want: "accounts.csv"},
+		{name: "compressed fallback", files: []string{"accounts.csv.gz"}, want: "accounts.csv.gz"},
+		{name: "nothing", want: "accounts_2025-04-15.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			store := FileAccountStore{Path: filepath.Join(dir, "accounts_2025-04-15.csv"), FallbackPath: filepath.Join(dir, "accounts.csv")}
+			if got := store.OpeningPath(); got != filepath.Join(dir, tt.want) {
+				t.Errorf("OpeningPath() = %s, want %s", got, filepath.Join(dir, tt.want))
+			}
+		})
+	}
+}
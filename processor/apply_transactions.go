@@ -2,22 +2,104 @@
 package processor
 
 import (
+	"DailyTransactionBatchProcessing/gzipio"
 	"DailyTransactionBatchProcessing/models"
 	"encoding/csv"
 	"fmt"
-	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// Constants for business rules
-const (
-	OverdraftLimit          = -1000.0 // Maximum allowed overdraft
-	MaxDailyWithdrawalLimit = 5000.0  // Maximum daily withdrawal limit
-)
+// Config holds the business rules applied during processing
+type Config struct {
+	// Overdraft and withdrawal limits, shared with anomaly detection
+	models.Limits
+
+	// Further outflow limits; 0 means no limit. AccountLimits overrides the defaults per account
+	MaxDailyTransferLimit     float64                  `json:"max_daily_transfer_limit"`
+	MaxSingleTransactionLimit float64                  `json:"max_single_transaction_limit"`
+	AccountLimits             map[string]AccountLimits `json:"account_limits"`
+
+	// Cooling-off rule between transactions on the same account at or above LargeTransactionThreshold
+	CoolingOffPeriodMins     float64 `json:"cooling_off_period_mins"` // 0 disables the rule
+	HoldCoolingOffViolations bool    `json:"hold_cooling_off_violations"`
+
+	// Transactions above this amount are held for manual review regardless of type; 0 disables
+	ManualReviewThreshold float64 `json:"manual_review_threshold"`
+
+	// Destination eligibility for transfers. Frozen accounts are rejected earlier, in validation.
+	ExcludedDestinations map[string]bool `json:"excluded_destinations"`
+
+	// Overdraft fees: the fee for an overdraft is OverdraftFeeSchedule[n-1] for the
+	// account's nth overdraft, the last tier repeating; an empty schedule charges no fees
+	OverdraftFeeSchedule  []float64 `json:"overdraft_fee_schedule"`
+	DailyOverdraftFeeCap  float64   `json:"daily_overdraft_fee_cap"`  // 0 means no cap
+	OverdraftFeeGraceDays int       `json:"overdraft_fee_grace_days"` // Fee-free days after an account opens; 0 disables
+
+	// Fees charged on completed transactions by type, deducted from the source account
+	TransactionFees FeeConfig `json:"transaction_fees"`
+
+	// Exchange rates keyed by RateKey(from, to), used to convert transactions into the account currency
+	ExchangeRates map[string]float64 `json:"exchange_rates"`
+
+	// Currency pairs keyed by RateKey(from, to) that transfers may convert between; transfers
+	// between accounts in other differing currencies are rejected
+	AllowedConversionPairs map[string]bool `json:"allowed_conversion_pairs"`
+
+	// Verify money conservation after processing
+	StrictInvariants bool `json:"strict_invariants"`
+
+	// Outcomes a prior run for the same date recorded, by transaction ID. Completed transactions
+	// are applied again from the same opening balances and must reproduce the recorded balance;
+	// the rest keep their recorded outcome instead of being retried.
+	PriorDecisions map[string]models.Transaction `json:"-"`
+
+	// Create a zero-balance account for a credit to an account that does not exist yet
+	AutoCreateOnCredit bool `json:"autocreate_on_credit"`
+
+	// Hold transactions that would exceed the overdraft limit for the next batch instead of rejecting them
+	HoldInsufficientFunds bool `json:"hold_insufficient_funds"`
 
-// LoadAccounts loads account data from a CSV file
+	// Account shards processed concurrently; 1 is serial
+	Workers int `json:"workers"`
+}
+
+// DefaultConfig returns the business rules used when no config is supplied
+func DefaultConfig() Config {
+	return Config{
+		Limits:  models.DefaultLimits(),
+		Workers: 1,
+	}
+}
+
+// accountColumns are the accounts file columns in the order LoadAccounts reads them; the first
+// requiredAccountColumns must be present
+var accountColumns = []string{
+	"account_id",
+	"balance",
+	"overdraft_count",
+	"last_transaction_time",
+	"account_type",
+	"held_amount",
+	"available_balance",
+	"currency",
+	"approved_overdraft",
+	"reserved_balance",
+	"account_open_date",
+	"overdraft_limit",
+	"daily_debits",
+	"daily_credits",
+	"daily_transfers",
+	"daily_totals_date",
+}
+
+const requiredAccountColumns = 2
+
+// LoadAccounts loads account data from a CSV file, matching its columns by header name
 func LoadAccounts(filePath string) (map[string]models.Account, error) {
-	file, err := os.Open(filePath)
+	file, err := gzipio.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("error opening accounts file: %w", err)
 	}
@@ -34,13 +116,19 @@ func LoadAccounts(filePath string) (map[string]models.Account, error) {
 		return nil, fmt.Errorf("accounts file is empty or missing data rows")
 	}
 
-	// Skip header row
+	// Map the columns by header name
+	columns, err := models.MapColumns(records[0], accountColumns, requiredAccountColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accounts file header: %w", err)
+	}
+
 	accounts := make(map[string]models.Account)
 	for i, record := range records {
 		// Skip header row
 		if i == 0 {
 			continue
 		}
+		record = columns.Reorder(record)
 
 		// Ensure we have the expected number of fields
 		if len(record) < 2 {
@@ -49,7 +137,7 @@ func LoadAccounts(filePath string) (map[string]models.Account, error) {
 
 		// Parse account data
 		accountID := record[0]
-		balance, err := strconv.ParseFloat(record[1], 64)
+		balance, err := models.ParseMoney(record[1])
 		if err != nil {
 			return nil, fmt.Errorf("invalid balance at line %d: %w", i+1, err)
 		}
@@ -71,58 +159,317 @@ func LoadAccounts(filePath string) (map[string]models.Account, error) {
 			}
 		}
 
+		if len(record) > 3 && record[3] != "" {
+			lastTxTime, err := time.Parse(time.RFC3339, record[3])
+			if err == nil {
+				account.LastTransactionTime = lastTxTime
+			}
+		}
+		if len(record) > 4 {
+			account.AccountType = record[4]
+		}
+
+		// Parse held amount, deriving it from available balance when only that is present
+		if len(record) > 5 && record[5] != "" {
+			heldAmount, err := models.ParseMoney(record[5])
+			if err != nil {
+				return nil, fmt.Errorf("invalid held amount at line %d: %w", i+1, err)
+			}
+			account.HeldAmount = heldAmount
+		} else if len(record) > 6 && record[6] != "" {
+			availableBalance, err := models.ParseMoney(record[6])
+			if err != nil {
+				return nil, fmt.Errorf("invalid available balance at line %d: %w", i+1, err)
+			}
+			account.HeldAmount = balance - availableBalance
+		}
+
+		if len(record) > 7 {
+			account.Currency = record[7]
+		}
+		if len(record) > 8 && record[8] != "" {
+			approved, err := strconv.ParseBool(record[8])
+			if err != nil {
+				return nil, fmt.Errorf("invalid approved overdraft flag at line %d: %w", i+1, err)
+			}
+			account.ApprovedOverdraft = approved
+		}
+		if len(record) > 9 && record[9] != "" {
+			reserved, err := models.ParseMoney(record[9])
+			if err != nil {
+				return nil, fmt.Errorf("invalid reserved balance at line %d: %w", i+1, err)
+			}
+			account.ReservedBalance = reserved
+		}
+		if len(record) > 10 && record[10] != "" {
+			openDate, err := time.Parse("2006-01-02", record[10])
+			if err != nil {
+				return nil, fmt.Errorf("invalid account open date at line %d: %w", i+1, err)
+			}
+			account.AccountOpenDate = openDate
+		}
+		if len(record) > 11 && record[11] != "" {
+			limit, err := models.ParseMoney(record[11])
+			if err != nil {
+				return nil, fmt.Errorf("invalid overdraft limit at line %d: %w", i+1, err)
+			}
+			if limit > 0 {
+				return nil, fmt.Errorf("invalid overdraft limit at line %d: %s must not be positive", i+1, limit)
+			}
+			account.OverdraftLimit = limit
+		}
+
+		// Parse the daily totals carried over from an earlier run
+		dailyTotals := []*models.Money{&account.DailyDebits, &account.DailyCredits, &account.DailyTransfers}
+		for j, total := range dailyTotals {
+			if len(record) > 12+j && record[12+j] != "" {
+				value, err := models.ParseMoney(record[12+j])
+				if err != nil {
+					return nil, fmt.Errorf("invalid daily total at line %d: %w", i+1, err)
+				}
+				*total = value
+			}
+		}
+		if len(record) > 15 && record[15] != "" {
+			totalsDate, err := time.Parse("2006-01-02", record[15])
+			if err != nil {
+				return nil, fmt.Errorf("invalid daily totals date at line %d: %w", i+1, err)
+			}
+			account.DailyTotalsDate = totalsDate
+		}
+
 		accounts[accountID] = account
 	}
 
 	return accounts, nil
 }
 
+// ResetDailyTotals starts each account's daily debit, credit and transfer totals for the
+// processing date, keeping totals already accumulated for that date by an earlier run
+func ResetDailyTotals(accounts map[string]models.Account, processDate time.Time) {
+	day := processDate.Format("2006-01-02")
+	for id, account := range accounts {
+		if account.DailyTotalsDate.Format("2006-01-02") != day {
+			account.DailyDebits = 0
+			account.DailyCredits = 0
+			account.DailyTransfers = 0
+		}
+		account.DailyTotalsDate = processDate
+		accounts[id] = account
+	}
+}
+
+// NormalizeAccountIDs rebuilds the accounts map with normalized account IDs,
+// returning an error if two accounts normalize to the same ID
+func NormalizeAccountIDs(
+	accounts map[string]models.Account,
+	normalizer models.IDNormalizer,
+) (map[string]models.Account, error) {
+	normalized := make(map[string]models.Account, len(accounts))
+	for id, account := range accounts {
+		key := normalizer.Normalize(id)
+		if _, exists := normalized[key]; exists {
+			return nil, fmt.Errorf("account IDs %q and another account both normalize to %q", id, key)
+		}
+		account.ID = normalizer.Normalize(account.ID)
+		normalized[key] = account
+	}
+	return normalized, nil
+}
+
+// NeedsManualReview reports whether a transaction exceeds the manual review threshold
+func NeedsManualReview(transaction models.Transaction, config Config) bool {
+	return config.ManualReviewThreshold > 0 && transaction.Amount > models.Cents(config.ManualReviewThreshold)
+}
+
 // ProcessTransactions applies transactions to account balances
 func ProcessTransactions(
 	transactions []models.Transaction,
 	accounts map[string]models.Account,
+) (map[string]models.Account, []models.Transaction) {
+	return ProcessTransactionsWithConfig(transactions, accounts, DefaultConfig())
+}
+
+// ProcessTransactionsWithConfig applies transactions to account balances using the supplied rules
+func ProcessTransactionsWithConfig(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
 ) (map[string]models.Account, []models.Transaction) {
 	// Create a copy of accounts to avoid modifying the original
-	processedAccounts := make(map[string]models.Account)
+	processedAccounts := make(map[string]models.Account, len(accounts))
 	for id, account := range accounts {
 		processedAccounts[id] = account
 	}
 
+	if config.Workers > 1 {
+		return processTransactionsSharded(transactions, processedAccounts, config)
+	}
+	return ProcessTransactionsInPlace(transactions, processedAccounts, config)
+}
+
+// ProcessTransactionsInPlace applies transactions directly to the supplied accounts map.
+// The map is mutated and returned; use it only when the caller owns the map and does
+// not need the original balances preserved.
+func ProcessTransactionsInPlace(
+	transactions []models.Transaction,
+	processedAccounts map[string]models.Account,
+	config Config,
+) (map[string]models.Account, []models.Transaction) {
 	// Process transactions
-	processedTransactions := make([]models.Transaction, len(transactions))
-	copy(processedTransactions, transactions)
+	processedTransactions := make([]models.Transaction, 0, len(transactions))
 
 	// Sort transactions by timestamp
 	// In a real system, we would sort here, but for simplicity we'll assume
 	// transactions are already in chronological order
 
-	for i, transaction := range processedTransactions {
-		// Get the account
-		account := processedAccounts[transaction.AccountID]
+	state := newProcessingState()
+	for _, transaction := range transactions {
+		processedTransactions = append(processedTransactions, processTransaction(transaction, processedAccounts, config, state)...)
+	}
+
+	return processedAccounts, processedTransactions
+}
+
+// processingState tracks per-account history carried between the transactions of a run
+type processingState struct {
+	// Last completed large transaction time by account for the cooling-off rule
+	lastLargeTime map[string]time.Time
+
+	// Overdraft fees charged today by account for the daily fee cap
+	overdraftFeesToday map[string]models.Money
 
-		switch transaction.Type {
-		case "credit":
-			// Handle deposit
-			processedTransactions[i], processedAccounts = processCredit(transaction, account, processedAccounts)
+	// Completed transactions by ID, and those already reversed, for resolving reversals. A
+	// reversal also undoes the fees a transaction incurred and any overdraft it started.
+	completed map[string]models.Transaction
+	reversed  map[string]bool
+	fees      map[string][]models.Transaction
+	overdrawn map[string]bool
+}
+
+func newProcessingState() *processingState {
+	return &processingState{
+		lastLargeTime:      make(map[string]time.Time),
+		overdraftFeesToday: make(map[string]models.Money),
+		completed:          make(map[string]models.Transaction),
+		reversed:           make(map[string]bool),
+		fees:               make(map[string][]models.Transaction),
+		overdrawn:          make(map[string]bool),
+	}
+}
+
+// processTransaction applies one transaction to the accounts, returning it followed by any
+// fees it incurred
+func processTransaction(
+	transaction models.Transaction,
+	processedAccounts map[string]models.Account,
+	config Config,
+	state *processingState,
+) []models.Transaction {
+	// Keep the outcome a prior run recorded for a transaction it did not post
+	if prior, decided := config.PriorDecisions[transaction.ID]; decided && prior.Status != "completed" {
+		return []models.Transaction{ReplayOutcome(transaction, prior)}
+	}
+
+	// Reversals undo an earlier transaction as it posted, outside the rules applied to new activity
+	if transaction.Type == "reversal" {
+		return processReversal(transaction, processedAccounts, state)
+	}
+
+	// Open accounts receiving their first credit
+	if _, exists := processedAccounts[transaction.AccountID]; !exists && config.AutoCreateOnCredit && transaction.Type == "credit" {
+		processedAccounts[transaction.AccountID] = newAccountForCredit(transaction)
+	}
+
+	// Get the account
+	account := processedAccounts[transaction.AccountID]
+
+	// Convert foreign-currency transactions into the account currency
+	transaction = convertToAccountCurrency(transaction, account, config.ExchangeRates)
+	if reason := checkCurrency(transaction, account); reason != "" {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = reason
+		return []models.Transaction{transaction}
+	}
 
-		case "debit":
-			// Handle withdrawal
-			processedTransactions[i], processedAccounts = processDebit(transaction, account, processedAccounts)
+	// Hold transactions above the review threshold for manual review
+	if NeedsManualReview(transaction, config) {
+		transaction.Status = "held"
+		transaction.ProcessingMessage = fmt.Sprintf(
+			"Held: manual review required for amounts above $%.2f", config.ManualReviewThreshold)
+		return []models.Transaction{transaction}
+	}
 
-		case "transfer":
-			// Handle transfer
-			processedTransactions[i], processedAccounts = processTransfer(transaction, processedAccounts)
+	// Hold large transactions inside the cooling-off period of the previous one
+	isLarge := transaction.Amount >= models.Cents(config.LargeTransactionThreshold)
+	if isLarge && config.HoldCoolingOffViolations && config.CoolingOffPeriodMins > 0 {
+		if last, exists := state.lastLargeTime[transaction.AccountID]; exists &&
+			transaction.Timestamp.Sub(last).Minutes() < config.CoolingOffPeriodMins {
+			transaction.Status = "held"
+			transaction.ProcessingMessage = fmt.Sprintf(
+				"Held: within %d minute cooling-off period after a large transaction", int(config.CoolingOffPeriodMins))
+			return []models.Transaction{transaction}
 		}
+	}
 
-		// Update last transaction time
-		if processedTransactions[i].Status == "completed" {
-			account = processedAccounts[transaction.AccountID]
-			account.LastTransactionTime = transaction.Timestamp
-			processedAccounts[transaction.AccountID] = account
+	// The transaction and its fee together must stay within the overdraft limit
+	fee := transactionFee(transaction, config.TransactionFees)
+	if fee > 0 && (transaction.Type == "debit" || transaction.Type == "transfer") {
+		if limit := config.OverdraftLimitFor(account); account.Balance-transaction.Amount-fee < limit {
+			transaction = declineInsufficientFunds(transaction, fmt.Sprintf(
+				"Would exceed overdraft limit of $%s including fee of $%s", -limit, fee), config)
+			return []models.Transaction{transaction}
 		}
 	}
 
-	return processedAccounts, processedTransactions
+	overdraftsBefore := account.OverdraftCount
+
+	switch transaction.Type {
+	case "credit":
+		// Handle deposit
+		transaction, processedAccounts = processCredit(transaction, account, processedAccounts)
+
+	case "debit":
+		// Handle withdrawal
+		transaction, processedAccounts = processDebit(transaction, account, processedAccounts, config)
+
+	case "transfer":
+		// Handle transfer
+		transaction, processedAccounts = processTransfer(transaction, processedAccounts, config)
+	}
+
+	// Update last transaction time
+	if transaction.Status == "completed" {
+		account = processedAccounts[transaction.AccountID]
+		account.LastTransactionTime = transaction.Timestamp
+		processedAccounts[transaction.AccountID] = account
+
+		if isLarge {
+			state.lastLargeTime[transaction.AccountID] = transaction.Timestamp
+		}
+		state.completed[transaction.ID] = transaction
+	}
+
+	rows := []models.Transaction{transaction}
+
+	// Charge the transaction fee
+	if fee > 0 && transaction.Status == "completed" {
+		rows = append(rows, chargeTransactionFee(transaction, fee, processedAccounts))
+	}
+
+	// Assess an overdraft fee when this transaction caused an overdraft
+	if processedAccounts[transaction.AccountID].OverdraftCount > overdraftsBefore {
+		state.overdrawn[transaction.ID] = true
+		fee, charged := assessOverdraftFee(transaction, processedAccounts, config, state.overdraftFeesToday)
+		if charged {
+			rows = append(rows, fee)
+		}
+	}
+
+	if len(rows) > 1 {
+		state.fees[transaction.ID] = rows[1:]
+	}
+	return rows
 }
 
 // processCredit handles deposit transactions
@@ -138,27 +485,69 @@ func processCredit(
 
 	// Update transaction status
 	transaction.Status = "completed"
+	transaction.BalanceAfter = account.Balance
 	return transaction, accounts
 }
 
+// newAccountForCredit returns the zero-balance account created for a credit to an unknown
+// account, opened on the credit's date in its currency
+func newAccountForCredit(transaction models.Transaction) models.Account {
+	year, month, day := transaction.Timestamp.Date()
+	openDate := time.Date(year, month, day, 0, 0, 0, 0, transaction.Timestamp.Location())
+	return models.Account{
+		ID:              transaction.AccountID,
+		Currency:        transaction.Currency,
+		AccountOpenDate: openDate,
+		DailyTotalsDate: openDate,
+	}
+}
+
+// CreatedAccounts returns the IDs of accounts present after processing but not before, in ID order
+func CreatedAccounts(before map[string]models.Account, after map[string]models.Account) []string {
+	created := []string{}
+	for id := range after {
+		if _, exists := before[id]; !exists {
+			created = append(created, id)
+		}
+	}
+	sort.Strings(created)
+	return created
+}
+
 // processDebit handles withdrawal transactions
 func processDebit(
 	transaction models.Transaction,
 	account models.Account,
 	accounts map[string]models.Account,
+	config Config,
 ) (models.Transaction, map[string]models.Account) {
+	limits := limitsFor(transaction.AccountID, config)
+
+	// Check if withdrawal would exceed the single transaction limit
+	if limits.SingleTransaction > 0 && transaction.Amount > models.Cents(limits.SingleTransaction) {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Exceeds single transaction limit of $%.2f", limits.SingleTransaction)
+		return transaction, accounts
+	}
+
 	// Check if withdrawal would exceed daily limit
-	if account.DailyDebits+transaction.Amount > MaxDailyWithdrawalLimit {
+	if account.DailyDebits+transaction.Amount > models.Cents(limits.DailyWithdrawal) {
 		transaction.Status = "rejected"
-		transaction.ProcessingMessage = fmt.Sprintf("Exceeds daily withdrawal limit of $%.2f", MaxDailyWithdrawalLimit)
+		transaction.ProcessingMessage = fmt.Sprintf("Exceeds daily withdrawal limit of $%.2f", limits.DailyWithdrawal)
 		return transaction, accounts
 	}
 
-	// Check if withdrawal would exceed overdraft limit
+	// Check if withdrawal would dip into the minimum reserve
 	newBalance := account.Balance - transaction.Amount
-	if newBalance < OverdraftLimit {
+	if reason := checkReserve(account, newBalance); reason != "" {
 		transaction.Status = "rejected"
-		transaction.ProcessingMessage = fmt.Sprintf("Would exceed overdraft limit of $%.2f", -OverdraftLimit)
+		transaction.ProcessingMessage = reason
+		return transaction, accounts
+	}
+
+	// Check if withdrawal would exceed overdraft limit
+	if limit := config.OverdraftLimitFor(account); newBalance < limit {
+		transaction = declineInsufficientFunds(transaction, fmt.Sprintf("Would exceed overdraft limit of $%s", -limit), config)
 		return transaction, accounts
 	}
 
@@ -176,6 +565,7 @@ func processDebit(
 
 	// Update transaction status
 	transaction.Status = "completed"
+	transaction.BalanceAfter = account.Balance
 	return transaction, accounts
 }
 
@@ -183,23 +573,81 @@ func processDebit(
 func processTransfer(
 	transaction models.Transaction,
 	accounts map[string]models.Account,
+	config Config,
 ) (models.Transaction, map[string]models.Account) {
-	sourceAccount := accounts[transaction.AccountID]
-	destAccount := accounts[transaction.DestinationAccountID]
+	// Both sides must exist before either is touched, so a transfer never debits the source
+	// without crediting a real destination
+	sourceAccount, sourceExists := accounts[transaction.AccountID]
+	if !sourceExists {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Source account %s does not exist", transaction.AccountID)
+		return transaction, accounts
+	}
+	destAccount, destExists := accounts[transaction.DestinationAccountID]
+	if !destExists {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Destination account %s does not exist", transaction.DestinationAccountID)
+		return transaction, accounts
+	}
 
-	// Check if transfer would exceed overdraft limit
+	// Check the destination can receive funds before touching the source
+	if reason := checkDestination(transaction, config); reason != "" {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = reason
+		return transaction, accounts
+	}
+
+	limits := limitsFor(transaction.AccountID, config)
+
+	// Check if transfer would exceed the single transaction limit
+	if limits.SingleTransaction > 0 && transaction.Amount > models.Cents(limits.SingleTransaction) {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Exceeds single transaction limit of $%.2f", limits.SingleTransaction)
+		return transaction, accounts
+	}
+
+	// Outbound transfers count toward the same daily withdrawal ceiling as debits
+	if sourceAccount.DailyDebits+transaction.Amount > models.Cents(limits.DailyWithdrawal) {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Transfer exceeds daily withdrawal limit of $%.2f", limits.DailyWithdrawal)
+		return transaction, accounts
+	}
+
+	// Check if transfer would exceed the daily transfer limit
+	if limits.DailyTransfer > 0 && sourceAccount.DailyTransfers+transaction.Amount > models.Cents(limits.DailyTransfer) {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Exceeds daily transfer limit of $%.2f", limits.DailyTransfer)
+		return transaction, accounts
+	}
+
+	// Convert between the account currencies when they differ
+	transaction, reason := convertTransferAmount(transaction, sourceAccount, destAccount, config)
+	if reason != "" {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = reason
+		return transaction, accounts
+	}
+
+	// Check if transfer would dip into the minimum reserve
 	newBalance := sourceAccount.Balance - transaction.Amount
-	if newBalance < OverdraftLimit {
+	if reason := checkReserve(sourceAccount, newBalance); reason != "" {
 		transaction.Status = "rejected"
-		transaction.ProcessingMessage = fmt.Sprintf("Would exceed overdraft limit of $%.2f", -OverdraftLimit)
+		transaction.ProcessingMessage = reason
+		return transaction, accounts
+	}
+
+	// Check if transfer would exceed overdraft limit
+	if limit := config.OverdraftLimitFor(sourceAccount); newBalance < limit {
+		transaction = declineInsufficientFunds(transaction, fmt.Sprintf("Would exceed overdraft limit of $%s", -limit), config)
 		return transaction, accounts
 	}
 
 	// Apply transfer
 	sourceAccount.Balance = newBalance
 	sourceAccount.DailyDebits += transaction.Amount
-	destAccount.Balance += transaction.Amount
-	destAccount.DailyCredits += transaction.Amount
+	sourceAccount.DailyTransfers += transaction.Amount
+	destAccount.Balance += transaction.CreditedAmount()
+	destAccount.DailyCredits += transaction.CreditedAmount()
 
 	// Check if source account is in overdraft after this transaction
 	if newBalance < 0 {
@@ -212,5 +660,185 @@ func processTransfer(
 
 	// Update transaction status
 	transaction.Status = "completed"
+	transaction.BalanceAfter = sourceAccount.Balance
 	return transaction, accounts
 }
+
+// processReversal undoes a transaction completed earlier in the run, restoring the balances and
+// daily totals it changed and the overdraft it started, and refunding its fees. The reversal takes
+// the accounts, amounts, and currency of the original as posted; each refunded fee follows it as a
+// reversal row of its own.
+func processReversal(
+	transaction models.Transaction,
+	accounts map[string]models.Account,
+	state *processingState,
+) []models.Transaction {
+	original, exists := state.completed[transaction.OriginalTransactionID]
+	if !exists {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Original transaction %s was not completed", transaction.OriginalTransactionID)
+		return []models.Transaction{transaction}
+	}
+	if state.reversed[original.ID] {
+		transaction.Status = "rejected"
+		transaction.ProcessingMessage = fmt.Sprintf("Original transaction %s is already reversed", original.ID)
+		return []models.Transaction{transaction}
+	}
+
+	transaction.AccountID = original.AccountID
+	transaction.Amount = original.Amount
+	transaction.Currency = original.Currency
+	transaction.DestinationAccountID = original.DestinationAccountID
+	transaction.DestinationAmount = original.DestinationAmount
+	transaction.ReversedType = original.Type
+
+	account := accounts[transaction.AccountID]
+	switch original.Type {
+	case "credit":
+		account.Balance -= original.Amount
+		account.DailyCredits -= original.Amount
+	case "debit":
+		account.Balance += original.Amount
+		account.DailyDebits -= original.Amount
+	case "transfer":
+		account.Balance += original.Amount
+		account.DailyDebits -= original.Amount
+		account.DailyTransfers -= original.Amount
+		destAccount := accounts[original.DestinationAccountID]
+		destAccount.Balance -= original.CreditedAmount()
+		destAccount.DailyCredits -= original.CreditedAmount()
+		accounts[original.DestinationAccountID] = destAccount
+	}
+	if state.overdrawn[original.ID] && account.OverdraftCount > 0 {
+		account.OverdraftCount--
+	}
+	account.LastTransactionTime = transaction.Timestamp
+	state.reversed[original.ID] = true
+
+	transaction.Status = "completed"
+	transaction.ProcessingMessage = fmt.Sprintf("Reversed %s transaction %s", original.Type, original.ID)
+	if account.Balance < 0 {
+		transaction.ProcessingMessage += "; account in overdraft"
+	}
+	transaction.BalanceAfter = account.Balance
+	rows := []models.Transaction{transaction}
+
+	// Refund the fees the original incurred, releasing overdraft fees from today's cap
+	for _, fee := range state.fees[original.ID] {
+		account.Balance += fee.Amount
+		if strings.HasSuffix(fee.ID, "-ODFEE") {
+			state.overdraftFeesToday[fee.AccountID] -= fee.Amount
+		}
+		rows = append(rows, models.Transaction{
+			ID:                    fee.ID + "-REV",
+			AccountID:             fee.AccountID,
+			OriginalTransactionID: fee.ID,
+			Timestamp:             transaction.Timestamp,
+			Amount:                fee.Amount,
+			Type:                  "reversal",
+			ReversedType:          "fee",
+			Status:                "completed",
+			Description:           "Refund: " + fee.Description,
+			ProcessingMessage:     fmt.Sprintf("Reversed fee %s with transaction %s", fee.ID, original.ID),
+			BalanceAfter:          account.Balance,
+		})
+	}
+	accounts[transaction.AccountID] = account
+	return rows
+}
+
+// insufficientFundsHold prefixes the processing message of transactions held for lack of funds
+const insufficientFundsHold = "Held until funds are available: "
+
+// declineInsufficientFunds rejects a transaction the account cannot cover, or holds it for the
+// next batch when config.HoldInsufficientFunds is set
+func declineInsufficientFunds(transaction models.Transaction, reason string, config Config) models.Transaction {
+	if config.HoldInsufficientFunds {
+		transaction.Status = "held"
+		transaction.ProcessingMessage = insufficientFundsHold + strings.ToLower(reason[:1]) + reason[1:]
+		return transaction
+	}
+	transaction.Status = "rejected"
+	transaction.ProcessingMessage = reason
+	return transaction
+}
+
+// HeldForInsufficientFunds reports whether a transaction was held for lack of funds, to be
+// retried in the next batch
+func HeldForInsufficientFunds(transaction models.Transaction) bool {
+	return transaction.Status == "held" && strings.HasPrefix(transaction.ProcessingMessage, insufficientFundsHold)
+}
+
+// checkDestination returns the reason a transfer destination is ineligible, or "" if it may receive funds
+func checkDestination(transaction models.Transaction, config Config) string {
+	if config.ExcludedDestinations[transaction.DestinationAccountID] {
+		return fmt.Sprintf("Destination account %s is excluded from transfers", transaction.DestinationAccountID)
+	}
+	return ""
+}
+
+// assessOverdraftFee charges the scheduled overdraft fee for the account's current overdraft
+// count, limited by the daily fee cap, and returns the synthetic fee transaction
+func assessOverdraftFee(
+	transaction models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+	feesToday map[string]models.Money,
+) (models.Transaction, bool) {
+	if len(config.OverdraftFeeSchedule) == 0 {
+		return models.Transaction{}, false
+	}
+
+	account := accounts[transaction.AccountID]
+
+	// Skip fees for accounts still inside their grace period
+	if config.OverdraftFeeGraceDays > 0 && !account.AccountOpenDate.IsZero() &&
+		transaction.Timestamp.Before(account.AccountOpenDate.AddDate(0, 0, config.OverdraftFeeGraceDays)) {
+		return models.Transaction{}, false
+	}
+
+	tier := account.OverdraftCount - 1
+	if tier < 0 {
+		tier = 0
+	}
+	if tier >= len(config.OverdraftFeeSchedule) {
+		tier = len(config.OverdraftFeeSchedule) - 1
+	}
+	fee := models.Cents(config.OverdraftFeeSchedule[tier])
+
+	// Respect the cap on total overdraft fees per day
+	if config.DailyOverdraftFeeCap > 0 {
+		remaining := models.Cents(config.DailyOverdraftFeeCap) - feesToday[transaction.AccountID]
+		if remaining < fee {
+			fee = remaining
+		}
+	}
+	if fee <= 0 {
+		return models.Transaction{}, false
+	}
+
+	account.Balance -= fee
+	accounts[transaction.AccountID] = account
+	feesToday[transaction.AccountID] += fee
+
+	return models.Transaction{
+		ID:                transaction.ID + "-ODFEE",
+		AccountID:         transaction.AccountID,
+		Timestamp:         transaction.Timestamp,
+		Amount:            fee,
+		Type:              "fee",
+		Status:            "completed",
+		Description:       "Overdraft fee",
+		ProcessingMessage: fmt.Sprintf("Overdraft fee for transaction %s (overdraft #%d)", transaction.ID, account.OverdraftCount),
+		BalanceAfter:      account.Balance,
+	}, true
+}
+
+// checkReserve returns the reason a new balance would breach the account's minimum reserve,
+// or "" if the available balance (balance minus reserve) stays non-negative
+func checkReserve(account models.Account, newBalance models.Money) string {
+	if account.ReservedBalance > 0 && newBalance < account.ReservedBalance {
+		return fmt.Sprintf("Would breach minimum reserve balance of $%s", account.ReservedBalance)
+	}
+	return ""
+}
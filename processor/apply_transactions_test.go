@@ -0,0 +1,631 @@
+// processor/apply_transactions_test.go
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// testBatch builds accountCount accounts and a deterministic mix of credits, debits and
+// transfers among them, including some that overdraw or exceed the daily limit
+func testBatch(accountCount int, transactionCount int) (map[string]models.Account, []models.Transaction) {
+	accounts := make(map[string]models.Account, accountCount)
+	for i := 0; i < accountCount; i++ {
+		id := fmt.Sprintf("ACC%05d", i)
+		accounts[id] = models.Account{ID: id, Balance: models.Money(int64(i%7) * 50000)}
+	}
+
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	types := []string{"credit", "debit", "transfer", "debit"}
+	transactions := make([]models.Transaction, transactionCount)
+	for i := range transactions {
+		transaction := models.Transaction{
+			ID:        fmt.Sprintf("TX%07d", i),
+			AccountID: fmt.Sprintf("ACC%05d", i%accountCount),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Amount:    models.Money(int64(i%13+1) * 17500),
+			Type:      types[i%len(types)],
+			Status:    "pending",
+		}
+		if transaction.Type == "transfer" {
+			transaction.DestinationAccountID = fmt.Sprintf("ACC%05d", (i*7+3)%accountCount)
+		}
+		transactions[i] = transaction
+	}
+	return accounts, transactions
+}
+
+// copyAccounts returns a shallow copy of an accounts map
+func copyAccounts(accounts map[string]models.Account) map[string]models.Account {
+	result := make(map[string]models.Account, len(accounts))
+	for id, account := range accounts {
+		result[id] = account
+	}
+	return result
+}
+
+func TestProcessTransactionsInPlaceMatchesCopy(t *testing.T) {
+	withFees := DefaultConfig()
+	withFees.TransactionFees = FeeConfig{"debit": {Flat: 1.5}, "transfer": {Percentage: 0.1}}
+	withFees.OverdraftFeeSchedule = []float64{25, 35}
+	withHolds := DefaultConfig()
+	withHolds.HoldInsufficientFunds = true
+
+	tests := []struct {
+		name     string
+		accounts int
+		count    int
+		config   Config
+	}{
+		{name: "empty batch", accounts: 3, count: 0, config: DefaultConfig()},
+		{name: "default rules", accounts: 20, count: 500, config: DefaultConfig()},
+		{name: "fees", accounts: 20, count: 500, config: withFees},
+		{name: "held for insufficient funds", accounts: 5, count: 200, config: withHolds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, transactions := testBatch(tt.accounts, tt.count)
+			original := copyAccounts(accounts)
+
+			wantAccounts, wantTransactions := ProcessTransactionsWithConfig(transactions, accounts, tt.config)
+			if !reflect.DeepEqual(accounts, original) {
+				t.Fatal("ProcessTransactionsWithConfig modified the caller's accounts")
+			}
+
+			owned := copyAccounts(accounts)
+			gotAccounts, gotTransactions := ProcessTransactionsInPlace(transactions, owned, tt.config)
+			if !reflect.DeepEqual(gotAccounts, wantAccounts) {
+				t.Error("in-place accounts differ from the copying variant")
+			}
+			if !reflect.DeepEqual(gotTransactions, wantTransactions) {
+				t.Error("in-place transactions differ from the copying variant")
+			}
+			if !reflect.DeepEqual(owned, wantAccounts) {
+				t.Error("in-place variant did not update the supplied map")
+			}
+		})
+	}
+}
+
+func BenchmarkProcessTransactionsCopy(b *testing.B) {
+	accounts, transactions := testBatch(100000, 10000)
+	config := DefaultConfig()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProcessTransactionsWithConfig(transactions, accounts, config)
+	}
+}
+
+func BenchmarkProcessTransactionsInPlace(b *testing.B) {
+	accounts, transactions := testBatch(100000, 10000)
+	config := DefaultConfig()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		owned := copyAccounts(accounts)
+		b.StartTimer()
+		ProcessTransactionsInPlace(transactions, owned, config)
+	}
+}
+
+func TestNoOverdraftAccountTypes(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		accountType string
+		types       map[string]bool
+		wantStatus  string
+	}{
+		{name: "checking may overdraw", accountType: "checking", types: DefaultConfig().NoOverdraftAccountTypes, wantStatus: "completed"},
+		{name: "savings floored at zero", accountType: "savings", types: DefaultConfig().NoOverdraftAccountTypes, wantStatus: "rejected"},
+		{name: "configured type floored", accountType: "isa", types: map[string]bool{"isa": true}, wantStatus: "rejected"},
+		{name: "savings allowed when not listed", accountType: "savings", types: map[string]bool{}, wantStatus: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", AccountType: tt.accountType, Balance: models.Cents(50)}}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(80), Type: "debit", Status: "pending"}}
+			config := DefaultConfig()
+			config.NoOverdraftAccountTypes = tt.types
+
+			_, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestConfiguredLimits(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		configure  func(config *Config)
+		amount     float64
+		wantStatus string
+	}{
+		{name: "default overdraft limit", configure: func(*Config) {}, amount: 250, wantStatus: "completed"},
+		{name: "tighter overdraft limit", configure: func(config *Config) { config.OverdraftLimit = -100 }, amount: 250, wantStatus: "rejected"},
+		{name: "within tighter overdraft limit", configure: func(config *Config) { config.OverdraftLimit = -100 }, amount: 150, wantStatus: "completed"},
+		{name: "tighter daily withdrawal limit", configure: func(config *Config) { config.MaxDailyWithdrawalLimit = 100 }, amount: 150, wantStatus: "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", AccountType: "checking", Balance: models.Cents(50)}}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(tt.amount), Type: "debit", Status: "pending"}}
+			config := DefaultConfig()
+			tt.configure(&config)
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			if tt.wantStatus == "rejected" && updated["A1"].Balance != models.Cents(50) {
+				t.Errorf("rejected debit moved the balance to %s", updated["A1"].Balance)
+			}
+		})
+	}
+}
+
+func TestApprovedOverdraft(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		approved   bool
+		amount     float64
+		wantStatus string
+	}{
+		{name: "unapproved beyond the standard limit", amount: 2000, wantStatus: "rejected"},
+		{name: "approved beyond the standard limit", approved: true, amount: 2000, wantStatus: "completed"},
+		{name: "approved beyond the approved ceiling", approved: true, amount: 4950, wantStatus: "rejected"},
+		{name: "unapproved within the standard limit", amount: 900, wantStatus: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", AccountType: "checking", ApprovedOverdraft: tt.approved}}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(tt.amount), Type: "debit", Status: "pending"}}
+			config := DefaultConfig()
+			config.ApprovedOverdraftLimit = -3000
+			config.MaxDailyWithdrawalLimit = 10000
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			wantBalance := -models.Cents(tt.amount)
+			if tt.wantStatus == "rejected" {
+				wantBalance = 0
+			}
+			if updated["A1"].Balance != wantBalance {
+				t.Errorf("balance = %s, want %s", updated["A1"].Balance, wantBalance)
+			}
+		})
+	}
+}
+
+func TestMinimumReserve(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		kind        string
+		amount      float64
+		reserved    float64
+		wantStatus  string
+		wantReserve bool // Whether the rejection names the reserve
+	}{
+		{name: "debit into the reserve", kind: "debit", amount: 80, reserved: 50, wantStatus: "rejected", wantReserve: true},
+		{name: "transfer into the reserve", kind: "transfer", amount: 80, reserved: 50, wantStatus: "rejected", wantReserve: true},
+		{name: "debit down to the reserve", kind: "debit", amount: 50, reserved: 50, wantStatus: "completed"},
+		{name: "no reserve", kind: "debit", amount: 80, wantStatus: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"A1": {ID: "A1", Balance: models.Cents(100), ReservedBalance: models.Cents(tt.reserved)},
+				"A2": {ID: "A2"},
+			}
+			transaction := models.Transaction{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(tt.amount), Type: tt.kind, Status: "pending"}
+			if tt.kind == "transfer" {
+				transaction.DestinationAccountID = "A2"
+			}
+
+			updated, processed := ProcessTransactionsWithConfig([]models.Transaction{transaction}, accounts, DefaultConfig())
+			if processed[0].Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			if got := strings.Contains(strings.ToLower(processed[0].ProcessingMessage), "reserve"); got != tt.wantReserve {
+				t.Errorf("message %q, want reserve named %v", processed[0].ProcessingMessage, tt.wantReserve)
+			}
+			if tt.wantStatus == "rejected" && updated["A1"].Balance != models.Cents(100) {
+				t.Errorf("rejected %s moved the balance to %s", tt.kind, updated["A1"].Balance)
+			}
+		})
+	}
+}
+
+func TestTransfersCountTowardDailyWithdrawalLimit(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		transfer    float64
+		wantStatus  string
+		wantMessage string
+	}{
+		{name: "within the limit", transfer: 1500, wantStatus: "completed"},
+		{name: "debit and transfer exceed the limit", transfer: 2500, wantStatus: "rejected",
+			wantMessage: "Transfer exceeds daily withdrawal limit of $5000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"A1": {ID: "A1", Balance: models.Cents(10000)},
+				"A2": {ID: "A2"},
+			}
+			transactions := []models.Transaction{
+				{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(3000), Type: "debit", Status: "pending"},
+				{ID: "T2", AccountID: "A1", DestinationAccountID: "A2", Timestamp: timestamp.Add(time.Hour), Amount: models.Cents(tt.transfer),
+					Type: "transfer", Status: "pending"},
+			}
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, DefaultConfig())
+			if processed[0].Status != "completed" {
+				t.Fatalf("debit status = %s (%s), want completed", processed[0].Status, processed[0].ProcessingMessage)
+			}
+			if processed[1].Status != tt.wantStatus || processed[1].ProcessingMessage != tt.wantMessage {
+				t.Errorf("transfer status = %s (%s), want %s (%s)",
+					processed[1].Status, processed[1].ProcessingMessage, tt.wantStatus, tt.wantMessage)
+			}
+			if tt.wantStatus == "rejected" && (updated["A1"].Balance != models.Cents(7000) || updated["A2"].Balance != 0) {
+				t.Errorf("rejected transfer moved A1 to %s and A2 to %s", updated["A1"].Balance, updated["A2"].Balance)
+			}
+		})
+	}
+}
+
+func TestBalanceAfterLedger(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	accounts := map[string]models.Account{
+		"A1": {ID: "A1", Balance: models.Cents(100)},
+		"A2": {ID: "A2", Balance: models.Cents(500)},
+	}
+	row := func(id, source, destination, kind string, amount float64) models.Transaction {
+		return models.Transaction{ID: id, AccountID: source, DestinationAccountID: destination, Timestamp: timestamp,
+			Amount: models.Cents(amount), Type: kind, Status: "pending"}
+	}
+	transactions := []models.Transaction{
+		row("T1", "A1", "", "credit", 50),
+		row("T2", "A1", "", "debit", 30),
+		row("T3", "A1", "A2", "transfer", 40),
+		row("T4", "A2", "A1", "transfer", 200),
+		row("T5", "A1", "", "debit", 1000),
+	}
+	// Hand-computed ledger: A1 goes 100, 150, 120, 80, 280 after T4, then -720; T4 records its source A2 at 500 + 40 - 200
+	want := map[string]models.Money{
+		"T1": models.Cents(150),
+		"T2": models.Cents(120),
+		"T3": models.Cents(80),
+		"T4": models.Cents(340),
+		"T5": models.Cents(-720),
+	}
+
+	updated, processed := ProcessTransactionsWithConfig(transactions, accounts, DefaultConfig())
+	for _, transaction := range processed {
+		if transaction.Status != "completed" {
+			t.Fatalf("%s status = %s (%s), want completed", transaction.ID, transaction.Status, transaction.ProcessingMessage)
+		}
+		if transaction.BalanceAfter != want[transaction.ID] {
+			t.Errorf("%s balance after = %s, want %s", transaction.ID, transaction.BalanceAfter, want[transaction.ID])
+		}
+	}
+	if updated["A1"].Balance != want["T5"] {
+		t.Errorf("A1 closing balance = %s, want the last balance after %s", updated["A1"].Balance, want["T5"])
+	}
+}
+
+func TestIneligibleTransferDestination(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		destination string
+		configure   func(config *Config)
+		wantStatus  string
+	}{
+		{name: "eligible", destination: "A2", configure: func(*Config) {}, wantStatus: "completed"},
+		{name: "excluded", destination: "A2", configure: func(config *Config) { config.ExcludedDestinations = map[string]bool{"A2": true} },
+			wantStatus: "rejected"},
+		{name: "missing", destination: "A9", configure: func(*Config) {}, wantStatus: "rejected"},
+		{name: "unpermitted currency", destination: "E1", configure: func(*Config) {}, wantStatus: "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"A1": {ID: "A1", Balance: models.Cents(100)},
+				"A2": {ID: "A2", Balance: models.Cents(20)},
+				"E1": {ID: "E1", Currency: "EUR", Balance: models.Cents(20)},
+			}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", DestinationAccountID: tt.destination, Timestamp: timestamp,
+				Amount: models.Cents(40), Type: "transfer", Status: "pending"}}
+			config := DefaultConfig()
+			tt.configure(&config)
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			if tt.wantStatus != "rejected" {
+				return
+			}
+			if source := updated["A1"]; source.Balance != models.Cents(100) || source.DailyDebits != 0 || source.DailyTransfers != 0 {
+				t.Errorf("rejected transfer touched the source: %+v", source)
+			}
+			for _, id := range []string{"A2", "E1"} {
+				if updated[id].Balance != models.Cents(20) {
+					t.Errorf("rejected transfer moved %s to %s", id, updated[id].Balance)
+				}
+			}
+		})
+	}
+}
+
+func TestTransferToMissingDestination(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"A1": {ID: "A1", Balance: models.Cents(100)},
+				"A2": {ID: "A2", Balance: models.Cents(20)},
+			}
+			transactions := []models.Transaction{
+				{ID: "T1", AccountID: "A1", DestinationAccountID: "A9", Timestamp: timestamp, Amount: models.Cents(40),
+					Type: "transfer", Status: "pending"},
+				{ID: "T2", AccountID: "A1", DestinationAccountID: "A2", Timestamp: timestamp.Add(time.Minute), Amount: models.Cents(100),
+					Type: "transfer", Status: "pending"},
+			}
+			config := DefaultConfig()
+			config.Workers = workers
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != "rejected" || !strings.Contains(processed[0].ProcessingMessage, "A9 does not exist") {
+				t.Errorf("T1 status = %s (%s), want rejected for the missing destination", processed[0].Status, processed[0].ProcessingMessage)
+			}
+			if _, exists := updated["A9"]; exists {
+				t.Error("the rejected transfer created the missing destination account")
+			}
+			// The source keeps its full balance, so the next transfer can move all of it
+			if processed[1].Status != "completed" {
+				t.Errorf("T2 status = %s (%s), want completed", processed[1].Status, processed[1].ProcessingMessage)
+			}
+			if updated["A1"].Balance != 0 || updated["A2"].Balance != models.Cents(120) {
+				t.Errorf("balances A1 = %s, A2 = %s, want 0.00 and 120.00", updated["A1"].Balance, updated["A2"].Balance)
+			}
+		})
+	}
+}
+
+func TestOverdraftFees(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		overdraftCount int
+		cap            float64
+		wantFees       []models.Money
+	}{
+		{name: "first then repeat", wantFees: []models.Money{models.Cents(25), models.Cents(35), models.Cents(35)}},
+		{name: "prior overdraft", overdraftCount: 1, wantFees: []models.Money{models.Cents(35), models.Cents(35), models.Cents(35)}},
+		{name: "daily cap", cap: 50, wantFees: []models.Money{models.Cents(25), models.Cents(25)}},
+		{name: "cap below the first fee", cap: 10, wantFees: []models.Money{models.Cents(10)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", AccountType: "checking", OverdraftCount: tt.overdraftCount}}
+			var transactions []models.Transaction
+			for i := 0; i < 3; i++ {
+				transactions = append(transactions, models.Transaction{ID: fmt.Sprintf("T%d", i+1), AccountID: "A1",
+					Timestamp: timestamp.Add(time.Duration(i) * time.Minute), Amount: models.Cents(10), Type: "debit", Status: "pending"})
+			}
+			config := DefaultConfig()
+			config.OverdraftFeeSchedule = []float64{25, 35}
+			config.DailyOverdraftFeeCap = tt.cap
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			var fees []models.Money
+			charged := models.Money(0)
+			for _, transaction := range processed {
+				if transaction.Type == "fee" {
+					fees = append(fees, transaction.Amount)
+					charged += transaction.Amount
+				}
+			}
+			if !reflect.DeepEqual(fees, tt.wantFees) {
+				t.Errorf("overdraft fees = %v, want %v", fees, tt.wantFees)
+			}
+			if want := -models.Cents(30) - charged; updated["A1"].Balance != want {
+				t.Errorf("balance = %s, want %s", updated["A1"].Balance, want)
+			}
+		})
+	}
+}
+
+func TestOverdraftFeeGracePeriod(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		opened   time.Time
+		wantFees int
+	}{
+		{name: "inside the grace window", opened: timestamp.AddDate(0, 0, -10), wantFees: 0},
+		{name: "grace window just ended", opened: timestamp.AddDate(0, 0, -30), wantFees: 1},
+		{name: "older account", opened: timestamp.AddDate(-2, 0, 0), wantFees: 1},
+		{name: "unknown open date", wantFees: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", AccountType: "checking", AccountOpenDate: tt.opened}}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(10), Type: "debit", Status: "pending"}}
+			config := DefaultConfig()
+			config.OverdraftFeeSchedule = []float64{25}
+			config.OverdraftFeeGraceDays = 30
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if got := len(processed) - 1; got != tt.wantFees {
+				t.Errorf("%d overdraft fees, want %d: %+v", got, tt.wantFees, processed)
+			}
+			if updated["A1"].OverdraftCount != 1 {
+				t.Errorf("overdraft count = %d, want 1 whether or not a fee was charged", updated["A1"].OverdraftCount)
+			}
+		})
+	}
+}
+
+func TestReversal(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	opening := map[string]models.Account{
+		"A1": {ID: "A1", Balance: models.Cents(100), DailyDebits: models.Cents(5), OverdraftCount: 1},
+		"A2": {ID: "A2", Balance: models.Cents(20)},
+	}
+	reversal := func(originalID string) models.Transaction {
+		return models.Transaction{ID: "R1", AccountID: "A1", OriginalTransactionID: originalID, Timestamp: timestamp.Add(time.Hour),
+			Type: "reversal", Status: "pending"}
+	}
+
+	tests := []struct {
+		name        string
+		original    models.Transaction
+		fees        FeeConfig
+		wantRefunds []string
+	}{
+		{name: "credit", original: models.Transaction{ID: "T1", AccountID: "A1", Amount: models.Cents(50), Type: "credit"}},
+		{name: "debit", original: models.Transaction{ID: "T1", AccountID: "A1", Amount: models.Cents(30), Type: "debit"}},
+		{name: "debit into overdraft with fees", original: models.Transaction{ID: "T1", AccountID: "A1", Amount: models.Cents(150), Type: "debit"},
+			fees: FeeConfig{"debit": {Flat: 1.5}}, wantRefunds: []string{"T1-FEE-REV", "T1-ODFEE-REV"}},
+		{name: "transfer", original: models.Transaction{ID: "T1", AccountID: "A1", DestinationAccountID: "A2", Amount: models.Cents(40), Type: "transfer"},
+			fees: FeeConfig{"transfer": {Flat: 2}}, wantRefunds: []string{"T1-FEE-REV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.TransactionFees = tt.fees
+			config.OverdraftFeeSchedule = []float64{25}
+			original := tt.original
+			original.Timestamp = timestamp
+			original.Status = "pending"
+
+			updated, processed := ProcessTransactionsWithConfig([]models.Transaction{original, reversal("T1")}, copyAccounts(opening), config)
+			var refunds []string
+			for _, transaction := range processed {
+				if transaction.Status != "completed" {
+					t.Errorf("%s %s (%s)", transaction.ID, transaction.Status, transaction.ProcessingMessage)
+				}
+				if transaction.Type == "reversal" && transaction.ReversedType == "fee" {
+					refunds = append(refunds, transaction.ID)
+				}
+			}
+			if !reflect.DeepEqual(refunds, tt.wantRefunds) {
+				t.Errorf("fee refunds = %v, want %v", refunds, tt.wantRefunds)
+			}
+			for id, want := range opening {
+				got := updated[id]
+				got.LastTransactionTime = time.Time{}
+				if got != want {
+					t.Errorf("%s after reversal = %+v, want the opening %+v", id, got, want)
+				}
+			}
+			if err := VerifyConservation(opening, updated, processed); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCoolingOffHold(t *testing.T) {
+	start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		gap      time.Duration
+		hold     bool
+		wantHeld bool
+	}{
+		{name: "inside the period", gap: 20 * time.Minute, hold: true, wantHeld: true},
+		{name: "compliant", gap: 90 * time.Minute, hold: true},
+		{name: "flagged only", gap: 20 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(50000)}}
+			transactions := []models.Transaction{
+				{ID: "T1", AccountID: "A1", Timestamp: start, Amount: models.Cents(12000), Type: "credit", Status: "pending"},
+				{ID: "T2", AccountID: "A1", Timestamp: start.Add(tt.gap), Amount: models.Cents(11000), Type: "credit", Status: "pending"},
+			}
+			config := DefaultConfig()
+			config.CoolingOffPeriodMins = 60
+			config.HoldCoolingOffViolations = tt.hold
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if got := processed[1].Status == "held"; got != tt.wantHeld {
+				t.Fatalf("T2 status = %s (%s), want held %v", processed[1].Status, processed[1].ProcessingMessage, tt.wantHeld)
+			}
+			wantBalance := models.Cents(73000)
+			if tt.wantHeld {
+				wantBalance = models.Cents(62000)
+			}
+			if updated["A1"].Balance != wantBalance {
+				t.Errorf("balance = %s, want %s", updated["A1"].Balance, wantBalance)
+			}
+		})
+	}
+}
+
+func TestPerAccountOverdraftLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.csv")
+	content := "account_id,balance,overdraft_limit\n" +
+		"WIDE,0.00,-500.00\n" +
+		"NARROW,0.00,-200.00\n" +
+		"DEFAULT,0.00,\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opening, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opening["NARROW"].OverdraftLimit; got != models.Cents(-200) {
+		t.Fatalf("NARROW overdraft limit = %s, want -200.00", got)
+	}
+
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		account    string
+		kind       string
+		wantStatus string
+	}{
+		{name: "debit within own limit", account: "WIDE", kind: "debit", wantStatus: "completed"},
+		{name: "debit beyond own limit", account: "NARROW", kind: "debit", wantStatus: "rejected"},
+		{name: "transfer beyond own limit", account: "NARROW", kind: "transfer", wantStatus: "rejected"},
+		{name: "no limit falls back to the global one", account: "DEFAULT", kind: "debit", wantStatus: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := models.Transaction{ID: "T1", AccountID: tt.account, Timestamp: timestamp, Amount: models.Cents(300),
+				Type: tt.kind, Status: "pending"}
+			if tt.kind == "transfer" {
+				transaction.DestinationAccountID = "WIDE"
+			}
+
+			updated, processed := ProcessTransactionsWithConfig([]models.Transaction{transaction}, copyAccounts(opening), DefaultConfig())
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			if tt.wantStatus == "rejected" && updated[tt.account].Balance != 0 {
+				t.Errorf("rejected %s moved the balance to %s", tt.kind, updated[tt.account].Balance)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// processor/currency.go
+package processor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// RateKey returns the exchange rate table key for converting from one currency to another
+func RateKey(from, to string) string {
+	return strings.ToUpper(from) + "/" + strings.ToUpper(to)
+}
+
+// LoadExchangeRates loads an exchange rate table from a CSV file (from_currency, to_currency, rate)
+func LoadExchangeRates(filePath string) (map[string]float64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening exchange rates file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	rates := make(map[string]float64)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 3 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		rate, err := models.ParseAmount(record[2])
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate at line %d", i+1)
+		}
+		rates[RateKey(record[0], record[1])] = rate
+	}
+
+	return rates, nil
+}
+
+// convertToAccountCurrency converts a transaction into its account's currency using the
+// rate table, recording the original amount, currency, and applied rate for audit.
+// Transactions already in the account currency, or without a matching rate, are unchanged.
+// Accounts without a currency are treated as holding DefaultCurrency.
+func convertToAccountCurrency(
+	transaction models.Transaction,
+	account models.Account,
+	rates map[string]float64,
+) models.Transaction {
+	currency := accountCurrency(account)
+	if transaction.Currency == "" || strings.EqualFold(transaction.Currency, currency) {
+		return transaction
+	}
+
+	rate, exists := rates[RateKey(transaction.Currency, currency)]
+	if !exists {
+		return transaction
+	}
+
+	transaction.OriginalAmount = transaction.Amount
+	transaction.OriginalCurrency = transaction.Currency
+	transaction.ExchangeRate = rate
+	transaction.Amount = transaction.Amount.Mul(rate)
+	transaction.Currency = currency
+	return transaction
+}
+
+// checkCurrency returns the reason a transaction left in a currency other than its account's
+// must be rejected, or "" when the currencies match or the transaction has no currency
+func checkCurrency(transaction models.Transaction, account models.Account) string {
+	currency := accountCurrency(account)
+	if transaction.Currency == "" || strings.EqualFold(transaction.Currency, currency) {
+		return ""
+	}
+	return fmt.Sprintf("Transaction currency %s does not match account currency %s and no exchange rate is available",
+		transaction.Currency, currency)
+}
+
+// convertTransferAmount sets the amount credited to a transfer destination held in a different
+// currency from the source. It returns the reason the transfer must be rejected when the pair is
+// not permitted or has no rate, or "" when the transfer may proceed.
+func convertTransferAmount(
+	transaction models.Transaction,
+	source models.Account,
+	destination models.Account,
+	config Config,
+) (models.Transaction, string) {
+	from, to := accountCurrency(source), accountCurrency(destination)
+	if strings.EqualFold(from, to) {
+		return transaction, ""
+	}
+
+	key := RateKey(from, to)
+	if !config.AllowedConversionPairs[key] {
+		return transaction, fmt.Sprintf("Transfer from %s to %s is not a permitted currency conversion", from, to)
+	}
+	rate, exists := config.ExchangeRates[key]
+	if !exists {
+		return transaction, fmt.Sprintf("No exchange rate for permitted conversion %s", key)
+	}
+
+	transaction.DestinationAmount = transaction.Amount.Mul(rate)
+	return transaction, ""
+}
@@ -0,0 +1,157 @@
+// processor/currency_test.go
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestCurrencyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.csv")
+	if err := os.WriteFile(path, []byte("account_id,balance,currency\nUS1,500.00,USD\nUS2,10.00,USD\nEU1,10.00,EUR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opening, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := opening["EU1"].Currency; got != "EUR" {
+		t.Fatalf("EU1 currency = %q, want EUR", got)
+	}
+
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		currency    string
+		kind        string
+		destination string
+		wantStatus  string
+	}{
+		{name: "matching debit", currency: "USD", kind: "debit", wantStatus: "completed"},
+		{name: "mismatched debit", currency: "EUR", kind: "debit", wantStatus: "rejected"},
+		{name: "matching transfer", currency: "USD", kind: "transfer", destination: "US2", wantStatus: "completed"},
+		{name: "transfer between currencies", currency: "USD", kind: "transfer", destination: "EU1", wantStatus: "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := []models.Transaction{{ID: "T1", AccountID: "US1", DestinationAccountID: tt.destination, Timestamp: timestamp,
+				Amount: models.Cents(100), Currency: tt.currency, Type: tt.kind, Status: "pending"}}
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, copyAccounts(opening), DefaultConfig())
+			if processed[0].Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			if tt.wantStatus == "rejected" {
+				if processed[0].ProcessingMessage == "" {
+					t.Error("rejected without a processing message")
+				}
+				if updated["US1"].Balance != models.Cents(500) || updated["EU1"].Balance != models.Cents(10) {
+					t.Errorf("rejected %s moved US1 to %s and EU1 to %s", tt.kind, updated["US1"].Balance, updated["EU1"].Balance)
+				}
+			} else if updated["US1"].Balance != models.Cents(400) {
+				t.Errorf("US1 balance = %s, want 400.00", updated["US1"].Balance)
+			}
+		})
+	}
+}
+
+func TestCurrencyConversionAuditFields(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		currency   string
+		rates      map[string]float64
+		wantStatus string
+		want       models.Transaction // Expected amount and audit fields
+	}{
+		{
+			name: "converted", currency: "EUR", rates: map[string]float64{"EUR/USD": 1.0857}, wantStatus: "completed",
+			want: models.Transaction{Amount: models.Cents(108.57), Currency: "USD", OriginalAmount: models.Cents(100), OriginalCurrency: "EUR",
+				ExchangeRate: 1.0857},
+		},
+		{
+			name: "lowercase currency", currency: "eur", rates: map[string]float64{"EUR/USD": 1.0857}, wantStatus: "completed",
+			want: models.Transaction{Amount: models.Cents(108.57), Currency: "USD", OriginalAmount: models.Cents(100), OriginalCurrency: "eur",
+				ExchangeRate: 1.0857},
+		},
+		{name: "account currency", currency: "USD", wantStatus: "completed", want: models.Transaction{Amount: models.Cents(100), Currency: "USD"}},
+		{name: "no rate", currency: "GBP", rates: map[string]float64{"EUR/USD": 1.0857}, wantStatus: "rejected",
+			want: models.Transaction{Amount: models.Cents(100), Currency: "GBP"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", Currency: "USD"}}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(100),
+				Currency: tt.currency, Type: "credit", Status: "pending"}}
+			config := DefaultConfig()
+			config.ExchangeRates = tt.rates
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			got := processed[0]
+			if got.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", got.Status, got.ProcessingMessage, tt.wantStatus)
+			}
+			if got.Amount != tt.want.Amount || got.Currency != tt.want.Currency || got.OriginalAmount != tt.want.OriginalAmount ||
+				got.OriginalCurrency != tt.want.OriginalCurrency || got.ExchangeRate != tt.want.ExchangeRate {
+				t.Errorf("amount %s %s from %s %s at %g, want %s %s from %s %s at %g",
+					got.Amount, got.Currency, got.OriginalAmount, got.OriginalCurrency, got.ExchangeRate,
+					tt.want.Amount, tt.want.Currency, tt.want.OriginalAmount, tt.want.OriginalCurrency, tt.want.ExchangeRate)
+			}
+			if tt.wantStatus == "completed" && updated["A1"].Balance != tt.want.Amount {
+				t.Errorf("balance = %s, want the converted %s", updated["A1"].Balance, tt.want.Amount)
+			}
+		})
+	}
+}
+
+func TestCrossCurrencyTransfer(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		pairs           map[string]bool
+		rates           map[string]float64
+		wantStatus      string
+		wantMessage     string
+		wantDestination models.Money // Amount credited to the EUR account
+	}{
+		{name: "permitted pair", pairs: map[string]bool{"USD/EUR": true}, rates: map[string]float64{"USD/EUR": 0.92},
+			wantStatus: "completed", wantDestination: models.Cents(92)},
+		{name: "disallowed pair", pairs: map[string]bool{"EUR/USD": true}, rates: map[string]float64{"USD/EUR": 0.92},
+			wantStatus: "rejected", wantMessage: "Transfer from USD to EUR is not a permitted currency conversion"},
+		{name: "permitted pair without a rate", pairs: map[string]bool{"USD/EUR": true},
+			wantStatus: "rejected", wantMessage: "No exchange rate for permitted conversion USD/EUR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"US1": {ID: "US1", Currency: "USD", Balance: models.Cents(500)},
+				"EU1": {ID: "EU1", Currency: "EUR", Balance: models.Cents(10)},
+			}
+			transactions := []models.Transaction{{ID: "T1", AccountID: "US1", DestinationAccountID: "EU1", Timestamp: timestamp,
+				Amount: models.Cents(100), Type: "transfer", Status: "pending"}}
+			config := DefaultConfig()
+			config.AllowedConversionPairs = tt.pairs
+			config.ExchangeRates = tt.rates
+
+			updated, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if processed[0].Status != tt.wantStatus || (tt.wantMessage != "" && processed[0].ProcessingMessage != tt.wantMessage) {
+				t.Fatalf("status = %s (%s), want %s (%s)", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus, tt.wantMessage)
+			}
+
+			wantSource, wantDestination := models.Cents(400), models.Cents(10)+tt.wantDestination
+			if tt.wantStatus == "rejected" {
+				wantSource = models.Cents(500)
+			}
+			if updated["US1"].Balance != wantSource || updated["EU1"].Balance != wantDestination {
+				t.Errorf("balances = %s USD and %s EUR, want %s and %s", updated["US1"].Balance, updated["EU1"].Balance, wantSource, wantDestination)
+			}
+			if tt.wantStatus == "completed" && processed[0].DestinationAmount != tt.wantDestination {
+				t.Errorf("destination amount = %s, want %s", processed[0].DestinationAmount, tt.wantDestination)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// processor/fees.go
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// TransactionFee is the fee charged on each completed transaction of one type
+type TransactionFee struct {
+	Flat       float64 `json:"flat"`
+	Percentage float64 `json:"percentage"` // Percent of the transaction amount
+}
+
+// FeeConfig holds the transaction fees keyed by transaction type
+type FeeConfig map[string]TransactionFee
+
+// ParseFeeConfig parses fees written as type=fee pairs separated by commas, where each fee is a
+// flat amount, a percentage ending in %, or both joined by +, e.g. "debit=0.50,transfer=1+0.1%"
+func ParseFeeConfig(spec string) (FeeConfig, error) {
+	fees := make(FeeConfig)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		transactionType, value, found := strings.Cut(pair, "=")
+		if !found || transactionType == "" {
+			return nil, fmt.Errorf("invalid fee %q: expected type=fee", pair)
+		}
+
+		var fee TransactionFee
+		for _, part := range strings.Split(value, "+") {
+			part = strings.TrimSpace(part)
+			percent := strings.HasSuffix(part, "%")
+			amount, err := models.ParseAmount(strings.TrimSuffix(part, "%"))
+			if err != nil || amount < 0 {
+				return nil, fmt.Errorf("invalid fee %q: %q is not a non-negative amount", pair, part)
+			}
+			if percent {
+				fee.Percentage += amount
+			} else {
+				fee.Flat += amount
+			}
+		}
+		fees[transactionType] = fee
+	}
+	return fees, nil
+}
+
+// transactionFee returns the fee for a transaction, rounded to cents
+func transactionFee(transaction models.Transaction, fees FeeConfig) models.Money {
+	fee, exists := fees[transaction.Type]
+	if !exists {
+		return 0
+	}
+	return models.Cents(fee.Flat + transaction.Amount.Float()*fee.Percentage/100)
+}
+
+// chargeTransactionFee deducts the fee for a completed transaction from its source account
+// and returns the fee as its own transaction
+func chargeTransactionFee(
+	transaction models.Transaction,
+	fee models.Money,
+	accounts map[string]models.Account,
+) models.Transaction {
+	account := accounts[transaction.AccountID]
+	account.Balance -= fee
+	accounts[transaction.AccountID] = account
+
+	return models.Transaction{
+		ID:                transaction.ID + "-FEE",
+		AccountID:         transaction.AccountID,
+		Timestamp:         transaction.Timestamp,
+		Amount:            fee,
+		Type:              "fee",
+		Status:            "completed",
+		Description:       fmt.Sprintf("Fee on %s", transaction.Type),
+		Currency:          transaction.Currency,
+		ProcessingMessage: fmt.Sprintf("Fee for transaction %s", transaction.ID),
+		BalanceAfter:      account.Balance,
+	}
+}
@@ -0,0 +1,68 @@
+// processor/fees_test.go
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestTransactionFees(t *testing.T) {
+	fees, err := ParseFeeConfig("debit=2,transfer=1+0.5%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+		opening     float64 // A1's opening balance
+		wantStatus  string
+		wantFee     float64 // Zero when no fee row is emitted
+		wantBalance float64 // A1's closing balance
+	}{
+		{name: "flat debit fee", transaction: models.Transaction{Type: "debit", Amount: models.Cents(50)}, opening: 100,
+			wantStatus: "completed", wantFee: 2, wantBalance: 48},
+		{name: "flat and percentage transfer fee", transaction: models.Transaction{Type: "transfer", DestinationAccountID: "A2",
+			Amount: models.Cents(200)}, opening: 300, wantStatus: "completed", wantFee: 2, wantBalance: 98},
+		{name: "no fee for the type", transaction: models.Transaction{Type: "credit", Amount: models.Cents(50)}, opening: 100,
+			wantStatus: "completed", wantBalance: 150},
+		{name: "fee would pass the overdraft limit", transaction: models.Transaction{Type: "debit", Amount: models.Cents(999)},
+			wantStatus: "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				"A1": {ID: "A1", Balance: models.Cents(tt.opening)},
+				"A2": {ID: "A2"},
+			}
+			transaction := tt.transaction
+			transaction.ID, transaction.AccountID, transaction.Timestamp, transaction.Status = "T1", "A1", timestamp, "pending"
+			config := DefaultConfig()
+			config.TransactionFees = fees
+
+			updated, processed := ProcessTransactionsWithConfig([]models.Transaction{transaction}, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+			var feeRows []models.Transaction
+			for _, row := range processed[1:] {
+				if row.Type == "fee" {
+					feeRows = append(feeRows, row)
+				}
+			}
+			if tt.wantFee == 0 {
+				if len(feeRows) != 0 {
+					t.Errorf("fee rows = %+v, want none", feeRows)
+				}
+			} else if len(feeRows) != 1 || feeRows[0].ID != "T1-FEE" || feeRows[0].Amount != models.Cents(tt.wantFee) ||
+				feeRows[0].BalanceAfter != models.Cents(tt.wantBalance) {
+				t.Errorf("fee rows = %+v, want one T1-FEE of %.2f leaving %.2f", feeRows, tt.wantFee, tt.wantBalance)
+			}
+			if got := updated["A1"].Balance; got != models.Cents(tt.wantBalance) {
+				t.Errorf("A1 closing balance = %s, want %.2f", got, tt.wantBalance)
+			}
+		})
+	}
+}
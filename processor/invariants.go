@@ -0,0 +1,137 @@
+// processor/invariants.go
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// DefaultCurrency is assumed for accounts that do not specify a currency
+const DefaultCurrency = "USD"
+
+// ProcessTransactionsChecked applies transactions like ProcessTransactionsWithConfig and,
+// when config.StrictInvariants is set, verifies the result with VerifyConservation
+func ProcessTransactionsChecked(
+	transactions []models.Transaction,
+	accounts map[string]models.Account,
+	config Config,
+) (map[string]models.Account, []models.Transaction, error) {
+	processedAccounts, processedTransactions := ProcessTransactionsWithConfig(transactions, accounts, config)
+	if config.StrictInvariants {
+		// Accounts created for credits count as opening at zero
+		before := accounts
+		if config.AutoCreateOnCredit {
+			before = make(map[string]models.Account, len(processedAccounts))
+			for id, account := range accounts {
+				before[id] = account
+			}
+			for _, id := range CreatedAccounts(accounts, processedAccounts) {
+				before[id] = models.Account{ID: id, Currency: processedAccounts[id].Currency}
+			}
+		}
+		if err := VerifyConservation(before, processedAccounts, processedTransactions); err != nil {
+			return processedAccounts, processedTransactions, err
+		}
+	}
+	return processedAccounts, processedTransactions, nil
+}
+
+// accountCurrency returns the currency of an account, falling back to DefaultCurrency
+func accountCurrency(account models.Account) string {
+	if account.Currency == "" {
+		return DefaultCurrency
+	}
+	return account.Currency
+}
+
+// Reconcile checks money conservation separately for each currency: the change in total
+// balances held in a currency must equal the net of completed transaction legs posted to
+// accounts in that currency, with transfers netting to zero across the pair and reversals
+// offsetting the transaction they undid
+func Reconcile(
+	before map[string]models.Account,
+	after map[string]models.Account,
+	transactions []models.Transaction,
+) []models.CurrencyReconciliation {
+	totals := make(map[string]*models.CurrencyReconciliation)
+	entry := func(currency string) *models.CurrencyReconciliation {
+		if totals[currency] == nil {
+			totals[currency] = &models.CurrencyReconciliation{Currency: currency}
+		}
+		return totals[currency]
+	}
+
+	for _, account := range before {
+		entry(accountCurrency(account)).OpeningTotal += account.Balance
+	}
+	for _, account := range after {
+		entry(accountCurrency(account)).ClosingTotal += account.Balance
+	}
+
+	// Post each completed leg to the currency of the account it affects
+	post := func(accountID string, amount models.Money) {
+		entry(accountCurrency(after[accountID])).NetTransactions += amount
+	}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		sign := models.Money(1)
+		if transaction.Type == "reversal" {
+			transaction, sign = transaction.Reversed(), -1
+		}
+		switch transaction.Type {
+		case "credit":
+			post(transaction.AccountID, sign*transaction.Amount)
+		case "debit", "fee":
+			post(transaction.AccountID, -sign*transaction.Amount)
+		case "transfer":
+			post(transaction.AccountID, -sign*transaction.Amount)
+			post(transaction.DestinationAccountID, sign*transaction.CreditedAmount())
+		}
+	}
+
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	result := make([]models.CurrencyReconciliation, 0, len(currencies))
+	for _, currency := range currencies {
+		reconciliation := totals[currency]
+		reconciliation.Difference = reconciliation.ClosingTotal - reconciliation.OpeningTotal - reconciliation.NetTransactions
+		reconciliation.Balanced = reconciliation.Difference == 0
+		result = append(result, *reconciliation)
+	}
+	return result
+}
+
+// VerifyConservation checks that processing did not create accounts and that every
+// currency reconciles, returning an error naming each currency that doesn't balance
+func VerifyConservation(
+	before map[string]models.Account,
+	after map[string]models.Account,
+	transactions []models.Transaction,
+) error {
+	for id := range after {
+		if _, exists := before[id]; !exists {
+			return fmt.Errorf("conservation violated: account %s was created during processing", id)
+		}
+	}
+
+	var unbalanced []string
+	for _, reconciliation := range Reconcile(before, after, transactions) {
+		if !reconciliation.Balanced {
+			unbalanced = append(unbalanced, fmt.Sprintf("%s balances changed by %s but transactions net to %s",
+				reconciliation.Currency, reconciliation.ClosingTotal-reconciliation.OpeningTotal, reconciliation.NetTransactions))
+		}
+	}
+	if len(unbalanced) > 0 {
+		return fmt.Errorf("conservation violated: %s", strings.Join(unbalanced, "; "))
+	}
+	return nil
+}
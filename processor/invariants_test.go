@@ -0,0 +1,110 @@
+// processor/invariants_test.go
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestVerifyConservation(t *testing.T) {
+	accounts, transactions := testBatch(20, 400)
+	config := DefaultConfig()
+	config.TransactionFees = FeeConfig{"debit": {Flat: 1.5}}
+	config.OverdraftFeeSchedule = []float64{25}
+	processedAccounts, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+
+	tests := []struct {
+		name    string
+		tamper  func(after map[string]models.Account, rows []models.Transaction) []models.Transaction
+		wantErr string
+	}{
+		{name: "processed batch balances"},
+		{
+			name: "balance changed outside a transaction",
+			tamper: func(after map[string]models.Account, rows []models.Transaction) []models.Transaction {
+				account := after["ACC00003"]
+				account.Balance += models.Cents(0.01)
+				after["ACC00003"] = account
+				return rows
+			},
+			wantErr: "USD balances changed by",
+		},
+		{
+			name: "completed row dropped",
+			tamper: func(after map[string]models.Account, rows []models.Transaction) []models.Transaction {
+				for i, row := range rows {
+					if row.Status == "completed" {
+						return append(rows[:i:i], rows[i+1:]...)
+					}
+				}
+				t.Fatal("no completed row to drop")
+				return rows
+			},
+			wantErr: "USD balances changed by",
+		},
+		{
+			name: "account created",
+			tamper: func(after map[string]models.Account, rows []models.Transaction) []models.Transaction {
+				after["NEW"] = models.Account{ID: "NEW"}
+				return rows
+			},
+			wantErr: "account NEW was created",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := copyAccounts(processedAccounts)
+			rows := append([]models.Transaction(nil), processed...)
+			if tt.tamper != nil {
+				rows = tt.tamper(after, rows)
+			}
+
+			err := VerifyConservation(accounts, after, rows)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileByCurrency(t *testing.T) {
+	before := map[string]models.Account{
+		"USD1": {ID: "USD1", Balance: models.Cents(100)},
+		"EUR1": {ID: "EUR1", Balance: models.Cents(100), Currency: "EUR"},
+	}
+	after := map[string]models.Account{
+		"USD1": {ID: "USD1", Balance: models.Cents(80)},
+		"EUR1": {ID: "EUR1", Balance: models.Cents(118), Currency: "EUR"},
+	}
+	transactions := []models.Transaction{
+		{ID: "T1", AccountID: "USD1", DestinationAccountID: "EUR1", Amount: models.Cents(20), DestinationAmount: models.Cents(18), Type: "transfer", Status: "completed"},
+		{ID: "T2", AccountID: "USD1", Amount: models.Cents(500), Type: "debit", Status: "rejected"},
+	}
+
+	reconciliation := Reconcile(before, after, transactions)
+	if len(reconciliation) != 2 {
+		t.Fatalf("got %d currencies, want 2", len(reconciliation))
+	}
+	for _, r := range reconciliation {
+		if !r.Balanced {
+			t.Errorf("%s not balanced: difference %s", r.Currency, r.Difference)
+		}
+	}
+}
+
+func TestProcessTransactionsCheckedStrict(t *testing.T) {
+	accounts, transactions := testBatch(10, 200)
+	config := DefaultConfig()
+	config.StrictInvariants = true
+	if _, _, err := ProcessTransactionsChecked(transactions, accounts, config); err != nil {
+		t.Errorf("strict processing reported a violation on a valid batch: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// processor/limits.go
+package processor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// AccountLimits holds an account's negotiated limits; a zero field falls back to the global default
+type AccountLimits struct {
+	DailyWithdrawal   float64 `json:"daily_withdrawal"`
+	DailyTransfer     float64 `json:"daily_transfer"`
+	SingleTransaction float64 `json:"single_transaction"`
+}
+
+// LoadAccountLimits loads per-account limits from a CSV file
+// (account_id, daily_withdrawal, daily_transfer, single_transaction); empty fields use the defaults
+func LoadAccountLimits(filePath string, normalizer models.IDNormalizer) (map[string]AccountLimits, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening limits file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	limits := make(map[string]AccountLimits)
+	for i, record := range records {
+		// Skip header row
+		if i == 0 {
+			continue
+		}
+
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid record format at line %d: insufficient fields", i+1)
+		}
+
+		var values [3]float64
+		for j, field := range record[1:4] {
+			if field == "" {
+				continue
+			}
+			values[j], err = models.ParseAmount(field)
+			if err != nil || values[j] < 0 {
+				return nil, fmt.Errorf("invalid limit at line %d", i+1)
+			}
+		}
+
+		limits[normalizer.Normalize(record[0])] = AccountLimits{
+			DailyWithdrawal:   values[0],
+			DailyTransfer:     values[1],
+			SingleTransaction: values[2],
+		}
+	}
+
+	return limits, nil
+}
+
+// limitsFor returns the limits in effect for an account, falling back to the global defaults
+func limitsFor(accountID string, config Config) AccountLimits {
+	limits := AccountLimits{
+		DailyWithdrawal:   config.MaxDailyWithdrawalLimit,
+		DailyTransfer:     config.MaxDailyTransferLimit,
+		SingleTransaction: config.MaxSingleTransactionLimit,
+	}
+
+	custom := config.AccountLimits[accountID]
+	if custom.DailyWithdrawal > 0 {
+		limits.DailyWithdrawal = custom.DailyWithdrawal
+	}
+	if custom.DailyTransfer > 0 {
+		limits.DailyTransfer = custom.DailyTransfer
+	}
+	if custom.SingleTransaction > 0 {
+		limits.SingleTransaction = custom.SingleTransaction
+	}
+	return limits
+}
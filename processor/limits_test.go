@@ -0,0 +1,62 @@
+// processor/limits_test.go
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestAccountLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.csv")
+	content := "account_id,daily_withdrawal,daily_transfer,single_transaction\n" +
+		" a1 ,200,,\n" +
+		"A2,,,\n" +
+		"A3,,100,50\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	limits, err := LoadAccountLimits(path, models.IDNormalizer{Trim: true, Case: "upper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		account    string
+		kind       string
+		amount     float64
+		wantStatus string
+	}{
+		{name: "custom lower withdrawal limit", account: "A1", kind: "debit", amount: 300, wantStatus: "rejected"},
+		{name: "within the custom limit", account: "A1", kind: "debit", amount: 150, wantStatus: "completed"},
+		{name: "empty fields use the default", account: "A2", kind: "debit", amount: 300, wantStatus: "completed"},
+		{name: "account without custom limits", account: "A4", kind: "debit", amount: 300, wantStatus: "completed"},
+		{name: "custom single transaction limit", account: "A3", kind: "debit", amount: 60, wantStatus: "rejected"},
+		{name: "custom daily transfer limit", account: "A3", kind: "transfer", amount: 40, wantStatus: "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{
+				tt.account: {ID: tt.account, Balance: models.Cents(1000), DailyTransfers: models.Cents(70)},
+				"B1":       {ID: "B1"},
+			}
+			transaction := models.Transaction{ID: "T1", AccountID: tt.account, Timestamp: timestamp, Amount: models.Cents(tt.amount),
+				Type: tt.kind, Status: "pending"}
+			if tt.kind == "transfer" {
+				transaction.DestinationAccountID = "B1"
+			}
+			config := DefaultConfig()
+			config.AccountLimits = limits
+
+			_, processed := ProcessTransactionsWithConfig([]models.Transaction{transaction}, accounts, config)
+			if processed[0].Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", processed[0].Status, processed[0].ProcessingMessage, tt.wantStatus)
+			}
+		})
+	}
+}
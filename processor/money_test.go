@@ -0,0 +1,48 @@
+// processor/money_test.go
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestSmallTransactionsBalanceExactly(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		kind        string
+		opening     models.Money
+		wantBalance models.Money
+	}{
+		{name: "credits of 0.01", amount: 0.01, kind: "credit", wantBalance: models.Cents(10)},
+		{name: "credits of 0.10", amount: 0.1, kind: "credit", wantBalance: models.Cents(100)},
+		{name: "debits of 0.07", amount: 0.07, kind: "debit", opening: models.Cents(70), wantBalance: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]models.Account{"A1": {ID: "A1", Balance: tt.opening}}
+			start := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+			transactions := make([]models.Transaction, 1000)
+			for i := range transactions {
+				transactions[i] = models.Transaction{
+					ID: fmt.Sprintf("T%04d", i), AccountID: "A1", Timestamp: start.Add(time.Duration(i) * time.Second),
+					Amount: models.Cents(tt.amount), Type: tt.kind, Status: "pending",
+				}
+			}
+			config := DefaultConfig()
+
+			processedAccounts, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			for _, transaction := range processed {
+				if transaction.Status != "completed" {
+					t.Fatalf("%s %s: %s", transaction.ID, transaction.Status, transaction.ProcessingMessage)
+				}
+			}
+			if got := processedAccounts["A1"].Balance; got != tt.wantBalance {
+				t.Errorf("closing balance = %s, want %s", got, tt.wantBalance)
+			}
+		})
+	}
+}
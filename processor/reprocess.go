@@ -0,0 +1,146 @@
+// processor/reprocess.go
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// PrepareReprocess restores a prior run's processed transactions to the pending state they were
+// processed from, undoing currency conversion so they can be re-applied with a corrected rate table.
+// Fee rows and their refunds are dropped because processing charges and refunds them again.
+func PrepareReprocess(processed []models.Transaction) []models.Transaction {
+	transactions := make([]models.Transaction, 0, len(processed))
+	for _, transaction := range processed {
+		if transaction.Generated() {
+			continue
+		}
+
+		if transaction.OriginalCurrency != "" {
+			transaction.Amount = transaction.OriginalAmount
+			transaction.Currency = transaction.OriginalCurrency
+		}
+		transaction.OriginalAmount = 0
+		transaction.OriginalCurrency = ""
+		transaction.ExchangeRate = 0
+		transaction.DestinationAmount = 0
+		transaction.BalanceAfter = 0
+		transaction.ReversedType = ""
+		transaction.Status = "pending"
+		transaction.ProcessingMessage = ""
+		transactions = append(transactions, transaction)
+	}
+	return transactions
+}
+
+// ApplyPostedTransactions returns a copy of the accounts with the completed legs of already
+// processed transactions applied, reproducing the balances a prior run closed with
+func ApplyPostedTransactions(accounts map[string]models.Account, transactions []models.Transaction) map[string]models.Account {
+	result := make(map[string]models.Account, len(accounts))
+	for id, account := range accounts {
+		result[id] = account
+	}
+
+	adjust := func(accountID string, amount models.Money) {
+		if account, exists := result[accountID]; exists {
+			account.Balance += amount
+			result[accountID] = account
+		}
+	}
+	for _, transaction := range transactions {
+		if transaction.Status != "completed" {
+			continue
+		}
+		sign := models.Money(1)
+		if transaction.Type == "reversal" {
+			transaction, sign = transaction.Reversed(), -1
+		}
+		switch transaction.Type {
+		case "credit":
+			adjust(transaction.AccountID, sign*transaction.Amount)
+		case "debit", "fee":
+			adjust(transaction.AccountID, -sign*transaction.Amount)
+		case "transfer":
+			adjust(transaction.AccountID, -sign*transaction.Amount)
+			adjust(transaction.DestinationAccountID, sign*transaction.CreditedAmount())
+		}
+	}
+
+	return result
+}
+
+// BalanceDeltas compares original and corrected closing balances, listing every account whose
+// balance changed, in account ID order
+func BalanceDeltas(original map[string]models.Account, corrected map[string]models.Account) []models.BalanceDelta {
+	deltas := []models.BalanceDelta{}
+	for id, account := range corrected {
+		difference := account.Balance - original[id].Balance
+		if difference == 0 {
+			continue
+		}
+		deltas = append(deltas, models.BalanceDelta{
+			AccountID:        id,
+			OriginalBalance:  original[id].Balance,
+			CorrectedBalance: account.Balance,
+			Difference:       difference,
+		})
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].AccountID < deltas[j].AccountID
+	})
+	return deltas
+}
+
+// PriorDecisions returns the outcome a prior run's processed transactions file records for each
+// transaction, by ID. Fee rows and their refunds are left out because processing generates them again.
+func PriorDecisions(processed []models.Transaction) map[string]models.Transaction {
+	decisions := make(map[string]models.Transaction)
+	for _, transaction := range processed {
+		if !transaction.Generated() {
+			decisions[transaction.ID] = transaction
+		}
+	}
+	return decisions
+}
+
+// ReplayOutcome returns a transaction with the outcome a prior run recorded for it. Only the fields
+// processing sets come from the prior record; the rest, account IDs included, come from the
+// transaction as loaded, since the prior file may be anonymized or written without some columns.
+func ReplayOutcome(transaction models.Transaction, prior models.Transaction) models.Transaction {
+	transaction.Status = prior.Status
+	transaction.ProcessingMessage = prior.ProcessingMessage
+	transaction.Amount = prior.Amount
+	transaction.Currency = prior.Currency
+	transaction.OriginalAmount = prior.OriginalAmount
+	transaction.OriginalCurrency = prior.OriginalCurrency
+	transaction.ExchangeRate = prior.ExchangeRate
+	transaction.DestinationAmount = prior.DestinationAmount
+	transaction.BalanceAfter = prior.BalanceAfter
+	transaction.ReversedType = prior.ReversedType
+	return transaction
+}
+
+// VerifyPriorDecisions checks that every transaction a prior run completed completed again with
+// the balance that run recorded, returning an error naming each that did not
+func VerifyPriorDecisions(decisions map[string]models.Transaction, processed []models.Transaction) error {
+	var mismatches []string
+	for _, transaction := range processed {
+		prior, decided := decisions[transaction.ID]
+		if transaction.Generated() || !decided || prior.Status != "completed" {
+			continue
+		}
+		if transaction.Status != "completed" {
+			mismatches = append(mismatches, fmt.Sprintf("%s completed before but is now %s", transaction.ID, transaction.Status))
+		} else if transaction.BalanceAfter != prior.BalanceAfter {
+			mismatches = append(mismatches, fmt.Sprintf("%s left a balance of $%s before but now leaves $%s",
+				transaction.ID, prior.BalanceAfter, transaction.BalanceAfter))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("prior run not reproduced: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// processor/reprocess_test.go
+package processor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestPriorDecisionsReplay(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	accounts := map[string]models.Account{"A1": {ID: "A1", Balance: models.Cents(100)}}
+	transactions := []models.Transaction{
+		{ID: "T1", AccountID: "A1", Timestamp: timestamp, Amount: models.Cents(40), Type: "debit", Status: "pending"},
+		{ID: "T2", AccountID: "A1", Timestamp: timestamp.Add(time.Minute), Amount: models.Cents(10), Type: "debit", Status: "pending"},
+	}
+	rejected := transactions[1]
+	rejected.Status = "rejected"
+	rejected.ProcessingMessage = "Rejected by the prior run"
+
+	tests := []struct {
+		name        string
+		decisions   []models.Transaction
+		wantBalance models.Money
+		wantStatus  []string
+		wantErr     bool
+	}{
+		{
+			name:        "no prior run",
+			wantBalance: models.Cents(50),
+			wantStatus:  []string{"completed", "completed"},
+		},
+		{
+			name: "completed reproduced",
+			decisions: []models.Transaction{
+				{ID: "T1", Type: "debit", Status: "completed", BalanceAfter: models.Cents(60)},
+			},
+			wantBalance: models.Cents(50),
+			wantStatus:  []string{"completed", "completed"},
+		},
+		{
+			name:        "rejected stays rejected",
+			decisions:   []models.Transaction{rejected},
+			wantBalance: models.Cents(60),
+			wantStatus:  []string{"completed", "rejected"},
+		},
+		{
+			name: "completed at a different balance",
+			decisions: []models.Transaction{
+				{ID: "T1", Type: "debit", Status: "completed", BalanceAfter: models.Cents(75)},
+			},
+			wantBalance: models.Cents(50),
+			wantStatus:  []string{"completed", "completed"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.PriorDecisions = PriorDecisions(tt.decisions)
+
+			processedAccounts, processed := ProcessTransactionsWithConfig(transactions, accounts, config)
+			if got := processedAccounts["A1"].Balance; got != tt.wantBalance {
+				t.Errorf("balance = %s, want %s", got, tt.wantBalance)
+			}
+			if len(processed) != len(tt.wantStatus) {
+				t.Fatalf("got %d rows, want %d", len(processed), len(tt.wantStatus))
+			}
+			for i, status := range tt.wantStatus {
+				if processed[i].Status != status {
+					t.Errorf("row %d status = %s, want %s", i, processed[i].Status, status)
+				}
+			}
+
+			err := VerifyPriorDecisions(config.PriorDecisions, processed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyPriorDecisions() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPriorDecisionsLeaveOutFees(t *testing.T) {
+	decisions := PriorDecisions([]models.Transaction{
+		{ID: "T1", Type: "debit", Status: "completed"},
+		{ID: "T1-FEE", Type: "fee", Status: "completed"},
+		{ID: "R1", Type: "reversal", ReversedType: "debit", OriginalTransactionID: "T1", Status: "completed"},
+		{ID: "T1-FEE-REV", Type: "reversal", ReversedType: "fee", OriginalTransactionID: "T1-FEE", Status: "completed"},
+	})
+	for _, id := range []string{"T1-FEE", "T1-FEE-REV"} {
+		if _, exists := decisions[id]; exists {
+			t.Errorf("generated row %s recorded as a decision", id)
+		}
+	}
+	if _, exists := decisions["R1"]; !exists {
+		t.Error("reversal missing from decisions")
+	}
+	if _, exists := decisions["T1"]; !exists {
+		t.Error("debit missing from decisions")
+	}
+}
+
+func TestReplayOutcome(t *testing.T) {
+	timestamp := time.Date(2025, 4, 15, 9, 0, 0, 0, time.UTC)
+	loaded := models.Transaction{
+		ID: "T1", AccountID: "A1", DestinationAccountID: "B1", Timestamp: timestamp, Amount: models.Cents(100),
+		Currency: "EUR", Type: "transfer", Status: "pending", Description: "Rent", Tags: map[string]string{"channel": "web"},
+	}
+	converted := models.Transaction{
+		Status: "rejected", ProcessingMessage: "Would exceed overdraft limit", Amount: models.Cents(108.57), Currency: "USD",
+		OriginalAmount: models.Cents(100), OriginalCurrency: "EUR", ExchangeRate: 1.0857,
+	}
+
+	tests := []struct {
+		name  string
+		prior models.Transaction
+		want  models.Transaction
+	}{
+		{
+			name:  "anonymized prior",
+			prior: models.Transaction{ID: "T1", AccountID: "ANON1", DestinationAccountID: "ANON2", Status: "held", ProcessingMessage: "Held", Amount: loaded.Amount, Currency: "EUR"},
+			want: models.Transaction{
+				ID: "T1", AccountID: "A1", DestinationAccountID: "B1", Timestamp: timestamp, Amount: models.Cents(100), Currency: "EUR",
+				Type: "transfer", Status: "held", ProcessingMessage: "Held", Description: "Rent", Tags: map[string]string{"channel": "web"},
+			},
+		},
+		{
+			name:  "prior without descriptive columns",
+			prior: converted,
+			want: models.Transaction{
+				ID: "T1", AccountID: "A1", DestinationAccountID: "B1", Timestamp: timestamp, Type: "transfer",
+				Description: "Rent", Tags: map[string]string{"channel": "web"}, Status: "rejected",
+				ProcessingMessage: "Would exceed overdraft limit", Amount: models.Cents(108.57), Currency: "USD",
+				OriginalAmount: models.Cents(100), OriginalCurrency: "EUR", ExchangeRate: 1.0857,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplayOutcome(loaded, tt.prior); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReplayOutcome() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
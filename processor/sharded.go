@@ -0,0 +1,140 @@
+// processor/sharded.go
+package processor
+
+import (
+	"sync"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+// processTransactionsSharded processes transactions on up to config.Workers goroutines. Accounts
+// linked by a transfer, or by a reversal to the transaction it undoes, are placed in the same shard,
+// so each shard sees every transaction touching its accounts in input order and the result matches
+// serial processing. Rows are returned in input order.
+func processTransactionsSharded(
+	transactions []models.Transaction,
+	processedAccounts map[string]models.Account,
+	config Config,
+) (map[string]models.Account, []models.Transaction) {
+	shardOf, shardCount := assignShards(transactions, config.Workers)
+
+	// Give each shard its own accounts and the input positions of its transactions
+	shardAccounts := make([]map[string]models.Account, shardCount)
+	shardIndexes := make([][]int, shardCount)
+	for i := range shardAccounts {
+		shardAccounts[i] = make(map[string]models.Account)
+	}
+	for id, account := range processedAccounts {
+		if shard, exists := shardOf[id]; exists {
+			shardAccounts[shard][id] = account
+		}
+	}
+	for i, transaction := range transactions {
+		shard := shardOf[transaction.AccountID]
+		shardIndexes[shard] = append(shardIndexes[shard], i)
+	}
+
+	rows := make([][]models.Transaction, len(transactions))
+	var wg sync.WaitGroup
+	for shard := 0; shard < shardCount; shard++ {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			state := newProcessingState()
+			for _, i := range shardIndexes[shard] {
+				rows[i] = processTransaction(transactions[i], shardAccounts[shard], config, state)
+			}
+		}(shard)
+	}
+	wg.Wait()
+
+	for _, accounts := range shardAccounts {
+		for id, account := range accounts {
+			processedAccounts[id] = account
+		}
+	}
+	processedTransactions := make([]models.Transaction, 0, len(transactions))
+	for _, transactionRows := range rows {
+		processedTransactions = append(processedTransactions, transactionRows...)
+	}
+
+	return processedAccounts, processedTransactions
+}
+
+// assignShards groups the accounts named in transactions into sets connected by transfers and
+// reversals and spreads the sets over at most workers shards, balancing transaction counts. It
+// returns the shard of each account and the number of shards used.
+func assignShards(transactions []models.Transaction, workers int) (map[string]int, int) {
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		if _, exists := parent[id]; !exists {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	union := func(a, b string) {
+		if rootA, rootB := find(a), find(b); rootA != rootB {
+			parent[rootB] = rootA
+		}
+	}
+	accountOf := make(map[string]string, len(transactions))
+	for _, transaction := range transactions {
+		find(transaction.AccountID)
+		if transaction.Type == "transfer" && transaction.DestinationAccountID != "" {
+			union(transaction.AccountID, transaction.DestinationAccountID)
+		}
+		// A reversal must see the transaction it undoes, and resolves it by ID among earlier ones
+		if transaction.Type == "reversal" {
+			if originalAccount, exists := accountOf[transaction.OriginalTransactionID]; exists {
+				union(originalAccount, transaction.AccountID)
+			}
+		}
+		if _, exists := accountOf[transaction.ID]; !exists {
+			accountOf[transaction.ID] = transaction.AccountID
+		}
+	}
+
+	// Count transactions per connected set, in order of first appearance
+	groupSize := make(map[string]int)
+	groups := []string{}
+	for _, transaction := range transactions {
+		root := find(transaction.AccountID)
+		if groupSize[root] == 0 {
+			groups = append(groups, root)
+		}
+		groupSize[root]++
+	}
+
+	shardCount := workers
+	if len(groups) < shardCount {
+		shardCount = len(groups)
+	}
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	// Place each set on the least loaded shard
+	load := make([]int, shardCount)
+	groupShard := make(map[string]int, len(groups))
+	for _, root := range groups {
+		lightest := 0
+		for shard := range load {
+			if load[shard] < load[lightest] {
+				lightest = shard
+			}
+		}
+		groupShard[root] = lightest
+		load[lightest] += groupSize[root]
+	}
+
+	shardOf := make(map[string]int, len(parent))
+	for id := range parent {
+		shardOf[id] = groupShard[find(id)]
+	}
+	return shardOf, shardCount
+}
@@ -0,0 +1,85 @@
+// processor/sharded_test.go
+package processor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"DailyTransactionBatchProcessing/models"
+)
+
+func TestShardedMatchesSerial(t *testing.T) {
+	// reversed appends a reversal of every nth transaction after the batch, booked on the
+	// account offset places after the original's account
+	reversed := func(transactions []models.Transaction, accountCount, every, offset int) []models.Transaction {
+		last := transactions[len(transactions)-1].Timestamp
+		for i := 0; i < len(transactions); i += every {
+			original := transactions[i]
+			var account int
+			fmt.Sscanf(original.AccountID, "ACC%05d", &account)
+			transactions = append(transactions, models.Transaction{
+				ID:                    original.ID + "-R",
+				AccountID:             fmt.Sprintf("ACC%05d", (account+offset)%accountCount),
+				OriginalTransactionID: original.ID,
+				Timestamp:             last.Add(time.Duration(i+1) * time.Second),
+				Type:                  "reversal",
+				Status:                "pending",
+			})
+		}
+		return transactions
+	}
+	withFees := DefaultConfig()
+	withFees.TransactionFees = FeeConfig{"debit": {Flat: 1.5}, "transfer": {Percentage: 0.1}}
+	withFees.OverdraftFeeSchedule = []float64{25, 35}
+
+	tests := []struct {
+		name         string
+		reverseEvery int // 0 reverses nothing
+		offset       int // Accounts between an original's account and the one its reversal names
+		config       Config
+	}{
+		{name: "mixed workload", config: DefaultConfig()},
+		{name: "fees", config: withFees},
+		{name: "reversals", reverseEvery: 7, config: withFees},
+		{name: "reversals named on another account", reverseEvery: 7, offset: 3, config: withFees},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, transactions := testBatch(20, 500)
+			if tt.reverseEvery > 0 {
+				transactions = reversed(transactions, 20, tt.reverseEvery, tt.offset)
+			}
+
+			serial := tt.config
+			serial.Workers = 1
+			wantAccounts, wantTransactions := ProcessTransactionsWithConfig(transactions, accounts, serial)
+			completedReversals := 0
+			for _, transaction := range wantTransactions {
+				if transaction.Type == "reversal" && transaction.ReversedType != "fee" && transaction.Status == "completed" {
+					completedReversals++
+				}
+			}
+			if tt.reverseEvery > 0 && completedReversals == 0 {
+				t.Fatal("serial processing completed no reversals")
+			}
+			sharded := tt.config
+			sharded.Workers = 4
+			gotAccounts, gotTransactions := ProcessTransactionsWithConfig(transactions, accounts, sharded)
+
+			if !reflect.DeepEqual(gotAccounts, wantAccounts) {
+				t.Error("sharded accounts differ from serial processing")
+			}
+			if !reflect.DeepEqual(gotTransactions, wantTransactions) {
+				for i := range wantTransactions {
+					if i < len(gotTransactions) && !reflect.DeepEqual(gotTransactions[i], wantTransactions[i]) {
+						t.Errorf("row %d: sharded %+v, serial %+v", i, gotTransactions[i], wantTransactions[i])
+						break
+					}
+				}
+				t.Errorf("sharded processing returned %d rows, serial %d", len(gotTransactions), len(wantTransactions))
+			}
+		})
+	}
+}